@@ -2,6 +2,7 @@ package definition_test
 
 import (
 	"context"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -83,7 +84,7 @@ func TestReadDefinition(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			// Call the ReadDefinition tool
-			result, err := tools.ReadDefinition(ctx, suite.Client, tc.symbolName)
+			result, err := tools.ReadDefinition(ctx, suite.Client, tc.symbolName, tools.DefinitionOptions{})
 			if err != nil {
 				t.Fatalf("Failed to read definition: %v", err)
 			}
@@ -98,3 +99,44 @@ func TestReadDefinition(t *testing.T) {
 		})
 	}
 }
+
+// TestReadDefinitions tests reading several Go definitions in one call
+func TestReadDefinitions(t *testing.T) {
+	suite := internal.GetTestSuite(t)
+
+	ctx, cancel := context.WithTimeout(suite.Context, 10*time.Second)
+	defer cancel()
+
+	result, err := tools.ReadDefinitions(ctx, suite.Client, []string{"FooBar", "TestStruct", "NotFound", "FooBar"}, tools.DefinitionOptions{})
+	if err != nil {
+		t.Fatalf("Failed to read definitions: %v", err)
+	}
+
+	for _, expected := range []string{"=== FooBar ===", "func FooBar()", "=== TestStruct ===", "type TestStruct struct", "=== Not found ===\n\nNotFound"} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Definitions do not contain expected text: %s", expected)
+		}
+	}
+	if strings.Count(result, "=== FooBar ===") != 1 {
+		t.Errorf("Expected FooBar to be listed once, got:\n%s", result)
+	}
+}
+
+// TestReadTypeDefinition tests jumping from a variable to the definition of its type
+func TestReadTypeDefinition(t *testing.T) {
+	suite := internal.GetTestSuite(t)
+
+	ctx, cancel := context.WithTimeout(suite.Context, 10*time.Second)
+	defer cancel()
+
+	// The variable s in consumer.go is a *SharedStruct
+	filePath := filepath.Join(suite.WorkspaceDir, "consumer.go")
+	result, err := tools.ReadTypeDefinition(ctx, suite.Client, filePath, 11, 2)
+	if err != nil {
+		t.Fatalf("Failed to read type definition: %v", err)
+	}
+
+	if !strings.Contains(result, "type SharedStruct struct") {
+		t.Errorf("Type definition does not contain SharedStruct, got:\n%s", result)
+	}
+}
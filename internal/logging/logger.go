@@ -5,6 +5,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 )
@@ -86,25 +87,25 @@ func init() {
 	ComponentLevels[LSPProcess] = DefaultMinLevel
 	ComponentLevels[LSPWire] = DefaultMinLevel
 
-	// Parse log level from environment variable
-	if level := os.Getenv("LOG_LEVEL"); level != "" {
-		switch strings.ToUpper(level) {
-		case "DEBUG":
-			DefaultMinLevel = LevelDebug
-		case "INFO":
-			DefaultMinLevel = LevelInfo
-		case "WARN":
-			DefaultMinLevel = LevelWarn
-		case "ERROR":
-			DefaultMinLevel = LevelError
-		case "FATAL":
-			DefaultMinLevel = LevelFatal
+	// Parse log level from environment variable. LSP_LOG_LEVEL takes precedence
+	// over the older LOG_LEVEL.
+	for _, name := range []string{"LSP_LOG_LEVEL", "LOG_LEVEL"} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
 		}
+		level, err := ParseLevel(value)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Ignoring %s: %v\n", name, err)
+			continue
+		}
+		DefaultMinLevel = level
 
 		// Set all components to this level by default
 		for comp := range ComponentLevels {
 			ComponentLevels[comp] = DefaultMinLevel
 		}
+		break
 	}
 
 	// Allow overriding levels for specific components
@@ -116,21 +117,8 @@ func init() {
 			}
 
 			comp := Component(strings.TrimSpace(compAndLevel[0]))
-			levelStr := strings.ToUpper(strings.TrimSpace(compAndLevel[1]))
-
-			var level LogLevel
-			switch levelStr {
-			case "DEBUG":
-				level = LevelDebug
-			case "INFO":
-				level = LevelInfo
-			case "WARN":
-				level = LevelWarn
-			case "ERROR":
-				level = LevelError
-			case "FATAL":
-				level = LevelFatal
-			default:
+			level, err := ParseLevel(compAndLevel[1])
+			if err != nil {
 				continue
 			}
 
@@ -138,9 +126,17 @@ func init() {
 		}
 	}
 
-	// Use custom log file if specified
-	if logFile := os.Getenv("LOG_FILE"); logFile != "" {
-		file, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	// LSP_LOG_FILE sends logs only to the file, keeping stderr quiet. LOG_FILE
+	// copies them to the file in addition to stderr.
+	if logFile := os.Getenv("LSP_LOG_FILE"); logFile != "" {
+		file, err := openLogFile(logFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Logging to stderr: %v\n", err)
+		} else {
+			Writer = file
+		}
+	} else if logFile := os.Getenv("LOG_FILE"); logFile != "" {
+		file, err := openLogFile(logFile)
 		if err == nil {
 			Writer = io.MultiWriter(os.Stderr, file)
 		}
@@ -151,6 +147,66 @@ func init() {
 	log.SetFlags(log.Ldate | log.Ltime | log.Lmicroseconds)
 }
 
+// ParseLevel parses a level name such as "debug" or "WARN", ignoring case
+func ParseLevel(name string) (LogLevel, error) {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "DEBUG":
+		return LevelDebug, nil
+	case "INFO":
+		return LevelInfo, nil
+	case "WARN", "WARNING":
+		return LevelWarn, nil
+	case "ERROR":
+		return LevelError, nil
+	case "FATAL":
+		return LevelFatal, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", name)
+}
+
+// openLogFile opens a log file for appending, creating it if needed. Files that
+// resolve to stdout, such as /dev/stdout, are refused.
+func openLogFile(filePath string) (*os.File, error) {
+	switch filepath.Clean(filePath) {
+	case "/dev/stdout", "/dev/fd/1", "/proc/self/fd/1":
+		return nil, fmt.Errorf("log file %s is stdout, which is reserved for the MCP protocol", filePath)
+	}
+
+	file, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	if isStdout(file) {
+		file.Close()
+		return nil, fmt.Errorf("log file %s is stdout, which is reserved for the MCP protocol", filePath)
+	}
+	return file, nil
+}
+
+// isStdout reports whether w writes to the process's stdout. MCP messages are
+// exchanged over stdio, so any other output on stdout corrupts the protocol stream.
+func isStdout(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	if file == os.Stdout || file.Fd() == os.Stdout.Fd() {
+		return true
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	stdoutInfo, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	// Only regular files are compared, since stdout and stderr often share a
+	// terminal or pipe
+	return info.Mode().IsRegular() && os.SameFile(info, stdoutInfo)
+}
+
 // Logger is the interface for component-specific logging
 type Logger interface {
 	Debug(format string, v ...any)
@@ -250,20 +306,26 @@ func SetGlobalLevel(level LogLevel) {
 	}
 }
 
-// SetWriter sets the writer for log output
+// SetWriter sets the writer for log output. Stdout is replaced by stderr, since
+// it carries the MCP protocol.
 func SetWriter(w io.Writer) {
 	logMu.Lock()
 	defer logMu.Unlock()
 
+	if isStdout(w) {
+		fmt.Fprintln(os.Stderr, "Refusing to log to stdout, logging to stderr instead")
+		w = os.Stderr
+	}
+
 	Writer = w
 	log.SetOutput(Writer)
 }
 
 // SetupFileLogging configures logging to a file in addition to stderr
 func SetupFileLogging(filePath string) error {
-	file, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	file, err := openLogFile(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to open log file: %w", err)
+		return err
 	}
 
 	logMu.Lock()
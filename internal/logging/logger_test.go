@@ -2,7 +2,10 @@ package logging
 
 import (
 	"bytes"
+	"io"
 	"maps"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -106,3 +109,100 @@ func TestLogger(t *testing.T) {
 		})
 	}
 }
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    LogLevel
+		wantErr bool
+	}{
+		{name: "debug", want: LevelDebug},
+		{name: "INFO", want: LevelInfo},
+		{name: " Warn ", want: LevelWarn},
+		{name: "warning", want: LevelWarn},
+		{name: "error", want: LevelError},
+		{name: "fatal", want: LevelFatal},
+		{name: "verbose", wantErr: true},
+		{name: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLevel(tt.name)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseLevel(%q) succeeded, expected an error", tt.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseLevel(%q) failed: %v", tt.name, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLevel(%q) = %s, expected %s", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestLoggingNeverWritesToStdout guards the MCP stdio transport. Every logger and
+// level is exercised, including an attempt to log to stdout, with stdout redirected
+// to a pipe that must stay empty.
+func TestLoggingNeverWritesToStdout(t *testing.T) {
+	originalStdout := os.Stdout
+	originalWriter := Writer
+	originalLevels := make(map[Component]LogLevel)
+	maps.Copy(originalLevels, ComponentLevels)
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	os.Stdout = writer
+
+	defer func() {
+		os.Stdout = originalStdout
+		SetWriter(originalWriter)
+		maps.Copy(ComponentLevels, originalLevels)
+	}()
+
+	SetGlobalLevel(LevelDebug)
+	SetWriter(io.Discard)
+	SetWriter(os.Stdout)
+	if Writer == os.Stdout {
+		t.Errorf("SetWriter accepted stdout")
+	}
+
+	for _, component := range []Component{Core, LSP, LSPWire, LSPProcess, Watcher, Tools} {
+		logger := NewLogger(component)
+		logger.Debug("debug message")
+		logger.Info("info message")
+		logger.Warn("warn message")
+		logger.Error("error message")
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to close pipe: %v", err)
+	}
+	written, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Failed to read pipe: %v", err)
+	}
+	if len(written) > 0 {
+		t.Errorf("Logging wrote to stdout: %q", written)
+	}
+}
+
+func TestOpenLogFileRejectsStdout(t *testing.T) {
+	for _, path := range []string{"/dev/stdout", "/dev/fd/1", "/proc/self/fd/1"} {
+		if _, err := openLogFile(path); err == nil {
+			t.Errorf("openLogFile(%q) succeeded, expected an error", path)
+		}
+	}
+
+	file, err := openLogFile(filepath.Join(t.TempDir(), "server.log"))
+	if err != nil {
+		t.Fatalf("openLogFile failed for a regular file: %v", err)
+	}
+	file.Close()
+}
@@ -2,96 +2,246 @@ package lsp
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/workspace"
 )
 
+// isClangd reports whether the lowercased server command path refers to clangd
+func isClangd(path string) bool {
+	return strings.Contains(path, "clangd")
+}
+
+// clangdArgs adds the clangd arguments configured through CLANGD_* variables, set in
+// the server's configured env or the process environment, to args
+func clangdArgs(args []string, env map[string]string) []string {
+	args = clangdCompileCommandsArgs(args, env)
+	return clangdLimitResultsArgs(args, env)
+}
+
+// clangdSetting returns a CLANGD_* variable from the server's configured env, or
+// from the process environment if it isn't configured
+func clangdSetting(env map[string]string, name string) string {
+	if value := env[name]; value != "" {
+		return value
+	}
+	return os.Getenv(name)
+}
+
+// hasArg reports whether args set the flag, as "--flag=value" or "--flag value"
+func hasArg(args []string, flag string) (string, bool) {
+	for _, arg := range args {
+		if arg == flag || strings.HasPrefix(arg, flag+"=") {
+			return arg, true
+		}
+	}
+	return "", false
+}
+
+// clangdLimitResultsArgs appends --limit-results when CLANGD_LIMIT_RESULTS is set,
+// unless the arguments already set it. clangd applies the limit to workspace/symbol
+// and completion results.
+func clangdLimitResultsArgs(args []string, env map[string]string) []string {
+	value := clangdSetting(env, "CLANGD_LIMIT_RESULTS")
+	if value == "" {
+		return args
+	}
+	if arg, ok := hasArg(args, "--limit-results"); ok {
+		lspLogger.Info("Ignoring CLANGD_LIMIT_RESULTS, clangd arguments already set %s", arg)
+		return args
+	}
+	if n, err := strconv.Atoi(value); err != nil || n < 0 {
+		lspLogger.Warn("Invalid CLANGD_LIMIT_RESULTS %q, expected a number of results", value)
+		return args
+	}
+
+	withLimit := make([]string, 0, len(args)+1)
+	withLimit = append(withLimit, args...)
+	return append(withLimit, "--limit-results="+value)
+}
+
+// clangdCompileCommandsArgs appends --compile-commands-dir to the clangd arguments
+// when CLANGD_COMPILE_COMMANDS_DIR is set, unless the arguments already set it. A
+// directory without a compile_commands.json is still passed on, but logged, since
+// clangd then falls back to guessing flags and cross file navigation quietly finds
+// nothing.
+func clangdCompileCommandsArgs(args []string, env map[string]string) []string {
+	dir := clangdSetting(env, "CLANGD_COMPILE_COMMANDS_DIR")
+	if dir == "" {
+		return args
+	}
+
+	if arg, ok := hasArg(args, "--compile-commands-dir"); ok {
+		lspLogger.Info("Ignoring CLANGD_COMPILE_COMMANDS_DIR, clangd arguments already set %s", arg)
+		return args
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "compile_commands.json")); err != nil {
+		lspLogger.Warn("CLANGD_COMPILE_COMMANDS_DIR %s does not contain compile_commands.json, clangd may not resolve symbols across files: %v", dir, err)
+	}
+
+	withDir := make([]string, 0, len(args)+1)
+	withDir = append(withDir, args...)
+	return append(withDir, "--compile-commands-dir="+dir)
+}
+
 // initializeClangdLanguageServer initializes the Clangd language server
 // with specific optimizations to warm up the static index and open core files.
 func initializeClangdLanguageServer(ctx context.Context, client *Client, workspaceDir string) error {
 	lspLogger.Info("Initializing Clangd language server with workspace: %s", workspaceDir)
 
-	// Step 1: Send 1-2 workspace/symbol queries to warm up the static index
+	// Step 1: Send workspace/symbol queries to warm up the static index
 	if err := warmupClangdStaticIndex(ctx, client); err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
 		lspLogger.Warn("Failed to warm up static index (continuing anyway): %v", err)
 		// Continue even if warmup fails - this is an optimization, not a requirement
 	}
 
 	// Step 2: Open core C++ files to trigger parsing and indexing
 	if err := openCoreCppFiles(ctx, client, workspaceDir); err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
 		lspLogger.Warn("Failed to open core C++ files (continuing anyway): %v", err)
 		// Continue even if opening files fails
 	}
 
+	for _, state := range client.ActiveProgress() {
+		lspLogger.Info("Clangd still working after warmup: %s %s", state.Title, state.Message)
+	}
+
 	lspLogger.Info("Clangd language server initialization completed successfully")
 	return nil
 }
 
+// Defaults for the clangd warmup, used unless the server config sets warmupQueries
+// or warmupDelayMs
+var (
+	// "::" triggers loading the index, and the empty query asks for all symbols
+	defaultClangdWarmupQueries = []string{"::", ""}
+	defaultClangdWarmupDelay   = 100 * time.Millisecond
+)
+
+// clangdWarmupSymbolLimit caps the results of each warmup query. The queries only
+// need to load the index, and the empty query would otherwise list all of it.
+const clangdWarmupSymbolLimit = 100
+
+// sleepContext waits for d, returning early with the context's error if it is
+// cancelled first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // warmupClangdStaticIndex sends workspace/symbol queries to promote static index into cache
 func warmupClangdStaticIndex(ctx context.Context, client *Client) error {
-	lspLogger.Info("Warming up clangd static index...")
-
-	// Query 1: General namespace separator to trigger index loading
-	symbolParams1 := protocol.WorkspaceSymbolParams{
-		Query: "::",
+	queries := client.warmupQueries
+	if queries == nil {
+		queries = defaultClangdWarmupQueries
+	}
+	delay := defaultClangdWarmupDelay
+	if client.warmupDelay != nil {
+		delay = *client.warmupDelay
 	}
 
-	_, err := client.Symbol(ctx, symbolParams1)
-	if err != nil {
-		lspLogger.Warn("First warmup symbol query failed: %v", err)
-	} else {
-		lspLogger.Debug("First warmup symbol query completed")
+	lspLogger.Info("Warming up clangd static index with %d queries...", len(queries))
+	start := time.Now()
+
+	for i, query := range queries {
+		// Small delay between queries
+		if i > 0 {
+			if err := sleepContext(ctx, delay); err != nil {
+				return fmt.Errorf("warmup cancelled: %w", err)
+			}
+		}
+
+		if _, err := client.LimitedSymbol(ctx, query, clangdWarmupSymbolLimit); err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("warmup cancelled: %w", ctx.Err())
+			}
+			lspLogger.Warn("Warmup symbol query %q failed: %v", query, err)
+		} else {
+			lspLogger.Debug("Warmup symbol query %q completed", query)
+		}
 	}
 
-	// Small delay between queries
-	time.Sleep(100 * time.Millisecond)
+	lspLogger.Info("Static index warmup completed in %s", time.Since(start).Round(time.Millisecond))
+	return nil
+}
 
-	// Query 2: Empty query to get all symbols (triggers comprehensive index loading)
-	symbolParams2 := protocol.WorkspaceSymbolParams{
-		Query: "",
+// defaultClangdWarmupFiles is how many C++ files are opened after startup
+const defaultClangdWarmupFiles = 3
+
+// clangdWarmupFilesFromEnv returns the number of C++ files to open after startup,
+// from CLANGD_WARMUP_FILES. 0 disables opening files.
+func clangdWarmupFilesFromEnv() int {
+	value := os.Getenv("CLANGD_WARMUP_FILES")
+	if value == "" {
+		return defaultClangdWarmupFiles
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		lspLogger.Warn("Invalid CLANGD_WARMUP_FILES %q, using %d", value, defaultClangdWarmupFiles)
+		return defaultClangdWarmupFiles
 	}
+	return n
+}
 
-	_, err = client.Symbol(ctx, symbolParams2)
-	if err != nil {
-		lspLogger.Warn("Second warmup symbol query failed: %v", err)
-	} else {
-		lspLogger.Debug("Second warmup symbol query completed")
+// largestFiles sorts paths by file size, largest first. The largest translation
+// units are the most likely to pull the important index entries into clangd's
+// cache. Files that can't be read are dropped.
+func largestFiles(paths []string) []string {
+	sizes := make(map[string]int64, len(paths))
+	sorted := make([]string, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			lspLogger.Debug("Skipping C++ file %s: %v", path, err)
+			continue
+		}
+		sizes[path] = info.Size()
+		sorted = append(sorted, path)
 	}
 
-	lspLogger.Info("Static index warmup completed")
-	return nil
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sizes[sorted[i]] > sizes[sorted[j]]
+	})
+	return sorted
 }
 
+// clangdIgnorePatterns are build output directories skipped when looking for core
+// files, in addition to the workspace's .gitignore
+var clangdIgnorePatterns = []string{"build/", "cmake-build-debug/"}
+
 // openCoreCppFiles finds and opens the largest/most important C++ files in the workspace
 func openCoreCppFiles(ctx context.Context, client *Client, workspaceDir string) error {
 	lspLogger.Info("Opening core C++ files in workspace: %s", workspaceDir)
 
-	// Find C++ files in the workspace
+	// Find C++ files in the workspace, leaving out ignored and build directories
 	var cppFiles []string
-	err := filepath.Walk(workspaceDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Skip directories and hidden folders
-		if info.IsDir() {
-			basename := filepath.Base(path)
-			if strings.HasPrefix(basename, ".") || basename == "build" || basename == "cmake-build-debug" {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
+	walker := workspace.NewWalker(workspaceDir, append(clangdIgnorePatterns, workspace.IgnorePatternsFromEnv()...)...)
+	err := walker.Walk(func(path string, d fs.DirEntry) error {
 		// Check if file is a C++ source file (prioritize .cpp over .h)
 		if strings.HasSuffix(path, ".cpp") || strings.HasSuffix(path, ".cxx") || strings.HasSuffix(path, ".cc") {
 			cppFiles = append(cppFiles, path)
 		}
-
 		return nil
 	})
 
@@ -99,10 +249,16 @@ func openCoreCppFiles(ctx context.Context, client *Client, workspaceDir string)
 		return fmt.Errorf("error walking workspace directory: %w", err)
 	}
 
-	// Sort by file size (largest first) to prioritize important files
-	// For simplicity, we'll just open the first few files found
+	// .h headers are opened as C by default, which clangd parses without C++
+	// features when it has no compile command for them
+	if len(cppFiles) > 0 {
+		client.setDefaultLanguageID(".h", protocol.LangCPP)
+	}
+
+	// Open the largest files first, capped to avoid overwhelming the server
+	cppFiles = largestFiles(cppFiles)
 	fileCount := 0
-	maxFilesToOpen := 3 // Limit to avoid overwhelming the server
+	maxFilesToOpen := clangdWarmupFilesFromEnv()
 
 	for _, filePath := range cppFiles {
 		if fileCount >= maxFilesToOpen {
@@ -118,9 +274,69 @@ func openCoreCppFiles(ctx context.Context, client *Client, workspaceDir string)
 		fileCount++
 
 		// Small delay between file opens to avoid overwhelming the server
-		time.Sleep(50 * time.Millisecond)
+		if err := sleepContext(ctx, 50*time.Millisecond); err != nil {
+			return err
+		}
 	}
 
 	lspLogger.Info("Opened %d core C++ files", fileCount)
 	return nil
 }
+
+// ASTParams are the parameters of clangd's textDocument/ast extension
+type ASTParams struct {
+	TextDocument protocol.TextDocumentIdentifier `json:"textDocument"`
+	Range        protocol.Range                  `json:"range"`
+}
+
+// ASTNode is a node of the clang AST returned by textDocument/ast, such as
+// {role: "expression", kind: "Call", detail: "foo"}
+type ASTNode struct {
+	Role     string          `json:"role"`
+	Kind     string          `json:"kind"`
+	Detail   string          `json:"detail,omitempty"`
+	Arcana   string          `json:"arcana,omitempty"`
+	Range    *protocol.Range `json:"range,omitempty"`
+	Children []ASTNode       `json:"children,omitempty"`
+}
+
+// hasASTProvider reports whether an initialize response advertises clangd's
+// astProvider capability, which isn't part of protocol.ServerCapabilities
+func hasASTProvider(initializeResult json.RawMessage) bool {
+	var result struct {
+		Capabilities struct {
+			ASTProvider bool `json:"astProvider"`
+		} `json:"capabilities"`
+	}
+	if err := json.Unmarshal(initializeResult, &result); err != nil {
+		return false
+	}
+	return result.Capabilities.ASTProvider
+}
+
+// SupportsAST reports whether the server advertised the textDocument/ast extension
+func (c *Client) SupportsAST() bool {
+	return c.astProvider
+}
+
+// AST returns the deepest AST node that encloses the range, using clangd's
+// textDocument/ast extension. The result is nil if no node encloses it.
+func (c *Client) AST(ctx context.Context, params ASTParams) (*ASTNode, error) {
+	var result *ASTNode
+	err := c.Call(ctx, "textDocument/ast", params, &result)
+	return result, err
+}
+
+// SwitchSourceHeader returns the header for a source file or the source for a
+// header, using clangd's textDocument/switchSourceHeader extension. The result is
+// empty if clangd knows of no counterpart.
+func (c *Client) SwitchSourceHeader(ctx context.Context, params protocol.TextDocumentIdentifier) (protocol.DocumentUri, error) {
+	var result *protocol.DocumentUri
+	if err := c.Call(ctx, "textDocument/switchSourceHeader", params, &result); err != nil {
+		return "", err
+	}
+	if result == nil {
+		return "", nil
+	}
+	return *result, nil
+}
@@ -0,0 +1,157 @@
+package lsp
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClangdArgs(t *testing.T) {
+	buildDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(buildDir, "compile_commands.json"), []byte("[]"), 0644))
+	emptyDir := t.TempDir()
+
+	testCases := []struct {
+		name     string
+		args     []string
+		env      map[string]string
+		osEnv    string
+		expected []string
+	}{
+		{
+			name:     "Not set",
+			args:     []string{"--background-index"},
+			expected: []string{"--background-index"},
+		},
+		{
+			name:     "From process environment",
+			args:     []string{"--background-index"},
+			osEnv:    buildDir,
+			expected: []string{"--background-index", "--compile-commands-dir=" + buildDir},
+		},
+		{
+			name:     "Configured env wins",
+			env:      map[string]string{"CLANGD_COMPILE_COMMANDS_DIR": buildDir},
+			osEnv:    emptyDir,
+			expected: []string{"--compile-commands-dir=" + buildDir},
+		},
+		{
+			name:     "Directory without database is still passed",
+			osEnv:    emptyDir,
+			expected: []string{"--compile-commands-dir=" + emptyDir},
+		},
+		{
+			name:     "Explicit argument is kept",
+			args:     []string{"--compile-commands-dir=out"},
+			osEnv:    buildDir,
+			expected: []string{"--compile-commands-dir=out"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("CLANGD_COMPILE_COMMANDS_DIR", tc.osEnv)
+			t.Setenv("CLANGD_LIMIT_RESULTS", "")
+			assert.Equal(t, tc.expected, clangdArgs(tc.args, tc.env))
+		})
+	}
+}
+
+func TestClangdLimitResultsArgs(t *testing.T) {
+	testCases := []struct {
+		name     string
+		args     []string
+		env      map[string]string
+		osEnv    string
+		expected []string
+	}{
+		{name: "Not set", args: []string{"--background-index"}, expected: []string{"--background-index"}},
+		{name: "From process environment", osEnv: "200", expected: []string{"--limit-results=200"}},
+		{name: "Configured env wins", env: map[string]string{"CLANGD_LIMIT_RESULTS": "50"}, osEnv: "200", expected: []string{"--limit-results=50"}},
+		{name: "Explicit argument is kept", args: []string{"--limit-results=10"}, osEnv: "200", expected: []string{"--limit-results=10"}},
+		{name: "Invalid value is ignored", osEnv: "lots", expected: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("CLANGD_LIMIT_RESULTS", tc.osEnv)
+			assert.Equal(t, tc.expected, clangdLimitResultsArgs(tc.args, tc.env))
+		})
+	}
+}
+
+func TestLargestFiles(t *testing.T) {
+	dir := t.TempDir()
+	sizes := map[string]int{"small.cpp": 10, "large.cpp": 300, "medium.cpp": 100}
+	var paths []string
+	for name, size := range sizes {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, make([]byte, size), 0644))
+		paths = append(paths, path)
+	}
+	paths = append(paths, filepath.Join(dir, "missing.cpp"))
+
+	assert.Equal(t, []string{
+		filepath.Join(dir, "large.cpp"),
+		filepath.Join(dir, "medium.cpp"),
+		filepath.Join(dir, "small.cpp"),
+	}, largestFiles(paths))
+}
+
+func TestClangdWarmupFilesFromEnv(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected int
+	}{
+		{value: "", expected: defaultClangdWarmupFiles},
+		{value: "10", expected: 10},
+		{value: "0", expected: 0},
+		{value: "-1", expected: defaultClangdWarmupFiles},
+		{value: "many", expected: defaultClangdWarmupFiles},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			t.Setenv("CLANGD_WARMUP_FILES", tc.value)
+			assert.Equal(t, tc.expected, clangdWarmupFilesFromEnv())
+		})
+	}
+}
+
+func TestSleepContext(t *testing.T) {
+	assert.NoError(t, sleepContext(context.Background(), time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	assert.ErrorIs(t, sleepContext(ctx, time.Hour), context.Canceled)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestWarmupClangdStaticIndexCancelled(t *testing.T) {
+	var buf bytes.Buffer
+	delay := time.Hour
+	client := &Client{
+		stdin:         nopWriteCloser{&buf},
+		handlers:      make(map[string]chan *Message),
+		warmupQueries: []string{"Foo", "Bar"},
+		warmupDelay:   &delay,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := warmupClangdStaticIndex(ctx, client)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestHasASTProvider(t *testing.T) {
+	assert.True(t, hasASTProvider([]byte(`{"capabilities":{"astProvider":true,"hoverProvider":true}}`)))
+	assert.False(t, hasASTProvider([]byte(`{"capabilities":{"hoverProvider":true}}`)))
+	assert.False(t, hasASTProvider([]byte(`not json`)))
+}
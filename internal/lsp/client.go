@@ -2,12 +2,14 @@ package lsp
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -41,48 +43,192 @@ type Client struct {
 	diagnostics   map[protocol.DocumentUri][]protocol.Diagnostic
 	diagnosticsMu sync.RWMutex
 
+	// Channels waiting for the next publishDiagnostics of a document
+	diagnosticsWaiters map[protocol.DocumentUri][]chan struct{}
+
+	// Called with every publishDiagnostics, see OnDiagnostics
+	diagnosticsHandlers []DiagnosticsHandler
+
+	// Work done progress reported with $/progress, by token, see progress.go
+	progress     map[string]*ProgressState
+	lastProgress *ProgressState
+	progressMu   sync.Mutex
+
 	// Files are currently opened by the LSP
 	openFiles   map[string]*OpenFileInfo
 	openFilesMu sync.RWMutex
+
+	// Recent workspace/symbol results by query
+	symbolCache    map[string]symbolCacheEntry
+	symbolCacheTTL time.Duration
+	symbolCacheMu  sync.Mutex
+
+	// How long a request waits for a response, 0 for no limit
+	requestTimeout time.Duration
+
+	// Capabilities reported by the server in its initialize response
+	capabilities protocol.ServerCapabilities
+	serverInfo   *protocol.ServerInfo
+	astProvider  bool
+
+	// File extensions routed to the server by its config, if any
+	extensions []string
+
+	// languageIds sent in didOpen by file extension, overriding DetectLanguageID
+	languageIDs   map[string]string
+	languageIDsMu sync.RWMutex
+
+	// Command line and workspace, kept to relaunch the server after a crash
+	command      string
+	args         []string
+	dir          string
+	env          map[string]string
+	workspaceDir string
+
+	// How the server is reached, see ServerConfig.Transport
+	transport string
+	address   string
+
+	// Workspace folders beyond workspaceDir, see workspace-folders.go
+	folders   []string
+	foldersMu sync.Mutex
+
+	// User supplied initializationOptions, merged over the defaults
+	initOptions map[string]any
+	// The initializationOptions sent in the last initialize request
+	sentInitOptions map[string]any
+
+	// Warmup settings from the server config, nil for the defaults
+	warmupQueries []string
+	warmupDelay   *time.Duration
+
+	// Guards the connection fields above, which are replaced on restart.
+	// connClosed is closed when the message loop of the current connection exits.
+	connMu     sync.RWMutex
+	connClosed chan struct{}
+	generation int
+	startedAt  time.Time
+	// When the last message from the server was read, in Unix nanoseconds
+	lastMessageAt atomic.Int64
+	// Held while a message is written, so concurrent requests don't interleave
+	writeMu sync.Mutex
+
+	// Restart bookkeeping, see restart.go
+	restartMu  sync.Mutex
+	restarts   int
+	restarting atomic.Bool
+	closing    atomic.Bool
 }
 
 func NewClient(command string, args ...string) (*Client, error) {
-	cmd := exec.Command(command, args...)
+	return NewClientFromConfig(ServerConfig{Command: command, Args: args})
+}
+
+// NewClientFromConfig starts the language server described by config
+func NewClientFromConfig(config ServerConfig) (*Client, error) {
+	args := config.Args
+	if isClangd(strings.ToLower(filepath.Base(config.Command))) {
+		args = clangdArgs(args, config.Env)
+	}
+
+	client := &Client{
+		command:               config.Command,
+		transport:             config.Transport,
+		address:               config.Address,
+		extensions:            config.Extensions,
+		args:                  args,
+		dir:                   config.Dir,
+		env:                   config.Env,
+		initOptions:           config.InitializationOptions,
+		warmupQueries:         config.WarmupQueries,
+		handlers:              make(map[string]chan *Message),
+		notificationHandlers:  make(map[string]NotificationHandler),
+		serverRequestHandlers: make(map[string]ServerRequestHandler),
+		diagnostics:           make(map[protocol.DocumentUri][]protocol.Diagnostic),
+		diagnosticsWaiters:    make(map[protocol.DocumentUri][]chan struct{}),
+		openFiles:             make(map[string]*OpenFileInfo),
+		symbolCache:           make(map[string]symbolCacheEntry),
+		symbolCacheTTL:        symbolCacheTTLFromEnv(),
+		requestTimeout:        requestTimeoutFromEnv(),
+	}
+
+	// The server's config takes precedence over LSP_LANGUAGE_IDS
+	client.languageIDs = languageIDsFromEnv()
+	for ext, id := range config.LanguageIDs {
+		if client.languageIDs == nil {
+			client.languageIDs = make(map[string]string)
+		}
+		client.languageIDs[ext] = id
+	}
+
+	if config.WarmupDelayMs != nil {
+		delay := time.Duration(*config.WarmupDelayMs) * time.Millisecond
+		client.warmupDelay = &delay
+	}
+
+	if err := client.connect(); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// connect starts the server and connects to it over its transport: over stdio by
+// launching its command, or over TCP by dialing its address
+func (c *Client) connect() error {
+	if c.transport == TransportTCP {
+		return c.dial()
+	}
+	return c.startProcess()
+}
+
+// startProcess launches the server command and starts reading its output. It
+// replaces any previous connection.
+func (c *Client) startProcess() error {
+	if err := CheckCommand(c.command); err != nil {
+		return err
+	}
+
+	cmd := exec.Command(c.command, c.args...)
+	cmd.Dir = c.dir
 	// Copy env
 	cmd.Env = os.Environ()
+	for key, value := range c.env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
 
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create stdin pipe: %w", err)
+		return fmt.Errorf("failed to create stdin pipe: %w", err)
 	}
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+		return fmt.Errorf("failed to create stdout pipe: %w", err)
 	}
 
 	stderr, err := cmd.StderrPipe()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create stderr pipe: %w", err)
-	}
-
-	client := &Client{
-		Cmd:                   cmd,
-		stdin:                 stdin,
-		stdout:                bufio.NewReader(stdout),
-		stderr:                stderr,
-		handlers:              make(map[string]chan *Message),
-		notificationHandlers:  make(map[string]NotificationHandler),
-		serverRequestHandlers: make(map[string]ServerRequestHandler),
-		diagnostics:           make(map[protocol.DocumentUri][]protocol.Diagnostic),
-		openFiles:             make(map[string]*OpenFileInfo),
+		return fmt.Errorf("failed to create stderr pipe: %w", err)
 	}
 
 	// Start the LSP server process
 	if err := cmd.Start(); err != nil {
-		return nil, fmt.Errorf("failed to start LSP server: %w", err)
+		return fmt.Errorf("failed to start LSP server: %w", err)
 	}
 
+	reader := bufio.NewReader(stdout)
+	closed := make(chan struct{})
+	c.connMu.Lock()
+	c.Cmd = cmd
+	c.stdin = stdin
+	c.stdout = reader
+	c.stderr = stderr
+	c.connClosed = closed
+	c.generation++
+	c.startedAt = time.Now()
+	c.connMu.Unlock()
+
 	// Handle stderr in a separate goroutine with proper logging
 	go func() {
 		scanner := bufio.NewScanner(stderr)
@@ -96,9 +242,9 @@ func NewClient(command string, args ...string) (*Client, error) {
 	}()
 
 	// Start message handling loop
-	go client.handleMessages()
+	go c.handleMessages(reader, stdin, closed)
 
-	return client, nil
+	return nil
 }
 
 func (c *Client) RegisterNotificationHandler(method string, handler NotificationHandler) {
@@ -113,15 +259,51 @@ func (c *Client) RegisterServerRequestHandler(method string, handler ServerReque
 	c.serverRequestHandlers[method] = handler
 }
 
+// semanticTokenTypes and semanticTokenModifiers are the standard semantic token
+// types and modifiers the client understands
+var (
+	semanticTokenTypes = []string{
+		"namespace", "type", "class", "enum", "interface", "struct", "typeParameter",
+		"parameter", "variable", "property", "enumMember", "event", "function", "method",
+		"macro", "keyword", "modifier", "comment", "string", "number", "regexp",
+		"operator", "decorator", "label",
+	}
+	semanticTokenModifiers = []string{
+		"declaration", "definition", "readonly", "static", "deprecated", "abstract",
+		"async", "modification", "documentation", "defaultLibrary",
+	}
+)
+
 func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string) (*protocol.InitializeResult, error) {
+	path := strings.ToLower(c.command)
+
+	initializationOptions := map[string]any{
+		"codelenses": map[string]any{
+			"generate":           true,
+			"regenerate_cgo":     true,
+			"test":               true,
+			"tidy":               true,
+			"upgrade_dependency": true,
+			"vendor":             true,
+			"vulncheck":          false,
+		},
+	}
+	if isGopls(path) {
+		mergeInitializationOptions(initializationOptions, goplsInitializationOptions)
+	}
+	mergeInitializationOptions(initializationOptions, c.initOptions)
+
+	rootPath, rootURI, err := InitializeRoot(workspaceDir)
+	if err != nil {
+		return nil, err
+	}
+	if rootPath != workspaceDir {
+		lspLogger.Info("Initializing with root %s instead of the workspace %s", rootPath, workspaceDir)
+	}
+
 	initParams := &protocol.InitializeParams{
 		WorkspaceFoldersInitializeParams: protocol.WorkspaceFoldersInitializeParams{
-			WorkspaceFolders: []protocol.WorkspaceFolder{
-				{
-					URI:  protocol.URI("file://" + workspaceDir),
-					Name: workspaceDir,
-				},
-			},
+			WorkspaceFolders: c.initialWorkspaceFolders(workspaceDir),
 		},
 
 		XInitializeParams: protocol.XInitializeParams{
@@ -130,11 +312,12 @@ func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string) (
 				Name:    "mcp-language-server",
 				Version: "0.1.0",
 			},
-			RootPath: workspaceDir,
-			RootURI:  protocol.DocumentUri("file://" + workspaceDir),
+			RootPath: rootPath,
+			RootURI:  rootURI,
 			Capabilities: protocol.ClientCapabilities{
 				Workspace: protocol.WorkspaceClientCapabilities{
-					Configuration: true,
+					WorkspaceFolders: true,
+					Configuration:    true,
 					DidChangeConfiguration: protocol.DidChangeConfigurationClientCapabilities{
 						DynamicRegistration: true,
 					},
@@ -142,6 +325,26 @@ func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string) (
 						DynamicRegistration:    true,
 						RelativePatternSupport: true,
 					},
+					WorkspaceEdit: &protocol.WorkspaceEditClientCapabilities{
+						DocumentChanges: true,
+						ResourceOperations: []protocol.ResourceOperationKind{
+							protocol.Create,
+							protocol.Rename,
+							protocol.Delete,
+						},
+					},
+					FileOperations: &protocol.FileOperationClientCapabilities{
+						WillRename: true,
+						DidRename:  true,
+					},
+					Symbol: &protocol.WorkspaceSymbolClientCapabilities{
+						ResolveSupport: &protocol.ClientSymbolResolveOptions{
+							Properties: []string{"location.range"},
+						},
+						TagSupport: &protocol.ClientSymbolTagOptions{
+							ValueSet: []protocol.SymbolTag{protocol.DeprecatedSymbol},
+						},
+					},
 				},
 				TextDocument: protocol.TextDocumentClientCapabilities{
 					Synchronization: &protocol.TextDocumentSyncClientCapabilities{
@@ -149,18 +352,45 @@ func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string) (
 						DidSave:             true,
 					},
 					Completion: protocol.CompletionClientCapabilities{
-						CompletionItem: protocol.ClientCompletionItemOptions{},
+						CompletionItem: protocol.ClientCompletionItemOptions{
+							DocumentationFormat: []protocol.MarkupKind{protocol.Markdown, protocol.PlainText},
+							ResolveSupport: &protocol.ClientCompletionItemResolveOptions{
+								Properties: []string{"documentation", "detail", "additionalTextEdits"},
+							},
+						},
 					},
 					CodeLens: &protocol.CodeLensClientCapabilities{
 						DynamicRegistration: true,
 					},
-					DocumentSymbol: protocol.DocumentSymbolClientCapabilities{},
+					DocumentSymbol: protocol.DocumentSymbolClientCapabilities{
+						HierarchicalDocumentSymbolSupport: true,
+					},
+					InlayHint: &protocol.InlayHintClientCapabilities{},
+					DocumentLink: &protocol.DocumentLinkClientCapabilities{
+						TooltipSupport: true,
+					},
+					Rename: &protocol.RenameClientCapabilities{
+						PrepareSupport: true,
+					},
 					CodeAction: protocol.CodeActionClientCapabilities{
 						CodeActionLiteralSupport: protocol.ClientCodeActionLiteralOptions{
 							CodeActionKind: protocol.ClientCodeActionKindOptions{
-								ValueSet: []protocol.CodeActionKind{},
+								ValueSet: []protocol.CodeActionKind{
+									protocol.QuickFix,
+									protocol.Refactor,
+									protocol.RefactorExtract,
+									protocol.RefactorInline,
+									protocol.RefactorRewrite,
+									protocol.Source,
+									protocol.SourceOrganizeImports,
+									protocol.SourceFixAll,
+								},
 							},
 						},
+						DataSupport: true,
+						ResolveSupport: &protocol.ClientCodeActionResolveOptions{
+							Properties: []string{"edit"},
+						},
 					},
 					PublishDiagnostics: protocol.PublishDiagnosticsClientCapabilities{
 						VersionSupport: true,
@@ -170,31 +400,43 @@ func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string) (
 							Range: &protocol.Or_ClientSemanticTokensRequestOptions_range{},
 							Full:  &protocol.Or_ClientSemanticTokensRequestOptions_full{},
 						},
-						TokenTypes:     []string{},
-						TokenModifiers: []string{},
-						Formats:        []protocol.TokenFormat{},
+						TokenTypes:     semanticTokenTypes,
+						TokenModifiers: semanticTokenModifiers,
+						Formats:        []protocol.TokenFormat{protocol.Relative},
 					},
 				},
-				Window: protocol.WindowClientCapabilities{},
-			},
-			InitializationOptions: map[string]any{
-				"codelenses": map[string]bool{
-					"generate":           true,
-					"regenerate_cgo":     true,
-					"test":               true,
-					"tidy":               true,
-					"upgrade_dependency": true,
-					"vendor":             true,
-					"vulncheck":          false,
+				Window: protocol.WindowClientCapabilities{
+					WorkDoneProgress: true,
 				},
 			},
+			InitializationOptions: initializationOptions,
 		},
 	}
 
-	var result protocol.InitializeResult
-	if err := c.Call(ctx, "initialize", initParams, &result); err != nil {
+	// Progress handlers go first, since servers may start indexing as soon as they
+	// are initialized
+	c.resetProgress()
+	c.RegisterServerRequestHandler("window/workDoneProgress/create", HandleWorkDoneProgressCreate)
+	c.RegisterNotificationHandler("$/progress",
+		func(params json.RawMessage) { HandleProgress(c, params) })
+
+	// The raw response is kept for capabilities outside the protocol, such as
+	// clangd's astProvider
+	var raw json.RawMessage
+	if err := c.Call(ctx, "initialize", initParams, &raw); err != nil {
 		return nil, fmt.Errorf("initialize failed: %w", err)
 	}
+	var result protocol.InitializeResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("invalid initialize result: %w", err)
+	}
+	c.capabilities = result.Capabilities
+	c.astProvider = hasASTProvider(raw)
+	c.sentInitOptions = initializationOptions
+	c.serverInfo = result.ServerInfo
+	c.foldersMu.Lock()
+	c.workspaceDir = workspaceDir
+	c.foldersMu.Unlock()
 
 	if err := c.Notify(ctx, "initialized", struct{}{}); err != nil {
 		return nil, fmt.Errorf("initialized notification failed: %w", err)
@@ -204,35 +446,168 @@ func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string) (
 	c.RegisterServerRequestHandler("workspace/applyEdit", HandleApplyEdit)
 	c.RegisterServerRequestHandler("workspace/configuration", HandleWorkspaceConfiguration)
 	c.RegisterServerRequestHandler("client/registerCapability", HandleRegisterCapability)
+	c.RegisterServerRequestHandler("workspace/workspaceFolders", func(json.RawMessage) (any, error) {
+		return c.handleWorkspaceFolders(), nil
+	})
 	c.RegisterNotificationHandler("window/showMessage", HandleServerMessage)
 	c.RegisterNotificationHandler("textDocument/publishDiagnostics",
 		func(params json.RawMessage) { HandleDiagnostics(c, params) })
 
 	// Notify the LSP server
-	err := c.Initialized(ctx, protocol.InitializedParams{})
+	err = c.Initialized(ctx, protocol.InitializedParams{})
 	if err != nil {
 		return nil, fmt.Errorf("initialization failed: %w", err)
 	}
 
 	// LSP sepecific Initialization
-	path := strings.ToLower(c.Cmd.Path)
 	switch {
 	case strings.Contains(path, "typescript-language-server"):
 		err := initializeTypescriptLanguageServer(ctx, c, workspaceDir)
 		if err != nil {
 			return nil, err
 		}
-	case strings.Contains(path, "clangd"):
+	case isClangd(path):
 		err := initializeClangdLanguageServer(ctx, c, workspaceDir)
 		if err != nil {
 			return nil, err
 		}
+	case isGopls(path):
+		err := initializeGoplsLanguageServer(ctx, c, workspaceDir)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return &result, nil
 }
 
+// WorkspaceDir returns the workspace the server was initialized with
+func (c *Client) WorkspaceDir() string {
+	return c.workspaceDir
+}
+
+// Extensions returns the file extensions the server handles: the ones its config
+// routes to it, or else the well known ones for its command. nil means unknown.
+func (c *Client) Extensions() []string {
+	if len(c.extensions) > 0 {
+		return c.extensions
+	}
+	return ServerExtensions(c.command)
+}
+
+// Name identifies the server in logs: its command, or the address of a server
+// reached over TCP that was configured without one
+func (c *Client) Name() string {
+	if c.command == "" {
+		return c.transport + "://" + c.address
+	}
+	return c.command
+}
+
+// Capabilities returns the capabilities the server reported when it was initialized
+func (c *Client) Capabilities() protocol.ServerCapabilities {
+	return c.capabilities
+}
+
+// ServerInfo returns the name and version the server reported when it was
+// initialized, and false if it reported none
+func (c *Client) ServerInfo() (protocol.ServerInfo, bool) {
+	if c.serverInfo == nil {
+		return protocol.ServerInfo{}, false
+	}
+	return *c.serverInfo, true
+}
+
+// LaunchInfo describes how a language server was started
+type LaunchInfo struct {
+	Command string
+	// Address is the host:port of a server reached over TCP, empty for stdio
+	Address string
+	Args    []string
+	// Dir is the working directory, empty for the current directory
+	Dir string
+	// Env holds the variables set for the server on top of this process's environment
+	Env                   map[string]string
+	PID                   int
+	StartedAt             time.Time
+	WorkspaceFolders      []string
+	InitializationOptions map[string]any
+}
+
+// LaunchInfo returns the command line, environment and workspace the server was
+// started with, for diagnosing setup problems
+func (c *Client) LaunchInfo() LaunchInfo {
+	info := LaunchInfo{
+		Command:               c.command,
+		Address:               c.address,
+		Args:                  c.args,
+		Dir:                   c.dir,
+		Env:                   c.env,
+		WorkspaceFolders:      c.WorkspaceFolders(),
+		InitializationOptions: c.sentInitOptions,
+	}
+
+	c.connMu.RLock()
+	if c.Cmd != nil && c.Cmd.Process != nil {
+		info.PID = c.Cmd.Process.Pid
+	}
+	info.StartedAt = c.startedAt
+	c.connMu.RUnlock()
+	return info
+}
+
+// SemanticTokensLegend returns the token types and modifiers the server uses to
+// encode semantic tokens, and false if the server does not provide semantic tokens
+func (c *Client) SemanticTokensLegend() (protocol.SemanticTokensLegend, bool) {
+	if c.capabilities.SemanticTokensProvider == nil {
+		return protocol.SemanticTokensLegend{}, false
+	}
+
+	// The provider is decoded as a generic map, round trip it into the options type
+	data, err := json.Marshal(c.capabilities.SemanticTokensProvider)
+	if err != nil {
+		return protocol.SemanticTokensLegend{}, false
+	}
+	var options protocol.SemanticTokensOptions
+	if err := json.Unmarshal(data, &options); err != nil {
+		lspLogger.Warn("Failed to decode semantic tokens provider: %v", err)
+		return protocol.SemanticTokensLegend{}, false
+	}
+	return options.Legend, len(options.Legend.TokenTypes) > 0
+}
+
+// shutdownTimeout bounds the shutdown request in Stop, so an unresponsive server
+// doesn't hold up exiting
+const shutdownTimeout = time.Second
+
+// Stop ends the language server following the LSP lifecycle. It closes the open
+// files, sends the shutdown request and the exit notification, then waits for the
+// process to exit and kills it if it doesn't.
+func (c *Client) Stop(ctx context.Context) error {
+	// Don't treat the server exiting from here on as a crash
+	c.closing.Store(true)
+
+	c.CloseAllFiles(ctx)
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, shutdownTimeout)
+	defer cancel()
+	if err := c.Shutdown(shutdownCtx); err != nil {
+		lspLogger.Warn("Shutdown request failed, proceeding with exit: %v", err)
+	}
+	if err := c.Exit(ctx); err != nil {
+		lspLogger.Warn("Exit notification failed: %v", err)
+	}
+
+	return c.Close()
+}
+
+// Close stops the server process by closing its stdin, killing it if it doesn't
+// exit in time. A server reached over TCP is left running, only the connection is
+// closed. Use Stop to shut the server down cleanly first.
 func (c *Client) Close() error {
+	// Don't treat the server exiting from here on as a crash
+	c.closing.Store(true)
+
 	// Try to close all open files first
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -240,8 +615,16 @@ func (c *Client) Close() error {
 	// Attempt to close files but continue shutdown regardless
 	c.CloseAllFiles(ctx)
 
+	// Wait for any restart in progress so we stop the current process
+	c.restartMu.Lock()
+	defer c.restartMu.Unlock()
+
+	if c.Cmd == nil {
+		return c.closeConnection()
+	}
+
 	// Force kill the LSP process if it doesn't exit within timeout
-	forcedKill := make(chan struct{})
+	exited := make(chan struct{})
 	go func() {
 		select {
 		case <-time.After(2 * time.Second):
@@ -253,9 +636,7 @@ func (c *Client) Close() error {
 					lspLogger.Info("Process killed successfully")
 				}
 			}
-			close(forcedKill)
-		case <-forcedKill:
-			// Channel closed from completion path
+		case <-exited:
 			return
 		}
 	}()
@@ -267,11 +648,27 @@ func (c *Client) Close() error {
 
 	// Wait for process to exit
 	err := c.Cmd.Wait()
-	close(forcedKill) // Stop the force kill goroutine
+	close(exited) // Stop the force kill goroutine
 
 	return err
 }
 
+// closeConnection closes the connection to a server reached over TCP and waits for
+// its message loop to exit
+func (c *Client) closeConnection() error {
+	c.connMu.RLock()
+	conn, closed := c.stdin, c.connClosed
+	c.connMu.RUnlock()
+
+	err := conn.Close()
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		lspLogger.Warn("Connection to %s did not close within timeout", c.address)
+	}
+	return err
+}
+
 type ServerState int
 
 const (
@@ -283,8 +680,8 @@ const (
 func (c *Client) WaitForServerReady(ctx context.Context) error {
 	// Check if this is clangd - if so, the clangd-specific initialization
 	// will handle the readiness check more thoroughly
-	path := strings.ToLower(c.Cmd.Path)
-	if strings.Contains(path, "clangd") {
+	path := strings.ToLower(c.command)
+	if isClangd(path) {
 		// Clangd readiness is handled in initializeClangdLanguageServer
 		lspLogger.Debug("Clangd detected, readiness will be handled by clangd-specific initialization")
 		return nil
@@ -299,28 +696,46 @@ func (c *Client) WaitForServerReady(ctx context.Context) error {
 type OpenFileInfo struct {
 	Version int32
 	URI     protocol.DocumentUri
+	// Number of OpenFile calls not yet matched by CloseFile
+	RefCount int
+	// Content last sent to the server
+	content []byte
 }
 
+// OpenFile opens a file on the server, or takes another reference to it if it is
+// already open. An open file whose content changed on disk is resent with didChange
+// rather than reopened. Each call must be paired with a CloseFile.
 func (c *Client) OpenFile(ctx context.Context, filepath string) error {
 	uri := fmt.Sprintf("file://%s", filepath)
 
-	c.openFilesMu.Lock()
-	if _, exists := c.openFiles[uri]; exists {
-		c.openFilesMu.Unlock()
-		return nil // Already open
-	}
-	c.openFilesMu.Unlock()
-
 	// Skip files that do not exist or cannot be read
 	content, err := os.ReadFile(filepath)
 	if err != nil {
 		return fmt.Errorf("error reading file: %w", err)
 	}
 
+	// The lock is held while notifying so concurrent callers can't both send
+	// didOpen, or use the file before didOpen is sent
+	c.openFilesMu.Lock()
+	defer c.openFilesMu.Unlock()
+
+	if fileInfo, exists := c.openFiles[uri]; exists {
+		fileInfo.RefCount++
+		if bytes.Equal(fileInfo.content, content) {
+			return nil // Already open and unchanged
+		}
+		previous := fileInfo.content
+		fileInfo.Version++
+		fileInfo.content = content
+
+		lspLogger.Debug("File changed since it was opened, sending didChange: %s", filepath)
+		return c.sendChange(ctx, uri, fileInfo.Version, previous, content)
+	}
+
 	params := protocol.DidOpenTextDocumentParams{
 		TextDocument: protocol.TextDocumentItem{
 			URI:        protocol.DocumentUri(uri),
-			LanguageID: DetectLanguageID(uri),
+			LanguageID: c.LanguageID(uri),
 			Version:    1,
 			Text:       string(content),
 		},
@@ -330,18 +745,21 @@ func (c *Client) OpenFile(ctx context.Context, filepath string) error {
 		return err
 	}
 
-	c.openFilesMu.Lock()
 	c.openFiles[uri] = &OpenFileInfo{
-		Version: 1,
-		URI:     protocol.DocumentUri(uri),
+		Version:  1,
+		URI:      protocol.DocumentUri(uri),
+		RefCount: 1,
+		content:  content,
 	}
-	c.openFilesMu.Unlock()
 
 	lspLogger.Debug("Opened file: %s", filepath)
 
 	return nil
 }
 
+// NotifyChange sends the current content of an open file to the server if it
+// differs from what the server last saw, as an incremental change when the server
+// accepts those
 func (c *Client) NotifyChange(ctx context.Context, filepath string) error {
 	uri := fmt.Sprintf("file://%s", filepath)
 
@@ -351,17 +769,26 @@ func (c *Client) NotifyChange(ctx context.Context, filepath string) error {
 	}
 
 	c.openFilesMu.Lock()
+	defer c.openFilesMu.Unlock()
+
 	fileInfo, isOpen := c.openFiles[uri]
 	if !isOpen {
-		c.openFilesMu.Unlock()
 		return fmt.Errorf("cannot notify change for unopened file: %s", filepath)
 	}
+	if bytes.Equal(fileInfo.content, content) {
+		return nil
+	}
 
 	// Increment version
+	previous := fileInfo.content
 	fileInfo.Version++
-	version := fileInfo.Version
-	c.openFilesMu.Unlock()
+	fileInfo.content = content
 
+	return c.sendChange(ctx, uri, fileInfo.Version, previous, content)
+}
+
+// sendFullChange sends a didChange notification replacing the whole document
+func (c *Client) sendFullChange(ctx context.Context, uri string, version int32, content []byte) error {
 	params := protocol.DidChangeTextDocumentParams{
 		TextDocument: protocol.VersionedTextDocumentIdentifier{
 			TextDocumentIdentifier: protocol.TextDocumentIdentifier{
@@ -381,15 +808,35 @@ func (c *Client) NotifyChange(ctx context.Context, filepath string) error {
 	return c.Notify(ctx, "textDocument/didChange", params)
 }
 
+// CloseFile releases a reference taken by OpenFile. The server is sent didClose
+// once the last reference is released.
 func (c *Client) CloseFile(ctx context.Context, filepath string) error {
+	return c.closeFile(ctx, filepath, false)
+}
+
+// CloseRemovedFile closes a file that was renamed or deleted on disk, whatever
+// references to it are held, so the server doesn't keep a document whose file is
+// gone
+func (c *Client) CloseRemovedFile(ctx context.Context, filepath string) error {
+	return c.closeFile(ctx, filepath, true)
+}
+
+// closeFile sends didClose for a file once its last reference is released, or
+// immediately if force is set
+func (c *Client) closeFile(ctx context.Context, filepath string, force bool) error {
 	uri := fmt.Sprintf("file://%s", filepath)
 
 	c.openFilesMu.Lock()
-	if _, exists := c.openFiles[uri]; !exists {
-		c.openFilesMu.Unlock()
+	defer c.openFilesMu.Unlock()
+
+	fileInfo, exists := c.openFiles[uri]
+	if !exists {
 		return nil // Already closed
 	}
-	c.openFilesMu.Unlock()
+	if !force && fileInfo.RefCount > 1 {
+		fileInfo.RefCount--
+		return nil
+	}
 
 	params := protocol.DidCloseTextDocumentParams{
 		TextDocument: protocol.TextDocumentIdentifier{
@@ -401,9 +848,7 @@ func (c *Client) CloseFile(ctx context.Context, filepath string) error {
 		return err
 	}
 
-	c.openFilesMu.Lock()
 	delete(c.openFiles, uri)
-	c.openFilesMu.Unlock()
 
 	return nil
 }
@@ -416,7 +861,7 @@ func (c *Client) IsFileOpen(filepath string) bool {
 	return exists
 }
 
-// CloseAllFiles closes all currently open files
+// CloseAllFiles closes all currently open files, regardless of their references
 func (c *Client) CloseAllFiles(ctx context.Context) {
 	c.openFilesMu.Lock()
 	filesToClose := make([]string, 0, len(c.openFiles))
@@ -431,7 +876,7 @@ func (c *Client) CloseAllFiles(ctx context.Context) {
 
 	// Then close them all
 	for _, filePath := range filesToClose {
-		err := c.CloseFile(ctx, filePath)
+		err := c.closeFile(ctx, filePath, true)
 		if err != nil {
 			lspLogger.Error("Error closing file %s: %v", filePath, err)
 		}
@@ -446,3 +891,102 @@ func (c *Client) GetFileDiagnostics(uri protocol.DocumentUri) []protocol.Diagnos
 
 	return c.diagnostics[uri]
 }
+
+// HasDiagnostics reports whether the server has published diagnostics for uri,
+// possibly an empty list
+func (c *Client) HasDiagnostics(uri protocol.DocumentUri) bool {
+	c.diagnosticsMu.RLock()
+	defer c.diagnosticsMu.RUnlock()
+
+	_, ok := c.diagnostics[uri]
+	return ok
+}
+
+// AllDiagnostics returns the diagnostics the server has published for every document
+func (c *Client) AllDiagnostics() map[protocol.DocumentUri][]protocol.Diagnostic {
+	c.diagnosticsMu.RLock()
+	defer c.diagnosticsMu.RUnlock()
+
+	all := make(map[protocol.DocumentUri][]protocol.Diagnostic, len(c.diagnostics))
+	for uri, diagnostics := range c.diagnostics {
+		all[uri] = diagnostics
+	}
+	return all
+}
+
+// DiagnosticsHandler is called with the diagnostics the server publishes for a document
+type DiagnosticsHandler func(uri protocol.DocumentUri, diagnostics []protocol.Diagnostic)
+
+// OnDiagnostics registers a handler for every textDocument/publishDiagnostics from the
+// server, after the diagnostics are cached. Handlers are kept across restarts and
+// must not block, since they run on the message loop.
+func (c *Client) OnDiagnostics(handler DiagnosticsHandler) {
+	c.diagnosticsMu.Lock()
+	defer c.diagnosticsMu.Unlock()
+	c.diagnosticsHandlers = append(c.diagnosticsHandlers, handler)
+}
+
+// DiagnosticsWaiter waits for the next publishDiagnostics of a document
+type DiagnosticsWaiter struct {
+	client *Client
+	uri    protocol.DocumentUri
+	ch     chan struct{}
+}
+
+// ExpectDiagnostics starts listening for the next publishDiagnostics of uri. Call it
+// before sending the notification that makes the server publish, such as didOpen,
+// so a quick reply isn't missed, then call Wait or Cancel.
+func (c *Client) ExpectDiagnostics(uri protocol.DocumentUri) *DiagnosticsWaiter {
+	w := &DiagnosticsWaiter{client: c, uri: uri, ch: make(chan struct{})}
+	c.diagnosticsMu.Lock()
+	c.diagnosticsWaiters[uri] = append(c.diagnosticsWaiters[uri], w.ch)
+	c.diagnosticsMu.Unlock()
+	return w
+}
+
+// Wait blocks until diagnostics are published or the timeout expires. It returns
+// true if diagnostics were published since ExpectDiagnostics.
+func (w *DiagnosticsWaiter) Wait(ctx context.Context, timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-w.ch:
+		return true
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	return w.Cancel()
+}
+
+// Cancel stops listening so the waiter is not notified later. It returns true if
+// diagnostics were already published.
+func (w *DiagnosticsWaiter) Cancel() bool {
+	c := w.client
+	c.diagnosticsMu.Lock()
+	defer c.diagnosticsMu.Unlock()
+	waiters := c.diagnosticsWaiters[w.uri]
+	for i, waiter := range waiters {
+		if waiter == w.ch {
+			c.diagnosticsWaiters[w.uri] = append(waiters[:i], waiters[i+1:]...)
+			if len(c.diagnosticsWaiters[w.uri]) == 0 {
+				delete(c.diagnosticsWaiters, w.uri)
+			}
+			return false
+		}
+	}
+	// The channel was already notified
+	return true
+}
+
+// WaitForDiagnostics blocks until the server publishes diagnostics for uri or
+// the timeout expires, returning true if they were published. It returns at once
+// if the server has already published diagnostics for uri.
+func (c *Client) WaitForDiagnostics(ctx context.Context, uri protocol.DocumentUri, timeout time.Duration) bool {
+	w := c.ExpectDiagnostics(uri)
+	if c.HasDiagnostics(uri) {
+		w.Cancel()
+		return true
+	}
+	return w.Wait(ctx, timeout)
+}
@@ -0,0 +1,247 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type nopWriteCloser struct {
+	*bytes.Buffer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// sentMethods decodes the messages written to a test client
+func sentMethods(t *testing.T, buf *bytes.Buffer) []string {
+	reader := bufio.NewReader(bytes.NewReader(buf.Bytes()))
+	var methods []string
+	for {
+		msg, err := ReadMessage(reader)
+		if err != nil {
+			break
+		}
+		methods = append(methods, msg.Method)
+	}
+	buf.Reset()
+	return methods
+}
+
+func TestOpenFileReferenceCounting(t *testing.T) {
+	var buf bytes.Buffer
+	client := &Client{
+		stdin:     nopWriteCloser{&buf},
+		openFiles: make(map[string]*OpenFileInfo),
+	}
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "main.go")
+	require.NoError(t, os.WriteFile(path, []byte("package main\n"), 0644))
+
+	// First open sends didOpen, reopening unchanged content sends nothing
+	require.NoError(t, client.OpenFile(ctx, path))
+	assert.Equal(t, []string{"textDocument/didOpen"}, sentMethods(t, &buf))
+	require.NoError(t, client.OpenFile(ctx, path))
+	assert.Empty(t, sentMethods(t, &buf))
+
+	// Reopening after the file changed on disk sends didChange with a new version
+	require.NoError(t, os.WriteFile(path, []byte("package main\n\nfunc main() {}\n"), 0644))
+	require.NoError(t, client.OpenFile(ctx, path))
+	assert.Equal(t, []string{"textDocument/didChange"}, sentMethods(t, &buf))
+	assert.Equal(t, int32(2), client.openFiles["file://"+path].Version)
+
+	// Unchanged content is not resent on NotifyChange
+	require.NoError(t, client.NotifyChange(ctx, path))
+	assert.Empty(t, sentMethods(t, &buf))
+
+	// The file was opened three times, so it stays open until the third CloseFile
+	assert.Equal(t, 3, client.openFiles["file://"+path].RefCount)
+	require.NoError(t, client.CloseFile(ctx, path))
+	require.NoError(t, client.CloseFile(ctx, path))
+	assert.Empty(t, sentMethods(t, &buf))
+	assert.True(t, client.IsFileOpen(path))
+	require.NoError(t, client.CloseFile(ctx, path))
+	assert.Equal(t, []string{"textDocument/didClose"}, sentMethods(t, &buf))
+	assert.False(t, client.IsFileOpen(path))
+
+	// Closing a closed file does nothing
+	require.NoError(t, client.CloseFile(ctx, path))
+	assert.Empty(t, sentMethods(t, &buf))
+}
+
+func TestCloseRemovedFileIgnoresReferences(t *testing.T) {
+	var buf bytes.Buffer
+	client := &Client{
+		stdin:     nopWriteCloser{&buf},
+		openFiles: make(map[string]*OpenFileInfo),
+	}
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "main.go")
+	require.NoError(t, os.WriteFile(path, []byte("package main\n"), 0644))
+	require.NoError(t, client.OpenFile(ctx, path))
+	require.NoError(t, client.OpenFile(ctx, path))
+	buf.Reset()
+
+	require.NoError(t, client.CloseRemovedFile(ctx, path))
+	assert.Equal(t, []string{"textDocument/didClose"}, sentMethods(t, &buf))
+	assert.False(t, client.IsFileOpen(path))
+
+	// The references still held are released without another didClose
+	require.NoError(t, client.CloseFile(ctx, path))
+	assert.Empty(t, sentMethods(t, &buf))
+}
+
+func TestOpenFileConcurrentCallersSendOneDidOpen(t *testing.T) {
+	var buf bytes.Buffer
+	client := &Client{
+		stdin:     nopWriteCloser{&buf},
+		openFiles: make(map[string]*OpenFileInfo),
+	}
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "main.go")
+	require.NoError(t, os.WriteFile(path, []byte("package main\n"), 0644))
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, client.OpenFile(ctx, path))
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, []string{"textDocument/didOpen"}, sentMethods(t, &buf))
+	assert.Equal(t, 20, client.openFiles["file://"+path].RefCount)
+}
+
+func TestCloseAllFiles(t *testing.T) {
+	var buf bytes.Buffer
+	client := &Client{
+		stdin:     nopWriteCloser{&buf},
+		openFiles: make(map[string]*OpenFileInfo),
+	}
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "main.go")
+	require.NoError(t, os.WriteFile(path, []byte("package main\n"), 0644))
+	require.NoError(t, client.OpenFile(ctx, path))
+	require.NoError(t, client.OpenFile(ctx, path))
+	buf.Reset()
+
+	client.CloseAllFiles(ctx)
+	assert.Equal(t, []string{"textDocument/didClose"}, sentMethods(t, &buf))
+	assert.False(t, client.IsFileOpen(path))
+}
+
+func TestOnDiagnostics(t *testing.T) {
+	client := &Client{
+		diagnostics:        make(map[protocol.DocumentUri][]protocol.Diagnostic),
+		diagnosticsWaiters: make(map[protocol.DocumentUri][]chan struct{}),
+	}
+
+	var gotURI protocol.DocumentUri
+	var got []protocol.Diagnostic
+	client.OnDiagnostics(func(uri protocol.DocumentUri, diagnostics []protocol.Diagnostic) {
+		gotURI = uri
+		got = diagnostics
+		// Diagnostics are cached before handlers run
+		assert.Equal(t, diagnostics, client.GetFileDiagnostics(uri))
+	})
+
+	HandleDiagnostics(client, []byte(`{"uri":"file:///tmp/main.go","diagnostics":[{"range":{"start":{"line":1,"character":0},"end":{"line":1,"character":4}},"message":"undefined: x"}]}`))
+	assert.Equal(t, protocol.DocumentUri("file:///tmp/main.go"), gotURI)
+	require.Len(t, got, 1)
+	assert.Equal(t, "undefined: x", got[0].Message)
+}
+
+func newDiagnosticsTestClient() *Client {
+	return &Client{
+		diagnostics:        make(map[protocol.DocumentUri][]protocol.Diagnostic),
+		diagnosticsWaiters: make(map[protocol.DocumentUri][]chan struct{}),
+	}
+}
+
+func TestWaitForDiagnostics(t *testing.T) {
+	const uri = protocol.DocumentUri("file:///tmp/main.go")
+	publish := func(client *Client) {
+		HandleDiagnostics(client, []byte(`{"uri":"file:///tmp/main.go","diagnostics":[]}`))
+	}
+	ctx := context.Background()
+
+	t.Run("Published while waiting", func(t *testing.T) {
+		client := newDiagnosticsTestClient()
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			publish(client)
+		}()
+		assert.True(t, client.WaitForDiagnostics(ctx, uri, time.Second))
+		assert.Empty(t, client.diagnosticsWaiters)
+	})
+
+	t.Run("Already published returns at once", func(t *testing.T) {
+		client := newDiagnosticsTestClient()
+		publish(client)
+		start := time.Now()
+		assert.True(t, client.WaitForDiagnostics(ctx, uri, time.Second))
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+		assert.Empty(t, client.diagnosticsWaiters)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		client := newDiagnosticsTestClient()
+		assert.False(t, client.WaitForDiagnostics(ctx, uri, 10*time.Millisecond))
+		assert.Empty(t, client.diagnosticsWaiters)
+	})
+
+	t.Run("Cancelled context", func(t *testing.T) {
+		client := newDiagnosticsTestClient()
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		assert.False(t, client.WaitForDiagnostics(cancelled, uri, time.Second))
+		assert.Empty(t, client.diagnosticsWaiters)
+	})
+}
+
+func TestExpectDiagnosticsCatchesEarlyPublish(t *testing.T) {
+	const uri = protocol.DocumentUri("file:///tmp/main.go")
+	client := newDiagnosticsTestClient()
+
+	// The server replies before Wait is called, as it may right after didOpen
+	waiter := client.ExpectDiagnostics(uri)
+	HandleDiagnostics(client, []byte(`{"uri":"file:///tmp/main.go","diagnostics":[]}`))
+	assert.True(t, waiter.Wait(context.Background(), 10*time.Millisecond))
+
+	// A cancelled waiter is removed and reports that nothing was published
+	waiter = client.ExpectDiagnostics("file:///tmp/other.go")
+	assert.False(t, waiter.Cancel())
+	assert.Empty(t, client.diagnosticsWaiters)
+}
+
+func TestStopKillsUnresponsiveServer(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep is not available")
+	}
+
+	// sleep never answers the shutdown request or reads its stdin
+	client, err := NewClient("sleep", "30")
+	require.NoError(t, err)
+
+	start := time.Now()
+	err = client.Stop(context.Background())
+	assert.Error(t, err, "the process should have been killed")
+	assert.NotNil(t, client.Cmd.ProcessState)
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
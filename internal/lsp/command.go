@@ -0,0 +1,51 @@
+package lsp
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// installHints tells how to install well known language servers, by command name
+var installHints = map[string]string{
+	"gopls":                      "Install it with: go install golang.org/x/tools/gopls@latest",
+	"clangd":                     "Install it with your package manager, such as apt install clangd or brew install llvm, or see https://clangd.llvm.org/installation",
+	"rust-analyzer":              "Install it with: rustup component add rust-analyzer",
+	"pyright-langserver":         "Install it with: npm install -g pyright",
+	"typescript-language-server": "Install it with: npm install -g typescript typescript-language-server",
+}
+
+// CheckCommand verifies that a language server command can be run, so that a
+// missing server is reported by name with a hint on how to install it rather than
+// by a bare exec error
+func CheckCommand(command string) error {
+	lspLogger.Debug("Looking up %s in PATH=%s", command, os.Getenv("PATH"))
+
+	path, err := exec.LookPath(command)
+	if err == nil {
+		lspLogger.Debug("Found %s at %s", command, path)
+		return nil
+	}
+
+	if errors.Is(err, fs.ErrPermission) {
+		return fmt.Errorf("language server %s is not executable, check its permissions", command)
+	}
+
+	where := "on PATH"
+	if strings.ContainsRune(command, filepath.Separator) {
+		where = "at that path"
+	}
+	return fmt.Errorf("language server %s was not found %s. %s", command, where, installHint(command))
+}
+
+// installHint returns how to install the server run by command
+func installHint(command string) string {
+	if hint, ok := installHints[filepath.Base(command)]; ok {
+		return hint
+	}
+	return "Install it, or pass the full path of its binary."
+}
@@ -0,0 +1,38 @@
+package lsp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckCommand(t *testing.T) {
+	dir := t.TempDir()
+	notExecutable := filepath.Join(dir, "server")
+	require.NoError(t, os.WriteFile(notExecutable, []byte("#!/bin/sh\n"), 0o644))
+
+	testCases := []struct {
+		name     string
+		command  string
+		expected string
+	}{
+		{name: "Found", command: "sh"},
+		{name: "Known server missing", command: "gopls-missing-for-test", expected: "was not found on PATH. Install it, or pass the full path"},
+		{name: "Missing path", command: filepath.Join(dir, "gopls"), expected: "was not found at that path. Install it with: go install golang.org/x/tools/gopls@latest"},
+		{name: "Not executable", command: notExecutable, expected: "is not executable"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckCommand(tc.command)
+			if tc.expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expected)
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ConfigFileName is the config file looked up in the workspace root when
+// LSP_CONFIG is not set
+const ConfigFileName = ".mcp-lsp.json"
+
+// Config is the content of the config file. It lists the language servers to run,
+// for example:
+//
+//	{
+//	  "servers": [
+//	    {"language": "go", "command": "gopls", "extensions": [".go"]},
+//	    {
+//	      "language": "cpp",
+//	      "command": "clangd",
+//	      "args": ["--background-index"],
+//	      "extensions": [".c", ".cpp", ".h"],
+//	      "languageIds": {".h": "cpp"},
+//	      "env": {"CLANGD_FLAGS": "--log=error"}
+//	    },
+//	    {"language": "python", "command": "pyright", "transport": "tcp", "address": "localhost:2087", "extensions": [".py"]}
+//	  ],
+//	  "contextLines": {"cpp": 2, "go": 8}
+//	}
+type Config struct {
+	Servers []ServerConfig `json:"servers"`
+	// ContextLines sets the lines of context shown around references by the
+	// languageId of the file they are in, such as {"cpp": 2, "go": 8}, over
+	// LSP_CONTEXT_LINES
+	ContextLines map[string]int `json:"contextLines,omitempty"`
+}
+
+// LoadConfig reads the config file named by LSP_CONFIG, or ConfigFileName in the
+// workspace directory. It returns nil without an error if neither exists.
+func LoadConfig(workspaceDir string) (*Config, error) {
+	path := os.Getenv("LSP_CONFIG")
+	explicit := path != ""
+	if !explicit {
+		path = filepath.Join(workspaceDir, ConfigFileName)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !explicit {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	config, err := ParseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	for i := range config.Servers {
+		if dir := config.Servers[i].Dir; dir != "" && !filepath.IsAbs(dir) {
+			config.Servers[i].Dir = filepath.Join(workspaceDir, dir)
+		}
+	}
+	lspLogger.Info("Loaded %d language servers from %s", len(config.Servers), path)
+	return config, nil
+}
+
+// ParseConfig decodes and validates a config file
+func ParseConfig(data []byte) (*Config, error) {
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	for i := range config.Servers {
+		server := &config.Servers[i]
+		switch server.Transport {
+		case "", TransportStdio:
+			if server.Command == "" {
+				return nil, fmt.Errorf("server %d (%s) has no command", i+1, server.Language)
+			}
+		case TransportTCP:
+			if err := checkAddress(server.Address); err != nil {
+				return nil, fmt.Errorf("server %d (%s): %w", i+1, server.Language, err)
+			}
+		default:
+			return nil, fmt.Errorf("server %d (%s) has unknown transport %q, expected %q or %q", i+1, server.Language, server.Transport, TransportStdio, TransportTCP)
+		}
+		for j, ext := range server.Extensions {
+			server.Extensions[j] = normalizeExtension(ext)
+		}
+		if len(server.LanguageIDs) > 0 {
+			languageIDs := make(map[string]string, len(server.LanguageIDs))
+			for ext, id := range server.LanguageIDs {
+				if normalizeExtension(ext) == "" || id == "" {
+					return nil, fmt.Errorf("server %d (%s) has an invalid languageIds entry %q: %q", i+1, server.Language, ext, id)
+				}
+				languageIDs[normalizeExtension(ext)] = id
+			}
+			server.LanguageIDs = languageIDs
+		}
+	}
+	for language, lines := range config.ContextLines {
+		if lines < 0 {
+			return nil, fmt.Errorf("contextLines for %s must not be negative, got %d", language, lines)
+		}
+	}
+	return &config, nil
+}
+
+// mergeInitializationOptions merges src into dst. Nested objects present in both are
+// merged key by key, so overriding one setting keeps the other defaults beside it;
+// any other value in src replaces the one in dst.
+func mergeInitializationOptions(dst, src map[string]any) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]any)
+		dstMap, dstIsMap := dst[key].(map[string]any)
+		if srcIsMap && dstIsMap {
+			merged := make(map[string]any, len(dstMap))
+			mergeInitializationOptions(merged, dstMap)
+			mergeInitializationOptions(merged, srcMap)
+			dst[key] = merged
+			continue
+		}
+		dst[key] = value
+	}
+}
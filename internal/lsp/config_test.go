@@ -0,0 +1,123 @@
+package lsp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	config, err := ParseConfig([]byte(`{
+		"servers": [
+			{"language": "go", "command": "gopls"},
+			{
+				"language": "cpp",
+				"command": "clangd",
+				"args": ["--background-index"],
+				"extensions": ["CPP", "h"],
+				"workingDir": "build",
+				"env": {"CLANGD_FLAGS": "--log=error"},
+				"languageIds": {"H": "cpp"},
+				"initializationOptions": {"fallbackFlags": ["-std=c++20"]}
+			}
+		]
+	}`))
+	require.NoError(t, err)
+	require.Len(t, config.Servers, 2)
+
+	assert.Equal(t, ServerConfig{Language: "go", Command: "gopls"}, config.Servers[0])
+
+	cpp := config.Servers[1]
+	assert.Equal(t, "clangd", cpp.Command)
+	assert.Equal(t, []string{"--background-index"}, cpp.Args)
+	assert.Equal(t, []string{".cpp", ".h"}, cpp.Extensions)
+	assert.Equal(t, "build", cpp.Dir)
+	assert.Equal(t, map[string]string{"CLANGD_FLAGS": "--log=error"}, cpp.Env)
+	assert.Equal(t, map[string]string{".h": "cpp"}, cpp.LanguageIDs)
+	assert.Equal(t, []any{"-std=c++20"}, cpp.InitializationOptions["fallbackFlags"])
+	assert.Nil(t, config.ContextLines)
+
+	config, err = ParseConfig([]byte(`{"servers": [{"command": "gopls"}], "contextLines": {"go": 8, "cpp": 0}}`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"go": 8, "cpp": 0}, config.ContextLines)
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		data string
+	}{
+		{name: "Invalid JSON", data: `{"servers": [`},
+		{name: "Missing command", data: `{"servers": [{"language": "go"}]}`},
+		{name: "Unknown transport", data: `{"servers": [{"command": "gopls", "transport": "pipe"}]}`},
+		{name: "TCP without address", data: `{"servers": [{"command": "gopls", "transport": "tcp"}]}`},
+		{name: "TCP address without port", data: `{"servers": [{"transport": "tcp", "address": "localhost"}]}`},
+		{name: "Negative contextLines", data: `{"servers": [{"command": "gopls"}], "contextLines": {"go": -1}}`},
+		{name: "Empty languageId", data: `{"servers": [{"command": "clangd", "languageIds": {".h": ""}}]}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseConfig([]byte(tc.data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("Missing file in workspace", func(t *testing.T) {
+		t.Setenv("LSP_CONFIG", "")
+		config, err := LoadConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Nil(t, config)
+	})
+
+	t.Run("Missing LSP_CONFIG file", func(t *testing.T) {
+		t.Setenv("LSP_CONFIG", filepath.Join(t.TempDir(), "missing.json"))
+		_, err := LoadConfig(t.TempDir())
+		assert.Error(t, err)
+	})
+
+	t.Run("Workspace file with relative working directory", func(t *testing.T) {
+		t.Setenv("LSP_CONFIG", "")
+		workspace := t.TempDir()
+		data := `{"servers": [{"command": "clangd", "workingDir": "build"}]}`
+		require.NoError(t, os.WriteFile(filepath.Join(workspace, ConfigFileName), []byte(data), 0644))
+
+		config, err := LoadConfig(workspace)
+		require.NoError(t, err)
+		require.Len(t, config.Servers, 1)
+		assert.Equal(t, filepath.Join(workspace, "build"), config.Servers[0].Dir)
+	})
+}
+
+func TestMergeInitializationOptions(t *testing.T) {
+	options := map[string]any{
+		"symbolMatcher": "FastFuzzy",
+		"hints": map[string]any{
+			"parameterNames":      true,
+			"assignVariableTypes": true,
+		},
+	}
+
+	mergeInitializationOptions(options, map[string]any{
+		"hints":       map[string]any{"parameterNames": false},
+		"staticcheck": true,
+	})
+
+	assert.Equal(t, map[string]any{
+		"symbolMatcher": "FastFuzzy",
+		"staticcheck":   true,
+		"hints": map[string]any{
+			"parameterNames":      false,
+			"assignVariableTypes": true,
+		},
+	}, options)
+
+	// A non-object value replaces an object default
+	mergeInitializationOptions(options, map[string]any{"hints": nil})
+	assert.Nil(t, options["hints"])
+}
@@ -1,12 +1,17 @@
 package lsp
 
 import (
+	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
+// DetectLanguageID returns the languageId sent in textDocument/didOpen for a file,
+// by its extension. Headers with a .h extension are C; clangd clients switch them
+// to C++ in C++ projects, see Client.LanguageID.
 func DetectLanguageID(uri string) protocol.LanguageKind {
 	ext := strings.ToLower(filepath.Ext(uri))
 	switch ext {
@@ -20,9 +25,9 @@ func DetectLanguageID(uri string) protocol.LanguageKind {
 		return protocol.LangClojure
 	case ".coffee":
 		return protocol.LangCoffeescript
-	case ".c":
+	case ".c", ".h":
 		return protocol.LangC
-	case ".cpp", ".cxx", ".cc", ".c++":
+	case ".cpp", ".cxx", ".cc", ".c++", ".hpp", ".hxx", ".hh", ".h++", ".ipp":
 		return protocol.LangCPP
 	case ".cs":
 		return protocol.LangCSharp
@@ -62,7 +67,7 @@ func DetectLanguageID(uri string) protocol.LanguageKind {
 		return protocol.LangIni
 	case ".java":
 		return protocol.LangJava
-	case ".js":
+	case ".js", ".mjs", ".cjs":
 		return protocol.LangJavaScript
 	case ".jsx":
 		return protocol.LangJavaScriptReact
@@ -92,7 +97,7 @@ func DetectLanguageID(uri string) protocol.LanguageKind {
 		return protocol.LangPowershell
 	case ".pug", ".jade":
 		return protocol.LangPug
-	case ".py":
+	case ".py", ".pyi":
 		return protocol.LangPython
 	case ".r":
 		return protocol.LangR
@@ -116,7 +121,7 @@ func DetectLanguageID(uri string) protocol.LanguageKind {
 		return protocol.LangSQL
 	case ".swift":
 		return protocol.LangSwift
-	case ".ts":
+	case ".ts", ".mts", ".cts":
 		return protocol.LangTypeScript
 	case ".tsx":
 		return protocol.LangTypeScriptReact
@@ -130,3 +135,95 @@ func DetectLanguageID(uri string) protocol.LanguageKind {
 		return protocol.LanguageKind("") // Unknown language
 	}
 }
+
+// languageIDsFromEnv reads languageId overrides from LSP_LANGUAGE_IDS, a comma
+// separated list of extension=languageId pairs such as ".h=cpp,.inc=cpp". Invalid
+// entries are logged and skipped.
+func languageIDsFromEnv() map[string]string {
+	value := os.Getenv("LSP_LANGUAGE_IDS")
+	if value == "" {
+		return nil
+	}
+	ids, err := parseLanguageIDs(value)
+	if err != nil {
+		lspLogger.Warn("Invalid LSP_LANGUAGE_IDS %q: %v", value, err)
+	}
+	return ids
+}
+
+// parseLanguageIDs parses extension=languageId pairs separated by commas. It
+// returns the valid pairs along with an error naming the first invalid one.
+func parseLanguageIDs(value string) (map[string]string, error) {
+	ids := make(map[string]string)
+	var firstErr error
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		ext, id, ok := strings.Cut(pair, "=")
+		ext = normalizeExtension(ext)
+		id = strings.TrimSpace(id)
+		if !ok || ext == "" || id == "" {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("expected extension=languageId, got %q", strings.TrimSpace(pair))
+			}
+			continue
+		}
+		ids[ext] = id
+	}
+	return ids, firstErr
+}
+
+// LanguageID returns the languageId the client sends when opening a file: the one
+// configured for its extension, or else the one DetectLanguageID picks
+func (c *Client) LanguageID(path string) protocol.LanguageKind {
+	ext := strings.ToLower(filepath.Ext(path))
+	c.languageIDsMu.RLock()
+	id, ok := c.languageIDs[ext]
+	c.languageIDsMu.RUnlock()
+	if ok {
+		return protocol.LanguageKind(id)
+	}
+	return DetectLanguageID(path)
+}
+
+// setDefaultLanguageID maps ext to id unless the config or environment already
+// maps it
+func (c *Client) setDefaultLanguageID(ext string, id protocol.LanguageKind) {
+	c.languageIDsMu.Lock()
+	defer c.languageIDsMu.Unlock()
+	if _, ok := c.languageIDs[ext]; ok {
+		return
+	}
+	if c.languageIDs == nil {
+		c.languageIDs = make(map[string]string)
+	}
+	c.languageIDs[ext] = string(id)
+}
+
+// serverExtensions are the source file extensions of well known language servers,
+// by a name their command contains
+var serverExtensions = []struct {
+	name       string
+	extensions []string
+}{
+	{"gopls", []string{".go"}},
+	{"clangd", []string{".c", ".cc", ".cpp", ".cxx", ".c++", ".h", ".hh", ".hpp", ".hxx", ".m", ".mm"}},
+	{"rust-analyzer", []string{".rs"}},
+	{"pyright", []string{".py", ".pyi"}},
+	{"pylsp", []string{".py", ".pyi"}},
+	{"jedi-language-server", []string{".py", ".pyi"}},
+	{"typescript-language-server", []string{".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs"}},
+}
+
+// ServerExtensions returns the file extensions of the language server run by command,
+// or nil if it is not a server it knows
+func ServerExtensions(command string) []string {
+	command = strings.ToLower(filepath.Base(command))
+	for _, server := range serverExtensions {
+		if strings.Contains(command, server.name) {
+			return server.extensions
+		}
+	}
+	return nil
+}
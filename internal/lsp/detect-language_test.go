@@ -0,0 +1,70 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerExtensions(t *testing.T) {
+	assert.Equal(t, []string{".go"}, ServerExtensions("/usr/local/bin/gopls"))
+	assert.Contains(t, ServerExtensions("clangd-17"), ".h")
+	assert.Contains(t, ServerExtensions("pyright-langserver"), ".py")
+	assert.Nil(t, ServerExtensions("/opt/bin/my-server"))
+
+	client := &Client{command: "gopls", extensions: []string{".tmpl"}}
+	assert.Equal(t, []string{".tmpl"}, client.Extensions())
+	client.extensions = nil
+	assert.Equal(t, []string{".go"}, client.Extensions())
+}
+
+func TestDetectLanguageID(t *testing.T) {
+	testCases := []struct {
+		path     string
+		expected protocol.LanguageKind
+	}{
+		{"/src/main.go", protocol.LangGo},
+		{"/src/lib.c", protocol.LangC},
+		{"/src/lib.h", protocol.LangC},
+		{"/src/Widget.CPP", protocol.LangCPP},
+		{"/src/widget.hpp", protocol.LangCPP},
+		{"/src/widget.hh", protocol.LangCPP},
+		{"/src/app.ts", protocol.LangTypeScript},
+		{"/src/app.mts", protocol.LangTypeScript},
+		{"/src/App.tsx", protocol.LangTypeScriptReact},
+		{"/src/index.mjs", protocol.LangJavaScript},
+		{"/src/App.jsx", protocol.LangJavaScriptReact},
+		{"/src/stubs.pyi", protocol.LangPython},
+		{"/src/notes.txt", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			assert.Equal(t, tc.expected, DetectLanguageID(tc.path))
+		})
+	}
+}
+
+func TestParseLanguageIDs(t *testing.T) {
+	ids, err := parseLanguageIDs(".h=cpp, INC = c,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{".h": "cpp", ".inc": "c"}, ids)
+
+	ids, err = parseLanguageIDs(".h=cpp,tsx,.x=")
+	assert.Error(t, err)
+	assert.Equal(t, map[string]string{".h": "cpp"}, ids)
+}
+
+func TestClientLanguageID(t *testing.T) {
+	client := &Client{languageIDs: map[string]string{".inc": "cpp"}}
+	assert.Equal(t, protocol.LanguageKind("cpp"), client.LanguageID("/src/table.inc"))
+	assert.Equal(t, protocol.LangC, client.LanguageID("/src/lib.h"))
+
+	// Detected defaults don't replace configured ones
+	client.setDefaultLanguageID(".h", protocol.LangCPP)
+	client.setDefaultLanguageID(".inc", protocol.LangC)
+	assert.Equal(t, protocol.LangCPP, client.LanguageID("/src/lib.H"))
+	assert.Equal(t, protocol.LanguageKind("cpp"), client.LanguageID("/src/table.inc"))
+}
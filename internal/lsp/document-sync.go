@@ -0,0 +1,166 @@
+package lsp
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// textDocumentSyncKind returns how the server wants document changes sent
+func (c *Client) textDocumentSyncKind() protocol.TextDocumentSyncKind {
+	// The capability is either a bare sync kind or a TextDocumentSyncOptions object
+	switch v := c.capabilities.TextDocumentSync.(type) {
+	case float64:
+		return protocol.TextDocumentSyncKind(v)
+	case map[string]any:
+		if change, ok := v["change"].(float64); ok {
+			return protocol.TextDocumentSyncKind(change)
+		}
+	}
+	return protocol.Full
+}
+
+// PositionEncoding returns the encoding the server counts characters in, UTF-16
+// unless it chose another
+func (c *Client) PositionEncoding() protocol.PositionEncodingKind {
+	if c.capabilities.PositionEncoding != nil {
+		return *c.capabilities.PositionEncoding
+	}
+	return protocol.UTF16
+}
+
+// SyncEdits tells the server about edits that were just written to a document on
+// disk, so its copy matches the file. Documents that are not open need no
+// notification.
+func (c *Client) SyncEdits(ctx context.Context, uri protocol.DocumentUri) error {
+	path := strings.TrimPrefix(string(uri), "file://")
+	if !c.IsFileOpen(path) {
+		return nil
+	}
+	return c.NotifyChange(ctx, path)
+}
+
+// sendChange sends a didChange notification taking the server's copy of a document
+// from previous to content. Servers that accept incremental sync are sent only the
+// part that changed, others the whole document.
+func (c *Client) sendChange(ctx context.Context, uri string, version int32, previous, content []byte) error {
+	if c.textDocumentSyncKind() != protocol.Incremental {
+		return c.sendFullChange(ctx, uri, version, content)
+	}
+
+	params := protocol.DidChangeTextDocumentParams{
+		TextDocument: protocol.VersionedTextDocumentIdentifier{
+			TextDocumentIdentifier: protocol.TextDocumentIdentifier{
+				URI: protocol.DocumentUri(uri),
+			},
+			Version: version,
+		},
+		ContentChanges: []protocol.TextDocumentContentChangeEvent{
+			contentChange(previous, content, c.PositionEncoding()),
+		},
+	}
+
+	lspLogger.Debug("Sending incremental change for %s (version %d)", uri, version)
+	return c.Notify(ctx, "textDocument/didChange", params)
+}
+
+// contentChange returns a single change event that turns previous into content,
+// replacing the range between their common prefix and suffix. The range never
+// splits a character or a \r\n line break.
+func contentChange(previous, content []byte, encoding protocol.PositionEncodingKind) protocol.TextDocumentContentChangeEvent {
+	start := 0
+	for start < len(previous) && start < len(content) && previous[start] == content[start] {
+		start++
+	}
+	for start > 0 && (previous[start-1] == '\r' ||
+		start < len(previous) && !utf8.RuneStart(previous[start]) ||
+		start < len(content) && !utf8.RuneStart(content[start])) {
+		start--
+	}
+
+	previousEnd, contentEnd := len(previous), len(content)
+	for previousEnd > start && contentEnd > start && previous[previousEnd-1] == content[contentEnd-1] {
+		previousEnd--
+		contentEnd--
+	}
+	// The rest of previous and content are the same bytes, so both ends move together
+	for previousEnd < len(previous) && (!utf8.RuneStart(previous[previousEnd]) ||
+		previous[previousEnd] == '\n' && previousEnd > 0 && previous[previousEnd-1] == '\r') {
+		previousEnd++
+		contentEnd++
+	}
+
+	return protocol.TextDocumentContentChangeEvent{
+		Value: protocol.TextDocumentContentChangePartial{
+			Range: &protocol.Range{
+				Start: offsetPosition(previous, start, encoding),
+				End:   offsetPosition(previous, previousEnd, encoding),
+			},
+			Text: string(content[start:contentEnd]),
+		},
+	}
+}
+
+// offsetPosition converts a byte offset in content to a position, with lines ended
+// by \n, \r\n or \r and characters counted in the given encoding
+func offsetPosition(content []byte, offset int, encoding protocol.PositionEncodingKind) protocol.Position {
+	var line uint32
+	lineStart := 0
+	for i := 0; i < offset; i++ {
+		switch content[i] {
+		case '\r':
+			if i+1 < len(content) && content[i+1] == '\n' {
+				continue
+			}
+			line++
+			lineStart = i + 1
+		case '\n':
+			line++
+			lineStart = i + 1
+		}
+	}
+
+	return protocol.Position{Line: line, Character: CharacterCount(string(content[lineStart:offset]), encoding)}
+}
+
+// CharacterCount returns the length of text in characters of the given encoding
+func CharacterCount(text string, encoding protocol.PositionEncodingKind) uint32 {
+	switch encoding {
+	case protocol.UTF8:
+		return uint32(len(text))
+	case protocol.UTF32:
+		return uint32(utf8.RuneCountInString(text))
+	}
+	var units uint32
+	for _, r := range text {
+		if r >= 0x10000 {
+			units += 2
+		} else {
+			units++
+		}
+	}
+	return units
+}
+
+// CharacterOffset converts a character position within line, counted in the given
+// encoding, to a byte offset. Positions past the end of the line give its length,
+// and positions inside a character give its start.
+func CharacterOffset(line string, character uint32, encoding protocol.PositionEncodingKind) int {
+	if encoding == protocol.UTF8 {
+		return min(int(character), len(line))
+	}
+	var units uint32
+	for offset, r := range line {
+		size := uint32(1)
+		if encoding != protocol.UTF32 && r >= 0x10000 {
+			size = 2
+		}
+		if units+size > character {
+			return offset
+		}
+		units += size
+	}
+	return len(line)
+}
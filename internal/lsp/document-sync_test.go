@@ -0,0 +1,250 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sentChange is a content change as sent in a didChange notification
+type sentChange struct {
+	Range *protocol.Range `json:"range"`
+	Text  string          `json:"text"`
+}
+
+// readDidChange reads a didChange notification and returns its version and changes
+func readDidChange(t *testing.T, buf *bytes.Buffer) (int32, []sentChange) {
+	t.Helper()
+	msg, err := ReadMessage(bufio.NewReader(buf))
+	require.NoError(t, err)
+	require.Equal(t, "textDocument/didChange", msg.Method)
+
+	var params struct {
+		TextDocument struct {
+			Version int32 `json:"version"`
+		} `json:"textDocument"`
+		ContentChanges []sentChange `json:"contentChanges"`
+	}
+	require.NoError(t, json.Unmarshal(msg.Params, &params))
+	return params.TextDocument.Version, params.ContentChanges
+}
+
+// positionOffset converts a position with UTF-16 characters to a byte offset, the
+// way a server applies a change
+func positionOffset(t *testing.T, content []byte, pos protocol.Position) int {
+	t.Helper()
+	offset := 0
+	for line := uint32(0); line < pos.Line; line++ {
+		i := bytes.IndexAny(content[offset:], "\r\n")
+		require.GreaterOrEqual(t, i, 0, "line %d is past the end of the document", pos.Line)
+		offset += i + 1
+		if content[offset-1] == '\r' && offset < len(content) && content[offset] == '\n' {
+			offset++
+		}
+	}
+	for units := uint32(0); units < pos.Character; {
+		r, size := utf8.DecodeRune(content[offset:])
+		require.NotZero(t, size, "character %d is past the end of line %d", pos.Character, pos.Line)
+		offset += size
+		units++
+		if r >= 0x10000 {
+			units++
+		}
+	}
+	return offset
+}
+
+// applyChanges applies content changes to a document as a server would
+func applyChanges(t *testing.T, content []byte, changes []sentChange) []byte {
+	t.Helper()
+	for _, change := range changes {
+		if change.Range == nil {
+			content = []byte(change.Text)
+			continue
+		}
+		start := positionOffset(t, content, change.Range.Start)
+		end := positionOffset(t, content, change.Range.End)
+		content = append(append(append([]byte{}, content[:start]...), change.Text...), content[end:]...)
+	}
+	return content
+}
+
+func newSyncTestClient(buf *bytes.Buffer) *Client {
+	return &Client{
+		stdin:        nopWriteCloser{buf},
+		openFiles:    make(map[string]*OpenFileInfo),
+		capabilities: protocol.ServerCapabilities{TextDocumentSync: map[string]any{"change": float64(protocol.Incremental)}},
+	}
+}
+
+func TestSyncEditsSendsIncrementalChange(t *testing.T) {
+	var buf bytes.Buffer
+	client := newSyncTestClient(&buf)
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "main.go")
+	before := []byte("a := 1\nb := 2\nc := 3\n")
+	require.NoError(t, os.WriteFile(path, before, 0644))
+	require.NoError(t, client.OpenFile(ctx, path))
+	buf.Reset()
+
+	after := []byte("a := 1\nb := 20\nc := 3\n")
+	require.NoError(t, os.WriteFile(path, after, 0644))
+	require.NoError(t, client.SyncEdits(ctx, protocol.DocumentUri("file://"+path)))
+
+	version, changes := readDidChange(t, &buf)
+	assert.Equal(t, int32(2), version)
+	require.Len(t, changes, 1)
+	require.NotNil(t, changes[0].Range)
+	// Only the changed part of the document is sent
+	assert.Equal(t, protocol.Range{Start: protocol.Position{Line: 1, Character: 6}, End: protocol.Position{Line: 1, Character: 6}}, *changes[0].Range)
+	assert.Equal(t, "0", changes[0].Text)
+	assert.Equal(t, string(after), string(applyChanges(t, before, changes)))
+
+	// The watcher noticing the same change on disk does not resend it
+	require.NoError(t, client.NotifyChange(ctx, path))
+	assert.Zero(t, buf.Len())
+}
+
+func TestSyncEditsMatchesDisk(t *testing.T) {
+	lineRange := func(startLine, endLine, endChar uint32) protocol.Range {
+		return protocol.Range{
+			Start: protocol.Position{Line: startLine, Character: 0},
+			End:   protocol.Position{Line: endLine, Character: endChar},
+		}
+	}
+
+	testCases := []struct {
+		name    string
+		content string
+		edit    protocol.TextEdit
+	}{
+		{
+			name:    "Delete whole lines",
+			content: "package main\n\nfunc a() {}\nfunc b() {}\nfunc c() {}\n",
+			edit:    protocol.TextEdit{Range: lineRange(2, 3, 11), NewText: ""},
+		},
+		{
+			name:    "Delete whole lines with CRLF",
+			content: "package main\r\n\r\nfunc a() {}\r\nfunc b() {}\r\nfunc c() {}\r\n",
+			edit:    protocol.TextEdit{Range: lineRange(2, 3, 11), NewText: ""},
+		},
+		{
+			name:    "Replace lines with CRLF",
+			content: "one\r\ntwo\r\nthree\r\n",
+			edit:    protocol.TextEdit{Range: lineRange(1, 1, 3), NewText: "2\n2.5"},
+		},
+		{
+			name:    "Replace line with multibyte characters",
+			content: "s := \"héllo 🌍\"\nt := 1\n",
+			edit:    protocol.TextEdit{Range: lineRange(0, 0, 19), NewText: "s := \"hallo 🌎\""},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			client := newSyncTestClient(&buf)
+			ctx := context.Background()
+
+			path := filepath.Join(t.TempDir(), "main.go")
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0644))
+			require.NoError(t, client.OpenFile(ctx, path))
+			buf.Reset()
+
+			uri := protocol.DocumentUri("file://" + path)
+			require.NoError(t, utilities.ApplyTextEdits(uri, []protocol.TextEdit{tc.edit}))
+			require.NoError(t, client.SyncEdits(ctx, uri))
+
+			onDisk, err := os.ReadFile(path)
+			require.NoError(t, err)
+			require.NotEqual(t, tc.content, string(onDisk))
+			_, changes := readDidChange(t, &buf)
+			assert.Equal(t, string(onDisk), string(applyChanges(t, []byte(tc.content), changes)))
+		})
+	}
+}
+
+func TestContentChange(t *testing.T) {
+	testCases := []struct {
+		name     string
+		previous string
+		content  string
+	}{
+		{"Insert at start", "b\n", "a\nb\n"},
+		{"Append", "a\n", "a\nb\n"},
+		{"Delete everything", "a\nb\n", ""},
+		{"From empty", "", "a\n"},
+		{"Repeated lines", "x\nx\nx\n", "x\nx\n"},
+		{"LF to CRLF", "a\nb\n", "a\r\nb\r\n"},
+		{"Inside CRLF", "a\r\nb\r\n", "a\r\r\nb\r\n"},
+		{"Lone CR line breaks", "a\rb\rc", "a\rc"},
+		{"Same lead byte", "é\n", "ã\n"},
+		{"Surrogate pair", "x🌍y\n", "x🌎y\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			event := contentChange([]byte(tc.previous), []byte(tc.content), protocol.UTF16)
+			partial, ok := event.Value.(protocol.TextDocumentContentChangePartial)
+			require.True(t, ok)
+			assert.True(t, utf8.ValidString(partial.Text))
+			changes := []sentChange{{Range: partial.Range, Text: partial.Text}}
+			assert.Equal(t, tc.content, string(applyChanges(t, []byte(tc.previous), changes)))
+		})
+	}
+}
+
+func TestOffsetPositionEncodings(t *testing.T) {
+	content := []byte("a\r\nxé🌍z")
+	offset := len(content) - 1 // before z
+
+	assert.Equal(t, protocol.Position{Line: 1, Character: 7}, offsetPosition(content, offset, protocol.UTF8))
+	assert.Equal(t, protocol.Position{Line: 1, Character: 4}, offsetPosition(content, offset, protocol.UTF16))
+	assert.Equal(t, protocol.Position{Line: 1, Character: 3}, offsetPosition(content, offset, protocol.UTF32))
+}
+
+func TestCharacterOffset(t *testing.T) {
+	line := "xé🌍z"
+	testCases := []struct {
+		name      string
+		character uint32
+		encoding  protocol.PositionEncodingKind
+		expected  int
+	}{
+		{"UTF-16 after multibyte", 4, protocol.UTF16, 7},
+		{"UTF-16 inside surrogate pair", 3, protocol.UTF16, 3},
+		{"UTF-32 after multibyte", 3, protocol.UTF32, 7},
+		{"UTF-8", 3, protocol.UTF8, 3},
+		{"Past the end", 10, protocol.UTF16, len(line)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, CharacterOffset(line, tc.character, tc.encoding))
+		})
+	}
+}
+
+func TestSyncEditsIgnoresClosedFiles(t *testing.T) {
+	var buf bytes.Buffer
+	client := &Client{
+		stdin:     nopWriteCloser{&buf},
+		openFiles: make(map[string]*OpenFileInfo),
+	}
+
+	path := filepath.Join(t.TempDir(), "main.go")
+	require.NoError(t, os.WriteFile(path, []byte("package main\n"), 0644))
+	require.NoError(t, client.SyncEdits(context.Background(), protocol.DocumentUri("file://"+path)))
+	assert.Zero(t, buf.Len())
+}
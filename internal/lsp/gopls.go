@@ -0,0 +1,106 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// goplsInitializationOptions are gopls settings sent with the initialize request
+var goplsInitializationOptions = map[string]any{
+	"symbolMatcher":      "FastFuzzy",
+	"completeUnimported": true,
+	"semanticTokens":     true,
+	// Inlay hints are off by default in gopls
+	"hints": map[string]any{
+		"assignVariableTypes":    true,
+		"compositeLiteralFields": true,
+		"constantValues":         true,
+		"functionTypeParameters": true,
+		"parameterNames":         true,
+		"rangeVariableTypes":     true,
+	},
+}
+
+// isGopls reports whether the lowercased server command path refers to gopls
+func isGopls(path string) bool {
+	return strings.Contains(path, "gopls")
+}
+
+// initializeGoplsLanguageServer initializes the gopls language server by warming
+// up its symbol index and opening the main package of the module.
+func initializeGoplsLanguageServer(ctx context.Context, client *Client, workspaceDir string) error {
+	lspLogger.Info("Initializing gopls language server with workspace: %s", workspaceDir)
+
+	// Step 1: Send a workspace/symbol query so gopls loads the workspace packages
+	if _, err := client.Symbol(ctx, protocol.WorkspaceSymbolParams{Query: ""}); err != nil {
+		lspLogger.Warn("Warmup symbol query failed (continuing anyway): %v", err)
+	} else {
+		lspLogger.Debug("Warmup symbol query completed")
+	}
+
+	// Step 2: Open the main package files of the nearest module
+	if err := openGoMainPackageFiles(ctx, client, workspaceDir); err != nil {
+		lspLogger.Warn("Failed to open main package files (continuing anyway): %v", err)
+	}
+
+	lspLogger.Info("gopls language server initialization completed successfully")
+	return nil
+}
+
+// findGoModDir returns the nearest directory at or above dir that contains a go.mod file
+func findGoModDir(dir string) (string, error) {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("no go.mod found")
+		}
+		dir = parent
+	}
+}
+
+// openGoMainPackageFiles opens the non-test files of package main in the module root
+func openGoMainPackageFiles(ctx context.Context, client *Client, workspaceDir string) error {
+	modDir, err := findGoModDir(workspaceDir)
+	if err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(modDir)
+	if err != nil {
+		return fmt.Errorf("error reading module directory: %w", err)
+	}
+
+	fileCount := 0
+	fset := token.NewFileSet()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		path := filepath.Join(modDir, name)
+		file, err := parser.ParseFile(fset, path, nil, parser.PackageClauseOnly)
+		if err != nil || file.Name.Name != "main" {
+			continue
+		}
+
+		if err := client.OpenFile(ctx, path); err != nil {
+			lspLogger.Warn("Failed to open Go file %s: %v", path, err)
+			continue
+		}
+		fileCount++
+	}
+
+	lspLogger.Info("Opened %d main package files in %s", fileCount, modDir)
+	return nil
+}
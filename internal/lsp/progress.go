@@ -0,0 +1,136 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ProgressState is the latest state of a work done progress reported by the server
+// with $/progress, such as clangd's background indexing
+type ProgressState struct {
+	Token   string
+	Title   string
+	Message string
+	// Percentage is from 0 to 100, or -1 if the server did not report one
+	Percentage int
+	Done       bool
+	UpdatedAt  time.Time
+}
+
+// progressValue holds the fields of the begin, report and end progress values
+type progressValue struct {
+	Kind       string  `json:"kind"`
+	Title      string  `json:"title"`
+	Message    string  `json:"message"`
+	Percentage *uint32 `json:"percentage"`
+}
+
+// HandleWorkDoneProgressCreate accepts the progress tokens the server creates with
+// window/workDoneProgress/create. Progress is tracked when $/progress arrives.
+func HandleWorkDoneProgressCreate(params json.RawMessage) (any, error) {
+	return nil, nil
+}
+
+// HandleProgress records a $/progress notification. Finished progress is dropped from
+// the active set but kept as the last progress.
+func HandleProgress(client *Client, params json.RawMessage) {
+	var progressParams struct {
+		Token json.RawMessage `json:"token"`
+		Value progressValue   `json:"value"`
+	}
+	if err := json.Unmarshal(params, &progressParams); err != nil {
+		lspLogger.Error("Error unmarshaling progress params: %v", err)
+		return
+	}
+	token := strings.Trim(string(progressParams.Token), `"`)
+	value := progressParams.Value
+
+	client.progressMu.Lock()
+	defer client.progressMu.Unlock()
+	if client.progress == nil {
+		client.progress = make(map[string]*ProgressState)
+	}
+
+	state, ok := client.progress[token]
+	if !ok {
+		state = &ProgressState{Token: token, Percentage: -1}
+	}
+	if value.Title != "" {
+		state.Title = value.Title
+	}
+	// An unset message keeps the previous one
+	if value.Message != "" {
+		state.Message = value.Message
+	}
+	if value.Percentage != nil {
+		state.Percentage = int(*value.Percentage)
+	}
+	state.UpdatedAt = time.Now()
+
+	switch value.Kind {
+	case "end":
+		state.Done = true
+		delete(client.progress, token)
+		lspLogger.Debug("Progress finished: %s %s", state.Title, state.Message)
+	default:
+		client.progress[token] = state
+		lspLogger.Debug("Progress %s: %s %s (%d%%)", value.Kind, state.Title, state.Message, state.Percentage)
+	}
+	client.lastProgress = state
+}
+
+// ActiveProgress returns the progress the server has begun and not yet ended, oldest
+// update first
+func (c *Client) ActiveProgress() []ProgressState {
+	c.progressMu.Lock()
+	defer c.progressMu.Unlock()
+
+	states := make([]ProgressState, 0, len(c.progress))
+	for _, state := range c.progress {
+		states = append(states, *state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].UpdatedAt.Before(states[j].UpdatedAt)
+	})
+	return states
+}
+
+// LastProgress returns the most recent progress update, which may have ended, and
+// false if the server never reported progress
+func (c *Client) LastProgress() (ProgressState, bool) {
+	c.progressMu.Lock()
+	defer c.progressMu.Unlock()
+
+	if c.lastProgress == nil {
+		return ProgressState{}, false
+	}
+	return *c.lastProgress, true
+}
+
+// resetProgress forgets the progress of a previous server process
+func (c *Client) resetProgress() {
+	c.progressMu.Lock()
+	defer c.progressMu.Unlock()
+	c.progress = make(map[string]*ProgressState)
+	c.lastProgress = nil
+}
+
+// WaitForProgress blocks until the server has no active progress or the timeout
+// expires, and returns true if no progress is active. It does not wait for progress
+// that has not begun yet.
+func (c *Client) WaitForProgress(ctx context.Context, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		if len(c.ActiveProgress()) == 0 {
+			return true
+		}
+		if err := sleepContext(ctx, 100*time.Millisecond); err != nil {
+			return false
+		}
+	}
+}
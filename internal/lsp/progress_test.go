@@ -0,0 +1,52 @@
+package lsp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleProgress(t *testing.T) {
+	client := &Client{}
+
+	_, ok := client.LastProgress()
+	assert.False(t, ok)
+
+	HandleProgress(client, []byte(`{"token":"backgroundIndexProgress","value":{"kind":"begin","title":"indexing","percentage":0}}`))
+	HandleProgress(client, []byte(`{"token":"backgroundIndexProgress","value":{"kind":"report","message":"3/10","percentage":30}}`))
+	HandleProgress(client, []byte(`{"token":7,"value":{"kind":"begin","title":"Loading"}}`))
+
+	active := client.ActiveProgress()
+	require.Len(t, active, 2)
+	assert.Equal(t, "backgroundIndexProgress", active[0].Token)
+	assert.Equal(t, "indexing", active[0].Title)
+	assert.Equal(t, "3/10", active[0].Message)
+	assert.Equal(t, 30, active[0].Percentage)
+	assert.Equal(t, "7", active[1].Token)
+	assert.Equal(t, -1, active[1].Percentage)
+
+	// Ending a progress removes it from the active set but keeps it as the last
+	HandleProgress(client, []byte(`{"token":"backgroundIndexProgress","value":{"kind":"end"}}`))
+	assert.Len(t, client.ActiveProgress(), 1)
+	last, ok := client.LastProgress()
+	require.True(t, ok)
+	assert.True(t, last.Done)
+	assert.Equal(t, "indexing", last.Title)
+}
+
+func TestWaitForProgress(t *testing.T) {
+	client := &Client{}
+	assert.True(t, client.WaitForProgress(context.Background(), time.Second))
+
+	HandleProgress(client, []byte(`{"token":"index","value":{"kind":"begin","title":"indexing"}}`))
+	assert.False(t, client.WaitForProgress(context.Background(), 150*time.Millisecond))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		HandleProgress(client, []byte(`{"token":"index","value":{"kind":"end"}}`))
+	}()
+	assert.True(t, client.WaitForProgress(context.Background(), 5*time.Second))
+}
@@ -86,6 +86,12 @@ type ResponseError struct {
 	Message string `json:"message"`
 }
 
+// Error implements error, so callers can check the code of a failed request with
+// errors.As
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("%s (code: %d)", e.Message, e.Code)
+}
+
 func NewRequest(id any, method string, params any) (*Message, error) {
 	paramsJSON, err := json.Marshal(params)
 	if err != nil {
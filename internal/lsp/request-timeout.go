@@ -0,0 +1,71 @@
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// defaultRequestTimeout bounds how long a request waits for the server
+const defaultRequestTimeout = 60 * time.Second
+
+// ErrRequestTimeout is returned when the server does not answer a request in time
+var ErrRequestTimeout = errors.New("request timed out")
+
+// requestTimeoutFromEnv reads the request timeout in milliseconds from
+// LSP_REQUEST_TIMEOUT_MS. A value of 0 disables the timeout.
+func requestTimeoutFromEnv() time.Duration {
+	value := os.Getenv("LSP_REQUEST_TIMEOUT_MS")
+	if value == "" {
+		return defaultRequestTimeout
+	}
+	ms, err := strconv.Atoi(value)
+	if err != nil || ms < 0 {
+		lspLogger.Warn("Invalid LSP_REQUEST_TIMEOUT_MS %q, using %s", value, defaultRequestTimeout)
+		return defaultRequestTimeout
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// hasRequestTimeout reports whether the request timeout applies to method. Server
+// startup can legitimately take a long time on large workspaces.
+func hasRequestTimeout(method string) bool {
+	return method != "initialize"
+}
+
+// describeRequest names the symbol query or document a request is about, for
+// error messages. It returns an empty string if the params mention neither.
+func describeRequest(params any) string {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return ""
+	}
+	var fields struct {
+		Query        *string `json:"query"`
+		TextDocument struct {
+			URI string `json:"uri"`
+		} `json:"textDocument"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return ""
+	}
+
+	switch {
+	case fields.Query != nil:
+		return fmt.Sprintf("query %q", *fields.Query)
+	case fields.TextDocument.URI != "":
+		return fields.TextDocument.URI
+	}
+	return ""
+}
+
+// requestTimeoutError builds the error for a request that timed out
+func requestTimeoutError(method string, params any, timeout time.Duration) error {
+	if subject := describeRequest(params); subject != "" {
+		return fmt.Errorf("%w: %s for %s got no response within %s, the language server may still be indexing (set LSP_REQUEST_TIMEOUT_MS to wait longer)", ErrRequestTimeout, method, subject, timeout)
+	}
+	return fmt.Errorf("%w: %s got no response within %s, the language server may still be indexing (set LSP_REQUEST_TIMEOUT_MS to wait longer)", ErrRequestTimeout, method, timeout)
+}
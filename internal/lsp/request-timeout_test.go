@@ -0,0 +1,125 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeRequest(t *testing.T) {
+	testCases := []struct {
+		name     string
+		params   any
+		expected string
+	}{
+		{
+			name:     "Workspace symbol query",
+			params:   protocol.WorkspaceSymbolParams{Query: "Foo.Bar"},
+			expected: `query "Foo.Bar"`,
+		},
+		{
+			name:     "Empty workspace symbol query",
+			params:   protocol.WorkspaceSymbolParams{},
+			expected: `query ""`,
+		},
+		{
+			name: "Document request",
+			params: protocol.ReferenceParams{
+				TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+					TextDocument: protocol.TextDocumentIdentifier{URI: "file:///src/main.go"},
+				},
+			},
+			expected: "file:///src/main.go",
+		},
+		{
+			name:     "Neither",
+			params:   protocol.ExecuteCommandParams{Command: "gopls.tidy"},
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, describeRequest(tc.params))
+		})
+	}
+}
+
+func TestLateResponseIsDiscarded(t *testing.T) {
+	toServer, clientOut := io.Pipe()
+	clientIn, fromServer := io.Pipe()
+	defer clientOut.Close()
+	defer fromServer.Close()
+
+	closed := make(chan struct{})
+	client := &Client{
+		stdin:                clientOut,
+		connClosed:           closed,
+		handlers:             make(map[string]chan *Message),
+		notificationHandlers: make(map[string]NotificationHandler),
+		requestTimeout:       50 * time.Millisecond,
+	}
+	go client.handleMessages(bufio.NewReader(clientIn), clientOut, closed)
+
+	// The server answers each request twice, the first one only after it timed out.
+	// It logs each request it receives, so it is busy rather than hung and isn't
+	// restarted.
+	requests := make(chan *Message)
+	go func() {
+		reader := bufio.NewReader(toServer)
+		for {
+			msg, err := ReadMessage(reader)
+			if err != nil {
+				close(requests)
+				return
+			}
+			if msg.ID != nil {
+				logMessage, _ := NewNotification("window/logMessage", protocol.LogMessageParams{Type: protocol.Log, Message: "working"})
+				if err := WriteMessage(fromServer, logMessage); err != nil {
+					return
+				}
+				requests <- msg
+			}
+		}
+	}()
+	respond := func(msg *Message, result string) {
+		data, _ := json.Marshal(result)
+		require.NoError(t, WriteMessage(fromServer, &Message{JSONRPC: "2.0", ID: msg.ID, Result: data}))
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- client.Call(context.Background(), "workspace/symbol", nil, nil)
+	}()
+	slow := <-requests
+	err := <-errs
+	assert.True(t, errors.Is(err, ErrRequestTimeout), "expected a timeout, got %v", err)
+
+	respond(slow, "late")
+	respond(slow, "duplicate")
+
+	var result string
+	go func() {
+		errs <- client.Call(context.Background(), "workspace/symbol", nil, &result)
+	}()
+	fast := <-requests
+	respond(fast, "on time")
+	respond(fast, "duplicate")
+	require.NoError(t, <-errs)
+	assert.Equal(t, "on time", result)
+
+	// Nothing is left waiting once the duplicate has been read
+	assert.Eventually(t, func() bool {
+		client.handlersMu.RLock()
+		defer client.handlersMu.RUnlock()
+		return len(client.handlers) == 0
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,200 @@
+package lsp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrServerExited is returned by requests that fail because the language server
+// process exited or its connection was lost
+var ErrServerExited = errors.New("language server exited")
+
+// ErrServerUnresponsive is returned by requests that timed out without the server
+// sending any message after they were sent
+var ErrServerUnresponsive = errors.New("language server is unresponsive")
+
+// retryableMethods are the requests Call retries after restarting the server. They
+// only read, so sending one twice is harmless. Requests such as
+// workspace/executeCommand, or rename whose edits tools apply, are left out.
+var retryableMethods = map[string]bool{
+	"workspace/symbol":                  true,
+	"workspaceSymbol/resolve":           true,
+	"workspace/diagnostic":              true,
+	"textDocument/definition":           true,
+	"textDocument/declaration":          true,
+	"textDocument/typeDefinition":       true,
+	"textDocument/implementation":       true,
+	"textDocument/references":           true,
+	"textDocument/hover":                true,
+	"textDocument/signatureHelp":        true,
+	"textDocument/completion":           true,
+	"completionItem/resolve":            true,
+	"textDocument/documentSymbol":       true,
+	"textDocument/documentHighlight":    true,
+	"textDocument/documentLink":         true,
+	"documentLink/resolve":              true,
+	"textDocument/foldingRange":         true,
+	"textDocument/selectionRange":       true,
+	"textDocument/semanticTokens/full":  true,
+	"textDocument/inlayHint":            true,
+	"textDocument/codeLens":             true,
+	"textDocument/diagnostic":           true,
+	"textDocument/moniker":              true,
+	"textDocument/prepareRename":        true,
+	"textDocument/prepareCallHierarchy": true,
+	"callHierarchy/incomingCalls":       true,
+	"callHierarchy/outgoingCalls":       true,
+	"textDocument/prepareTypeHierarchy": true,
+	"typeHierarchy/supertypes":          true,
+	"typeHierarchy/subtypes":            true,
+	"textDocument/switchSourceHeader":   true,
+	"textDocument/ast":                  true,
+}
+
+const (
+	// maxConsecutiveRestarts is how many times a crashing server is relaunched
+	// before giving up
+	maxConsecutiveRestarts = 5
+	// restartResetAfter is how long a server must stay up for earlier crashes to
+	// be forgotten
+	restartResetAfter = time.Minute
+	// Bounds of the exponential backoff between restarts
+	restartBaseDelay = 500 * time.Millisecond
+	restartMaxDelay  = 30 * time.Second
+)
+
+// restartDelay returns how long to wait before the nth consecutive restart,
+// starting at 1. The first restart happens immediately.
+func restartDelay(attempt int) time.Duration {
+	if attempt <= 1 {
+		return 0
+	}
+	delay := restartBaseDelay
+	for i := 2; i < attempt && delay < restartMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, restartMaxDelay)
+}
+
+// connGeneration identifies the current connection. It increases on every restart.
+func (c *Client) connGeneration() int {
+	c.connMu.RLock()
+	defer c.connMu.RUnlock()
+	return c.generation
+}
+
+// shouldRestart reports whether a request that failed because the server exited
+// should trigger a restart
+func (c *Client) shouldRestart(method string) bool {
+	// Requests made while restarting or shutting down fail normally
+	if c.closing.Load() || c.restarting.Load() {
+		return false
+	}
+	return method != "shutdown" && method != "exit"
+}
+
+// restartAfterCrash relaunches the server, after it crashed or hung, if the
+// connection identified by generation is still the current one. Concurrent callers that saw the same crash
+// wait for a single restart.
+func (c *Client) restartAfterCrash(ctx context.Context, generation int) error {
+	c.restartMu.Lock()
+	defer c.restartMu.Unlock()
+
+	if c.closing.Load() {
+		return fmt.Errorf("client is closing")
+	}
+	if c.connGeneration() != generation {
+		// Someone else already restarted the server
+		return nil
+	}
+
+	c.connMu.RLock()
+	uptime := time.Since(c.startedAt)
+	c.connMu.RUnlock()
+	if uptime > restartResetAfter {
+		c.restarts = 0
+	}
+	if c.restarts >= maxConsecutiveRestarts {
+		return fmt.Errorf("language server crashed %d times in a row, not restarting", c.restarts)
+	}
+	c.restarts++
+
+	if delay := restartDelay(c.restarts); delay > 0 {
+		lspLogger.Info("Waiting %s before restarting language server", delay)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return c.restart(ctx)
+}
+
+// restart tears down the current server process, launches a new one, or
+// reconnects to a server reached over TCP, initializes it and reopens the files
+// that were open. The caller must hold restartMu.
+func (c *Client) restart(ctx context.Context) error {
+	c.restarting.Store(true)
+	defer c.restarting.Store(false)
+
+	lspLogger.Info("Restarting language server %s (attempt %d)", c.command, c.restarts)
+
+	// Stop the old process in case it is hung rather than dead
+	c.connMu.RLock()
+	oldCmd, oldStdin := c.Cmd, c.stdin
+	c.connMu.RUnlock()
+	if err := oldStdin.Close(); err != nil {
+		lspLogger.Debug("Failed to close stdin of old server: %v", err)
+	}
+	// A server reached over TCP has no process here, it is reconnected to
+	if oldCmd != nil {
+		if oldCmd.Process != nil {
+			if err := oldCmd.Process.Kill(); err != nil {
+				lspLogger.Debug("Failed to kill old server: %v", err)
+			}
+		}
+		if err := oldCmd.Wait(); err != nil {
+			lspLogger.Debug("Old server exited: %v", err)
+		}
+	}
+
+	// Forget the files the old process had open, they are reopened below
+	c.openFilesMu.Lock()
+	reopen := make(map[string]int, len(c.openFiles))
+	for uri, fileInfo := range c.openFiles {
+		reopen[strings.TrimPrefix(uri, "file://")] = fileInfo.RefCount
+	}
+	c.openFiles = make(map[string]*OpenFileInfo)
+	c.openFilesMu.Unlock()
+
+	c.InvalidateSymbolCache()
+
+	if err := c.connect(); err != nil {
+		return err
+	}
+	if _, err := c.InitializeLSPClient(ctx, c.workspaceDir); err != nil {
+		return fmt.Errorf("failed to initialize restarted server: %w", err)
+	}
+	if err := c.WaitForServerReady(ctx); err != nil {
+		return fmt.Errorf("restarted server did not become ready: %w", err)
+	}
+
+	for path, refCount := range reopen {
+		if err := c.OpenFile(ctx, path); err != nil {
+			lspLogger.Warn("Failed to reopen %s after restart: %v", path, err)
+			continue
+		}
+		c.openFilesMu.Lock()
+		if fileInfo, ok := c.openFiles["file://"+path]; ok {
+			fileInfo.RefCount = refCount
+		}
+		c.openFilesMu.Unlock()
+	}
+
+	lspLogger.Info("Language server restarted, reopened %d files", len(reopen))
+	return nil
+}
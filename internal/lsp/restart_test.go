@@ -0,0 +1,306 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestartDelay(t *testing.T) {
+	testCases := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{attempt: 1, expected: 0},
+		{attempt: 2, expected: 500 * time.Millisecond},
+		{attempt: 3, expected: time.Second},
+		{attempt: 4, expected: 2 * time.Second},
+		{attempt: 10, expected: 30 * time.Second},
+		{attempt: 100, expected: 30 * time.Second},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, restartDelay(tc.attempt), "attempt %d", tc.attempt)
+	}
+}
+
+// fakeServerAction is how a fakeServer handles a request
+type fakeServerAction int
+
+const (
+	// Answer with the number of the connection
+	fakeReply fakeServerAction = iota
+	// Close the connection, as if the server crashed
+	fakeCrash
+	// Never answer, as if the server hung
+	fakeHang
+	// Report progress but never answer, as if the server were busy
+	fakeBusy
+)
+
+// fakeServer is a language server reached over TCP. Connections are numbered
+// from 1, and act decides how each request other than initialize is handled.
+// Methods in results are answered with that result instead of the connection number.
+type fakeServer struct {
+	listener net.Listener
+	act      func(conn int, method string) fakeServerAction
+	results  map[string]any
+
+	mu          sync.Mutex
+	connections int
+	requests    map[string]int
+}
+
+func startFakeServer(t *testing.T, act func(conn int, method string) fakeServerAction, results map[string]any) *fakeServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	s := &fakeServer{listener: listener, act: act, results: results, requests: make(map[string]int)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.connections++
+			number := s.connections
+			s.mu.Unlock()
+			go s.serve(conn, number)
+		}
+	}()
+	return s
+}
+
+func (s *fakeServer) serve(conn net.Conn, number int) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		msg, err := ReadMessage(reader)
+		if err != nil {
+			return
+		}
+		if msg.ID == nil {
+			continue // Notifications need no answer
+		}
+
+		var result any = map[string]any{"capabilities": map[string]any{}}
+		if msg.Method != "initialize" {
+			s.mu.Lock()
+			s.requests[msg.Method]++
+			s.mu.Unlock()
+
+			switch s.act(number, msg.Method) {
+			case fakeCrash:
+				return
+			case fakeHang:
+				continue
+			case fakeBusy:
+				progress, _ := json.Marshal(map[string]any{"token": "index", "value": map[string]any{"kind": "report", "message": "indexing"}})
+				WriteMessage(conn, &Message{JSONRPC: "2.0", Method: "$/progress", Params: progress})
+				continue
+			}
+			result = number
+			if fixed, ok := s.results[msg.Method]; ok {
+				result = fixed
+			}
+		}
+		if responseErr, ok := result.(*ResponseError); ok {
+			if err := WriteMessage(conn, &Message{JSONRPC: "2.0", ID: msg.ID, Error: responseErr}); err != nil {
+				return
+			}
+			continue
+		}
+		data, _ := json.Marshal(result)
+		if err := WriteMessage(conn, &Message{JSONRPC: "2.0", ID: msg.ID, Result: data}); err != nil {
+			return
+		}
+	}
+}
+
+func (s *fakeServer) counts(method string) (connections, requests int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections, s.requests[method]
+}
+
+func newFakeServerClient(t *testing.T, s *fakeServer) *Client {
+	client, err := NewClientFromConfig(ServerConfig{Transport: TransportTCP, Address: s.listener.Addr().String()})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	_, err = client.InitializeLSPClient(context.Background(), t.TempDir())
+	require.NoError(t, err)
+	return client
+}
+
+func TestCallRetriesReadOnlyRequestAfterCrash(t *testing.T) {
+	server := startFakeServer(t, func(conn int, method string) fakeServerAction {
+		if conn == 1 {
+			return fakeCrash
+		}
+		return fakeReply
+	}, nil)
+	client := newFakeServerClient(t, server)
+
+	var answeredBy int
+	require.NoError(t, client.Call(context.Background(), "textDocument/hover", nil, &answeredBy))
+	assert.Equal(t, 2, answeredBy)
+
+	connections, requests := server.counts("textDocument/hover")
+	assert.Equal(t, 2, connections)
+	assert.Equal(t, 2, requests)
+}
+
+// slowWriter pauses after every write
+type slowWriter struct {
+	io.WriteCloser
+}
+
+func (w slowWriter) Write(p []byte) (int, error) {
+	n, err := w.WriteCloser.Write(p)
+	time.Sleep(time.Millisecond)
+	return n, err
+}
+
+func TestConcurrentCallsKeepMessagesWhole(t *testing.T) {
+	server := startFakeServer(t, func(conn int, method string) fakeServerAction {
+		return fakeReply
+	}, nil)
+	client := newFakeServerClient(t, server)
+	// Pausing between the header and body writes lets other calls write in between
+	// unless writes are serialized
+	client.connMu.Lock()
+	client.stdin = slowWriter{client.stdin}
+	client.connMu.Unlock()
+
+	params := map[string]string{"padding": strings.Repeat("x", 1024)}
+	const calls = 32
+	var wg sync.WaitGroup
+	errs := make(chan error, calls)
+	for range calls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var answeredBy int
+			errs <- client.Call(context.Background(), "textDocument/hover", params, &answeredBy)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	connections, requests := server.counts("textDocument/hover")
+	assert.Equal(t, 1, connections)
+	assert.Equal(t, calls, requests)
+}
+
+func TestCallReturnsResponseError(t *testing.T) {
+	server := startFakeServer(t, func(conn int, method string) fakeServerAction {
+		return fakeReply
+	}, map[string]any{"textDocument/implementation": &ResponseError{Code: -32601, Message: "method not found"}})
+	client := newFakeServerClient(t, server)
+
+	var result any
+	err := client.Call(context.Background(), "textDocument/implementation", nil, &result)
+	var responseErr *ResponseError
+	require.ErrorAs(t, err, &responseErr)
+	assert.Equal(t, -32601, responseErr.Code)
+	assert.EqualError(t, err, "request failed: method not found (code: -32601)")
+}
+
+func TestRestartKeepsOpenFileReferences(t *testing.T) {
+	server := startFakeServer(t, func(conn int, method string) fakeServerAction {
+		if conn == 1 {
+			return fakeCrash
+		}
+		return fakeReply
+	}, nil)
+	client := newFakeServerClient(t, server)
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "main.go")
+	require.NoError(t, os.WriteFile(path, []byte("package main\n"), 0644))
+	require.NoError(t, client.OpenFile(ctx, path))
+	require.NoError(t, client.OpenFile(ctx, path))
+
+	var answeredBy int
+	require.NoError(t, client.Call(ctx, "textDocument/hover", nil, &answeredBy))
+	assert.Equal(t, 2, answeredBy)
+
+	// Both references survive the restart, so one CloseFile leaves the file open
+	require.NoError(t, client.CloseFile(ctx, path))
+	assert.True(t, client.IsFileOpen(path))
+	require.NoError(t, client.CloseFile(ctx, path))
+	assert.False(t, client.IsFileOpen(path))
+}
+
+func TestCallDoesNotRetryCommandAfterCrash(t *testing.T) {
+	server := startFakeServer(t, func(conn int, method string) fakeServerAction {
+		if conn == 1 {
+			return fakeCrash
+		}
+		return fakeReply
+	}, nil)
+	client := newFakeServerClient(t, server)
+
+	err := client.Call(context.Background(), "workspace/executeCommand", nil, nil)
+	assert.ErrorIs(t, err, ErrServerExited)
+	assert.ErrorContains(t, err, "not retried")
+
+	// The command was sent once, but the server was restarted for later requests
+	connections, requests := server.counts("workspace/executeCommand")
+	assert.Equal(t, 2, connections)
+	assert.Equal(t, 1, requests)
+
+	var answeredBy int
+	require.NoError(t, client.Call(context.Background(), "textDocument/hover", nil, &answeredBy))
+	assert.Equal(t, 2, answeredBy)
+}
+
+func TestCallRestartsHungServerOnTimeout(t *testing.T) {
+	server := startFakeServer(t, func(conn int, method string) fakeServerAction {
+		if conn == 1 {
+			return fakeHang
+		}
+		return fakeReply
+	}, nil)
+	client := newFakeServerClient(t, server)
+	client.requestTimeout = 50 * time.Millisecond
+
+	var answeredBy int
+	require.NoError(t, client.Call(context.Background(), "workspace/symbol", nil, &answeredBy))
+	assert.Equal(t, 2, answeredBy)
+
+	connections, requests := server.counts("workspace/symbol")
+	assert.Equal(t, 2, connections)
+	assert.Equal(t, 2, requests)
+}
+
+func TestCallKeepsBusyServerOnTimeout(t *testing.T) {
+	server := startFakeServer(t, func(conn int, method string) fakeServerAction {
+		return fakeBusy
+	}, nil)
+	client := newFakeServerClient(t, server)
+	client.requestTimeout = 50 * time.Millisecond
+
+	err := client.Call(context.Background(), "workspace/symbol", nil, nil)
+	assert.ErrorIs(t, err, ErrRequestTimeout)
+	assert.NotErrorIs(t, err, ErrServerUnresponsive)
+
+	connections, _ := server.counts("workspace/symbol")
+	assert.Equal(t, 1, connections)
+}
@@ -0,0 +1,67 @@
+package lsp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// InitializeRoot returns the rootPath and rootUri sent with the initialize request.
+// Both default to the workspace, and can be pointed elsewhere with LSP_ROOT_PATH and
+// LSP_ROOT_URI, for projects whose build root isn't where their sources live. Setting
+// one also sets the other, unless both are set. Tools still read files relative to
+// the workspace.
+func InitializeRoot(workspaceDir string) (string, protocol.DocumentUri, error) {
+	rootPath := os.Getenv("LSP_ROOT_PATH")
+	rootURI := os.Getenv("LSP_ROOT_URI")
+
+	if rootPath != "" {
+		abs, err := filepath.Abs(rootPath)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get absolute path for LSP_ROOT_PATH: %v", err)
+		}
+		if err := checkRootDir(abs); err != nil {
+			return "", "", fmt.Errorf("invalid LSP_ROOT_PATH: %v", err)
+		}
+		rootPath = abs
+	}
+
+	var uri protocol.DocumentUri
+	if rootURI != "" {
+		parsed, err := protocol.ParseDocumentUri(rootURI)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid LSP_ROOT_URI %q, expected a file:// URI: %v", rootURI, err)
+		}
+		if err := checkRootDir(parsed.Path()); err != nil {
+			return "", "", fmt.Errorf("invalid LSP_ROOT_URI: %v", err)
+		}
+		uri = parsed
+	}
+
+	switch {
+	case rootPath == "" && uri == "":
+		return workspaceDir, protocol.DocumentUri("file://" + workspaceDir), nil
+	case uri == "":
+		uri = protocol.DocumentUri("file://" + rootPath)
+	case rootPath == "":
+		rootPath = uri.Path()
+	}
+	return rootPath, uri, nil
+}
+
+// checkRootDir returns an error unless path is an existing directory
+func checkRootDir(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("directory does not exist: %s", path)
+		}
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("not a directory: %s", path)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package lsp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitializeRoot(t *testing.T) {
+	workspaceDir := t.TempDir()
+	buildRoot := t.TempDir()
+	otherRoot := t.TempDir()
+	file := filepath.Join(buildRoot, "CMakeLists.txt")
+	require.NoError(t, os.WriteFile(file, nil, 0644))
+
+	testCases := []struct {
+		name         string
+		rootPath     string
+		rootURI      string
+		expectedPath string
+		expectedURI  protocol.DocumentUri
+		expectError  bool
+	}{
+		{
+			name:         "Defaults to the workspace",
+			expectedPath: workspaceDir,
+			expectedURI:  protocol.DocumentUri("file://" + workspaceDir),
+		},
+		{
+			name:         "Root path sets both",
+			rootPath:     buildRoot,
+			expectedPath: buildRoot,
+			expectedURI:  protocol.DocumentUri("file://" + buildRoot),
+		},
+		{
+			name:         "Root URI sets both",
+			rootURI:      "file://" + buildRoot,
+			expectedPath: buildRoot,
+			expectedURI:  protocol.DocumentUri("file://" + buildRoot),
+		},
+		{
+			name:         "Both set independently",
+			rootPath:     otherRoot,
+			rootURI:      "file://" + buildRoot,
+			expectedPath: otherRoot,
+			expectedURI:  protocol.DocumentUri("file://" + buildRoot),
+		},
+		{
+			name:        "Missing directory",
+			rootPath:    filepath.Join(buildRoot, "missing"),
+			expectError: true,
+		},
+		{
+			name:        "Not a directory",
+			rootURI:     "file://" + file,
+			expectError: true,
+		},
+		{
+			name:        "Not a file URI",
+			rootURI:     "https://example.com/src",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("LSP_ROOT_PATH", tc.rootPath)
+			t.Setenv("LSP_ROOT_URI", tc.rootURI)
+
+			rootPath, rootURI, err := InitializeRoot(workspaceDir)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedPath, rootPath)
+			assert.Equal(t, tc.expectedURI, rootURI)
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// ServerConfig describes a language server and the file extensions routed to it
+type ServerConfig struct {
+	// Language is a label for logs, such as "go" or "cpp"
+	Language   string   `json:"language,omitempty"`
+	Extensions []string `json:"extensions,omitempty"`
+	Command    string   `json:"command"`
+	Args       []string `json:"args,omitempty"`
+	// Dir is the working directory of the server process. Relative paths are
+	// relative to the workspace. Defaults to the current directory.
+	Dir string `json:"workingDir,omitempty"`
+	// Env is added to the environment the server inherits
+	Env map[string]string `json:"env,omitempty"`
+	// InitializationOptions are sent with the initialize request, merged over the
+	// built in defaults. Nested objects are merged key by key.
+	InitializationOptions map[string]any `json:"initializationOptions,omitempty"`
+	// WarmupQueries are the workspace/symbol queries sent to clangd after startup,
+	// and WarmupDelayMs the pause between them. nil uses the defaults.
+	WarmupQueries []string `json:"warmupQueries,omitempty"`
+	WarmupDelayMs *int     `json:"warmupDelayMs,omitempty"`
+	// Transport is how the server is reached: "stdio", the default, launches
+	// Command and talks to it over stdin and stdout, "tcp" connects to a server
+	// already listening on Address (host:port) instead. Command is optional with
+	// tcp, but naming the server lets the client apply its specific setup.
+	Transport string `json:"transport,omitempty"`
+	Address   string `json:"address,omitempty"`
+	// LanguageIDs maps file extensions to the languageId sent when opening them,
+	// such as {".h": "cpp"}, for extensions the built in table gets wrong
+	LanguageIDs map[string]string `json:"languageIds,omitempty"`
+}
+
+// Name identifies the server in logs and errors
+func (s ServerConfig) Name() string {
+	if s.Command == "" {
+		return s.Transport + "://" + s.Address
+	}
+	return s.Command
+}
+
+// Check verifies that the server can be started: that its command can be run, or
+// for the tcp transport that its address is well formed
+func (s ServerConfig) Check() error {
+	if s.Transport == TransportTCP {
+		return checkAddress(s.Address)
+	}
+	return CheckCommand(s.Command)
+}
+
+// ParseServerConfig parses a server spec of the form "ext1,ext2=command arg1 arg2",
+// for example ".cpp,.h=clangd --background-index"
+func ParseServerConfig(spec string) (ServerConfig, error) {
+	exts, command, ok := strings.Cut(spec, "=")
+	if !ok {
+		return ServerConfig{}, fmt.Errorf("invalid server spec %q, expected ext1,ext2=command [args...]", spec)
+	}
+
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return ServerConfig{}, fmt.Errorf("invalid server spec %q, missing command", spec)
+	}
+
+	var config ServerConfig
+	for _, ext := range strings.Split(exts, ",") {
+		ext = normalizeExtension(ext)
+		if ext == "" {
+			continue
+		}
+		config.Extensions = append(config.Extensions, ext)
+	}
+	if len(config.Extensions) == 0 {
+		return ServerConfig{}, fmt.Errorf("invalid server spec %q, missing file extensions", spec)
+	}
+
+	config.Command = fields[0]
+	config.Args = fields[1:]
+	return config, nil
+}
+
+// normalizeExtension lowercases an extension and makes sure it starts with a dot
+func normalizeExtension(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// Router maps files to language servers by extension. The primary client handles
+// every file that no additional server claims. Additional servers are started on
+// first use.
+type Router struct {
+	ctx          context.Context
+	workspaceDir string
+	primary      *Client
+	servers      []ServerConfig
+	onStart      func(*Client)
+
+	// Started clients, indexed like servers. nil until first use.
+	clients []*Client
+	mu      sync.Mutex
+	// Held while servers[i] is starting, so it is only started once without
+	// blocking callers that need other servers
+	starting []sync.Mutex
+}
+
+// NewRouter creates a router around an already initialized primary client. onStart,
+// if not nil, is called for each additional client once it is initialized.
+func NewRouter(ctx context.Context, workspaceDir string, primary *Client, servers []ServerConfig, onStart func(*Client)) *Router {
+	return &Router{
+		ctx:          ctx,
+		workspaceDir: workspaceDir,
+		primary:      primary,
+		servers:      servers,
+		onStart:      onStart,
+		clients:      make([]*Client, len(servers)),
+		starting:     make([]sync.Mutex, len(servers)),
+	}
+}
+
+// ClientForFile returns the client responsible for a file, starting it if needed
+func (r *Router) ClientForFile(path string) (*Client, error) {
+	ext := normalizeExtension(filepath.Ext(path))
+	for i, server := range r.servers {
+		for _, serverExt := range server.Extensions {
+			if ext == serverExt {
+				return r.client(i)
+			}
+		}
+	}
+	return r.primary, nil
+}
+
+// ClientForSymbol returns the first client whose workspace/symbol search finds
+// symbolName, trying the primary client first. Additional servers are started as
+// needed. If no server knows the symbol the primary client is returned.
+func (r *Router) ClientForSymbol(ctx context.Context, symbolName string) (*Client, error) {
+	if len(r.servers) == 0 {
+		return r.primary, nil
+	}
+
+	candidates := []func() (*Client, error){
+		func() (*Client, error) { return r.primary, nil },
+	}
+	for i := range r.servers {
+		candidates = append(candidates, func() (*Client, error) { return r.client(i) })
+	}
+
+	for _, candidate := range candidates {
+		client, err := candidate()
+		if err != nil {
+			lspLogger.Warn("Skipping language server: %v", err)
+			continue
+		}
+
+		result, err := client.CachedSymbol(ctx, protocol.WorkspaceSymbolParams{Query: symbolName})
+		if err != nil {
+			lspLogger.Warn("Symbol lookup failed on %s: %v", client.Name(), err)
+			continue
+		}
+		if symbols, err := result.Results(); err == nil && len(symbols) > 0 {
+			return client, nil
+		}
+	}
+
+	return r.primary, nil
+}
+
+// Clients returns the primary client and every additional client started so far
+func (r *Router) Clients() []*Client {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	clients := []*Client{r.primary}
+	for _, client := range r.clients {
+		if client != nil {
+			clients = append(clients, client)
+		}
+	}
+	return clients
+}
+
+// WorkspaceFolders returns the workspace folders of the primary server, which
+// every other server shares
+func (r *Router) WorkspaceFolders() []string {
+	return r.primary.WorkspaceFolders()
+}
+
+// ChangeWorkspaceFolders adds and removes workspace folders on every started
+// server. Servers started later get the primary server's folders. It returns the
+// error of the primary server, others are only logged.
+func (r *Router) ChangeWorkspaceFolders(ctx context.Context, added, removed []string) error {
+	if err := r.primary.ChangeWorkspaceFolders(ctx, added, removed); err != nil {
+		return err
+	}
+	for _, client := range r.Clients()[1:] {
+		if err := client.ChangeWorkspaceFolders(ctx, added, removed); err != nil {
+			lspLogger.Error("Failed to change workspace folders of %s: %v", client.Name(), err)
+		}
+	}
+	return nil
+}
+
+// client returns the client for servers[i], starting and initializing it on first use
+func (r *Router) client(i int) (*Client, error) {
+	if client := r.startedClient(i); client != nil {
+		return client, nil
+	}
+
+	r.starting[i].Lock()
+	defer r.starting[i].Unlock()
+	// Another caller may have started the server while this one waited
+	if client := r.startedClient(i); client != nil {
+		return client, nil
+	}
+
+	client, err := r.start(r.servers[i])
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.clients[i] = client
+	r.mu.Unlock()
+	return client, nil
+}
+
+// startedClient returns the client for servers[i], or nil if it isn't started
+func (r *Router) startedClient(i int) *Client {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.clients[i]
+}
+
+// start starts and initializes a client for an additional server
+func (r *Router) start(server ServerConfig) (*Client, error) {
+	lspLogger.Info("Starting language server %s for %s", server.Name(), strings.Join(server.Extensions, ", "))
+
+	client, err := NewClientFromConfig(server)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", server.Name(), err)
+	}
+
+	// Additional servers see the same workspace folders as the primary one
+	if err := client.ChangeWorkspaceFolders(r.ctx, r.primary.additionalFolders(), nil); err != nil {
+		lspLogger.Error("Failed to set workspace folders of %s: %v", server.Name(), err)
+	}
+
+	if _, err := client.InitializeLSPClient(r.ctx, r.workspaceDir); err != nil {
+		if closeErr := client.Close(); closeErr != nil {
+			lspLogger.Error("Failed to close %s: %v", server.Name(), closeErr)
+		}
+		return nil, fmt.Errorf("failed to initialize %s: %w", server.Name(), err)
+	}
+
+	if err := client.WaitForServerReady(r.ctx); err != nil {
+		if closeErr := client.Close(); closeErr != nil {
+			lspLogger.Error("Failed to close %s: %v", server.Name(), closeErr)
+		}
+		return nil, fmt.Errorf("%s did not become ready: %w", server.Name(), err)
+	}
+
+	if r.onStart != nil {
+		r.onStart(client)
+	}
+	return client, nil
+}
@@ -0,0 +1,99 @@
+package lsp
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseServerConfig(t *testing.T) {
+	testCases := []struct {
+		name     string
+		spec     string
+		expected ServerConfig
+		wantErr  bool
+	}{
+		{
+			name:     "Single extension",
+			spec:     ".go=gopls",
+			expected: ServerConfig{Extensions: []string{".go"}, Command: "gopls", Args: []string{}},
+		},
+		{
+			name: "Multiple extensions with args",
+			spec: "cpp, .H,.hpp=clangd --background-index --log=error",
+			expected: ServerConfig{
+				Extensions: []string{".cpp", ".h", ".hpp"},
+				Command:    "clangd",
+				Args:       []string{"--background-index", "--log=error"},
+			},
+		},
+		{name: "Missing separator", spec: "gopls", wantErr: true},
+		{name: "Missing command", spec: ".go= ", wantErr: true},
+		{name: "Missing extensions", spec: ",=gopls", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config, err := ParseServerConfig(tc.spec)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, config)
+		})
+	}
+}
+
+func TestRouterStartsServerOutsideLock(t *testing.T) {
+	server := startFakeServer(t, func(conn int, method string) fakeServerAction { return fakeReply }, nil)
+	primary := newFakeServerClient(t, server)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var starts int
+	router := NewRouter(context.Background(), t.TempDir(), primary, []ServerConfig{{
+		Extensions: []string{".ts"},
+		Transport:  TransportTCP,
+		Address:    server.listener.Addr().String(),
+	}}, func(client *Client) {
+		starts++
+		close(started)
+		<-release
+		t.Cleanup(func() { client.Close() })
+	})
+
+	var wg sync.WaitGroup
+	clients := make([]*Client, 2)
+	for i := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client, err := router.ClientForFile("/src/a.ts")
+			assert.NoError(t, err)
+			clients[i] = client
+		}()
+	}
+
+	// Other callers aren't blocked while the server starts
+	<-started
+	done := make(chan []*Client)
+	go func() { done <- router.Clients() }()
+	select {
+	case clients := <-done:
+		assert.Equal(t, []*Client{primary}, clients)
+	case <-time.After(time.Second):
+		t.Fatal("Clients blocked while a server was starting")
+	}
+
+	close(release)
+	wg.Wait()
+	require.NotNil(t, clients[0])
+	assert.Same(t, clients[0], clients[1])
+	assert.Equal(t, 1, starts)
+	assert.Equal(t, []*Client{primary, clients[0]}, router.Clients())
+}
@@ -119,10 +119,19 @@ func HandleDiagnostics(client *Client, params json.RawMessage) {
 		return
 	}
 
-	// Save diagnostics in client
+	// Save diagnostics in client and wake up anyone waiting for them
 	client.diagnosticsMu.Lock()
 	client.diagnostics[diagParams.URI] = diagParams.Diagnostics
+	for _, ch := range client.diagnosticsWaiters[diagParams.URI] {
+		close(ch)
+	}
+	delete(client.diagnosticsWaiters, diagParams.URI)
+	handlers := client.diagnosticsHandlers
 	client.diagnosticsMu.Unlock()
 
 	lspLogger.Info("Received diagnostics for %s: %d items", diagParams.URI, len(diagParams.Diagnostics))
+
+	for _, handler := range handlers {
+		handler(diagParams.URI, diagParams.Diagnostics)
+	}
 }
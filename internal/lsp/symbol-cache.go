@@ -0,0 +1,86 @@
+package lsp
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// defaultSymbolCacheTTL is how long workspace/symbol results are reused
+const defaultSymbolCacheTTL = 30 * time.Second
+
+type symbolCacheEntry struct {
+	result  protocol.Or_Result_workspace_symbol
+	expires time.Time
+}
+
+// symbolCacheTTLFromEnv reads the cache lifetime from LSP_SYMBOL_CACHE_TTL, a Go
+// duration such as "10s". A value of 0 disables the cache.
+func symbolCacheTTLFromEnv() time.Duration {
+	value := os.Getenv("LSP_SYMBOL_CACHE_TTL")
+	if value == "" {
+		return defaultSymbolCacheTTL
+	}
+	if value == "0" {
+		return 0
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl < 0 {
+		lspLogger.Warn("Invalid LSP_SYMBOL_CACHE_TTL %q, using %s", value, defaultSymbolCacheTTL)
+		return defaultSymbolCacheTTL
+	}
+	return ttl
+}
+
+// CachedSymbol is like Symbol but reuses the result of an identical query made
+// within the cache TTL. Large indexes can take a long time to answer workspace/symbol.
+func (c *Client) CachedSymbol(ctx context.Context, params protocol.WorkspaceSymbolParams) (protocol.Or_Result_workspace_symbol, error) {
+	if c.symbolCacheTTL <= 0 {
+		return c.Symbol(ctx, params)
+	}
+
+	c.symbolCacheMu.Lock()
+	entry, ok := c.symbolCache[params.Query]
+	if ok && !time.Now().Before(entry.expires) {
+		delete(c.symbolCache, params.Query)
+		ok = false
+	}
+	c.symbolCacheMu.Unlock()
+	if ok {
+		lspLogger.Debug("Using cached workspace/symbol result for %q", params.Query)
+		return entry.result, nil
+	}
+
+	result, err := c.Symbol(ctx, params)
+	if err != nil {
+		return result, err
+	}
+
+	now := time.Now()
+	c.symbolCacheMu.Lock()
+	// Expired entries of other queries are dropped here, so the cache doesn't grow
+	// with every distinct query
+	for query, entry := range c.symbolCache {
+		if !now.Before(entry.expires) {
+			delete(c.symbolCache, query)
+		}
+	}
+	c.symbolCache[params.Query] = symbolCacheEntry{
+		result:  result,
+		expires: now.Add(c.symbolCacheTTL),
+	}
+	c.symbolCacheMu.Unlock()
+
+	return result, nil
+}
+
+// InvalidateSymbolCache drops all cached workspace/symbol results. It should be
+// called after files are modified, by the tools or, as the watcher reports, outside
+// them, so symbol locations aren't stale.
+func (c *Client) InvalidateSymbolCache() {
+	c.symbolCacheMu.Lock()
+	defer c.symbolCacheMu.Unlock()
+	c.symbolCache = make(map[string]symbolCacheEntry)
+}
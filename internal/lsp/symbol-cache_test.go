@@ -0,0 +1,75 @@
+package lsp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSymbolCacheTestClient(t *testing.T, ttl time.Duration) (*Client, *fakeServer) {
+	server := startFakeServer(t, func(conn int, method string) fakeServerAction {
+		return fakeReply
+	}, map[string]any{"workspace/symbol": []any{}})
+	client := newFakeServerClient(t, server)
+	client.symbolCacheTTL = ttl
+	return client, server
+}
+
+func TestCachedSymbol(t *testing.T) {
+	ctx := context.Background()
+	query := func(client *Client, q string) {
+		t.Helper()
+		_, err := client.CachedSymbol(ctx, protocol.WorkspaceSymbolParams{Query: q})
+		require.NoError(t, err)
+	}
+	symbolRequests := func(server *fakeServer) int {
+		_, requests := server.counts("workspace/symbol")
+		return requests
+	}
+
+	t.Run("Hit", func(t *testing.T) {
+		client, server := newSymbolCacheTestClient(t, time.Minute)
+		query(client, "Foo")
+		query(client, "Foo")
+		assert.Equal(t, 1, symbolRequests(server))
+
+		// Other queries are cached separately
+		query(client, "Bar")
+		assert.Equal(t, 2, symbolRequests(server))
+	})
+
+	t.Run("Expiry", func(t *testing.T) {
+		client, server := newSymbolCacheTestClient(t, 20*time.Millisecond)
+		query(client, "Foo")
+		query(client, "Bar")
+		time.Sleep(30 * time.Millisecond)
+
+		query(client, "Foo")
+		assert.Equal(t, 3, symbolRequests(server))
+
+		// The expired entry of Bar was evicted when Foo was cached again
+		client.symbolCacheMu.Lock()
+		assert.Len(t, client.symbolCache, 1)
+		assert.Contains(t, client.symbolCache, "Foo")
+		client.symbolCacheMu.Unlock()
+	})
+
+	t.Run("Invalidation", func(t *testing.T) {
+		client, server := newSymbolCacheTestClient(t, time.Minute)
+		query(client, "Foo")
+		client.InvalidateSymbolCache()
+		query(client, "Foo")
+		assert.Equal(t, 2, symbolRequests(server))
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		client, server := newSymbolCacheTestClient(t, 0)
+		query(client, "Foo")
+		query(client, "Foo")
+		assert.Equal(t, 2, symbolRequests(server))
+	})
+}
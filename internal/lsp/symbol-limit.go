@@ -0,0 +1,48 @@
+package lsp
+
+import (
+	"context"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// limitedSymbolParams are workspace/symbol params with clangd's limit extension,
+// which caps the number of results the server computes
+type limitedSymbolParams struct {
+	protocol.WorkspaceSymbolParams
+	Limit int `json:"limit,omitempty"`
+}
+
+// LimitedSymbol sends a workspace/symbol request for at most limit results. clangd
+// stops searching at the limit; results from servers that ignore it are truncated.
+// A limit of 0 or less means no limit.
+func (c *Client) LimitedSymbol(ctx context.Context, query string, limit int) (protocol.Or_Result_workspace_symbol, error) {
+	if limit <= 0 {
+		return c.Symbol(ctx, protocol.WorkspaceSymbolParams{Query: query})
+	}
+
+	var result protocol.Or_Result_workspace_symbol
+	params := limitedSymbolParams{
+		WorkspaceSymbolParams: protocol.WorkspaceSymbolParams{Query: query},
+		Limit:                 limit,
+	}
+	if err := c.Call(ctx, "workspace/symbol", params, &result); err != nil {
+		return result, err
+	}
+	return truncateSymbolResult(result, limit), nil
+}
+
+// truncateSymbolResult keeps the first limit symbols of a workspace/symbol result
+func truncateSymbolResult(result protocol.Or_Result_workspace_symbol, limit int) protocol.Or_Result_workspace_symbol {
+	switch v := result.Value.(type) {
+	case []protocol.WorkspaceSymbol:
+		if len(v) > limit {
+			result.Value = v[:limit]
+		}
+	case []protocol.SymbolInformation:
+		if len(v) > limit {
+			result.Value = v[:limit]
+		}
+	}
+	return result
+}
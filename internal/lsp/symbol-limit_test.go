@@ -0,0 +1,21 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncateSymbolResult(t *testing.T) {
+	symbols := []protocol.SymbolInformation{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+
+	truncated := truncateSymbolResult(protocol.Or_Result_workspace_symbol{Value: symbols}, 2)
+	assert.Equal(t, symbols[:2], truncated.Value)
+
+	untouched := truncateSymbolResult(protocol.Or_Result_workspace_symbol{Value: symbols}, 5)
+	assert.Equal(t, symbols, untouched.Value)
+
+	empty := truncateSymbolResult(protocol.Or_Result_workspace_symbol{}, 2)
+	assert.Nil(t, empty.Value)
+}
@@ -0,0 +1,56 @@
+package lsp
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Transports a server can be reached over, see ServerConfig.Transport
+const (
+	TransportStdio = "stdio"
+	TransportTCP   = "tcp"
+)
+
+// dialTimeout bounds connecting to a server over TCP
+const dialTimeout = 10 * time.Second
+
+// checkAddress returns an error unless address has the host:port form net.Dial
+// expects
+func checkAddress(address string) error {
+	if address == "" {
+		return fmt.Errorf("tcp transport requires an address such as localhost:2087")
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return fmt.Errorf("invalid address %q, expected host:port: %v", address, err)
+	}
+	return nil
+}
+
+// dial connects to a language server that is already running and listening on
+// c.address, such as one in a container, and starts reading its messages. It
+// replaces any previous connection. Nothing is launched, so there is no process.
+func (c *Client) dial() error {
+	conn, err := net.DialTimeout("tcp", c.address, dialTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to language server at %s: %w", c.address, err)
+	}
+	lspLogger.Info("Connected to language server at %s", c.address)
+
+	reader := bufio.NewReader(conn)
+	closed := make(chan struct{})
+	c.connMu.Lock()
+	c.Cmd = nil
+	c.stdin = conn
+	c.stdout = reader
+	c.stderr = nil
+	c.connClosed = closed
+	c.generation++
+	c.startedAt = time.Now()
+	c.connMu.Unlock()
+
+	go c.handleMessages(reader, conn, closed)
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTCPTransport(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	// A server that answers every request with the method it was sent
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for {
+			msg, err := ReadMessage(reader)
+			if err != nil {
+				return
+			}
+			result, _ := json.Marshal(msg.Method)
+			if err := WriteMessage(conn, &Message{JSONRPC: "2.0", ID: msg.ID, Result: result}); err != nil {
+				return
+			}
+		}
+	}()
+
+	client, err := NewClientFromConfig(ServerConfig{Transport: TransportTCP, Address: listener.Addr().String()})
+	require.NoError(t, err)
+	assert.Nil(t, client.Cmd)
+	assert.Equal(t, "tcp://"+listener.Addr().String(), client.Name())
+
+	var method string
+	require.NoError(t, client.Call(context.Background(), "workspace/symbol", nil, &method))
+	assert.Equal(t, "workspace/symbol", method)
+
+	assert.NoError(t, client.Close())
+}
+
+func TestTCPTransportNoServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	_, err = NewClientFromConfig(ServerConfig{Transport: TransportTCP, Address: address})
+	assert.ErrorContains(t, err, "failed to connect to language server at "+address)
+}
+
+func TestParseConfigTCP(t *testing.T) {
+	config, err := ParseConfig([]byte(`{"servers": [{"language": "python", "transport": "tcp", "address": "localhost:2087", "extensions": [".py"]}]}`))
+	require.NoError(t, err)
+	require.Len(t, config.Servers, 1)
+	assert.NoError(t, config.Servers[0].Check())
+	assert.Equal(t, "tcp://localhost:2087", config.Servers[0].Name())
+}
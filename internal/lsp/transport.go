@@ -4,11 +4,14 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/isaacphi/mcp-language-server/internal/logging"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
 // Create component-specific loggers
@@ -42,6 +45,14 @@ func WriteMessage(w io.Writer, msg *Message) error {
 	return nil
 }
 
+// writeMessage writes a message to the server, one writer at a time, as WriteMessage
+// writes the header and the body separately
+func (c *Client) writeMessage(w io.Writer, msg *Message) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return WriteMessage(w, msg)
+}
+
 // ReadMessage reads a single LSP message from the given reader
 func ReadMessage(r *bufio.Reader) (*Message, error) {
 	// Read headers
@@ -94,10 +105,13 @@ func ReadMessage(r *bufio.Reader) (*Message, error) {
 	return &msg, nil
 }
 
-// handleMessages reads and dispatches messages in a loop
-func (c *Client) handleMessages() {
+// handleMessages reads and dispatches messages of one connection in a loop. closed
+// is closed when the connection ends.
+func (c *Client) handleMessages(stdout *bufio.Reader, stdin io.Writer, closed chan struct{}) {
+	defer close(closed)
+
 	for {
-		msg, err := ReadMessage(c.stdout)
+		msg, err := ReadMessage(stdout)
 		if err != nil {
 			// Check if this is due to normal shutdown (EOF when closing connection)
 			if strings.Contains(err.Error(), "EOF") {
@@ -107,6 +121,7 @@ func (c *Client) handleMessages() {
 			}
 			return
 		}
+		c.lastMessageAt.Store(time.Now().UnixNano())
 
 		// Handle server->client request (has both Method and ID)
 		if msg.Method != "" && msg.ID != nil && msg.ID.Value != nil {
@@ -150,7 +165,7 @@ func (c *Client) handleMessages() {
 			}
 
 			// Send response back to server
-			if err := WriteMessage(c.stdin, response); err != nil {
+			if err := c.writeMessage(stdin, response); err != nil {
 				lspLogger.Error("Error sending response to server: %v", err)
 			}
 
@@ -174,25 +189,54 @@ func (c *Client) handleMessages() {
 
 		// Handle response to our request (has ID but no Method)
 		if msg.ID != nil && msg.ID.Value != nil && msg.Method == "" {
-			// Convert ID to string for map lookup
-			idStr := msg.ID.String()
-			c.handlersMu.RLock()
-			ch, ok := c.handlers[idStr]
-			c.handlersMu.RUnlock()
-
-			if ok {
+			if ch, ok := c.takeHandler(msg.ID.String()); ok {
 				lspLogger.Debug("Sending response for ID %v to handler", msg.ID)
 				ch <- msg
 				close(ch)
 			} else {
-				lspLogger.Debug("No handler for response ID: %v", msg.ID)
+				// The request timed out or was cancelled, or this is a duplicate
+				lspLogger.Debug("Discarding response to ID %v, no request is waiting for it", msg.ID)
 			}
 		}
 	}
 }
 
-// Call makes a request and waits for the response
+// takeHandler removes and returns the channel waiting for the response with the
+// given ID. Removing it as the response is delivered means each channel gets at
+// most one message, and a response arriving after its request gave up finds nothing.
+func (c *Client) takeHandler(id string) (chan *Message, bool) {
+	c.handlersMu.Lock()
+	defer c.handlersMu.Unlock()
+	ch, ok := c.handlers[id]
+	if ok {
+		delete(c.handlers, id)
+	}
+	return ch, ok
+}
+
+// Call makes a request and waits for the response. If the server process has
+// exited, or a request timed out without the server sending anything since, the
+// server is restarted. Read-only requests are then retried once, others fail since
+// they may already have taken effect.
 func (c *Client) Call(ctx context.Context, method string, params any, result any) error {
+	generation := c.connGeneration()
+	err := c.call(ctx, method, params, result)
+	if !errors.Is(err, ErrServerExited) && !errors.Is(err, ErrServerUnresponsive) || !c.shouldRestart(method) {
+		return err
+	}
+
+	lspLogger.Warn("Restarting language server after %s failed: %v", method, err)
+	if restartErr := c.restartAfterCrash(ctx, generation); restartErr != nil {
+		return fmt.Errorf("%w (restart failed: %v)", err, restartErr)
+	}
+	if !retryableMethods[method] {
+		return fmt.Errorf("%w, the language server was restarted but %s was not retried since it may have taken effect", err, method)
+	}
+	return c.call(ctx, method, params, result)
+}
+
+// call makes a single request on the current connection
+func (c *Client) call(ctx context.Context, method string, params any, result any) error {
 	id := c.nextID.Add(1)
 
 	lspLogger.Debug("Making call: method=%s id=%v", method, id)
@@ -210,27 +254,59 @@ func (c *Client) Call(ctx context.Context, method string, params any, result any
 	c.handlers[idStr] = ch
 	c.handlersMu.Unlock()
 
-	defer func() {
-		c.handlersMu.Lock()
-		delete(c.handlers, idStr)
-		c.handlersMu.Unlock()
-	}()
+	// Stop waiting however the call ends, so that a late response is discarded
+	defer c.takeHandler(idStr)
+
+	c.connMu.RLock()
+	stdin, closed := c.stdin, c.connClosed
+	c.connMu.RUnlock()
 
 	// Send request
-	if err := WriteMessage(c.stdin, msg); err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+	sentAt := time.Now()
+	if err := c.writeMessage(stdin, msg); err != nil {
+		return fmt.Errorf("%w: failed to send request: %v", ErrServerExited, err)
 	}
 
 	lspLogger.Debug("Waiting for response to request ID: %v", msg.ID)
 
+	var timeout <-chan time.Time
+	if c.requestTimeout > 0 && hasRequestTimeout(method) {
+		timer := time.NewTimer(c.requestTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
 	// Wait for response
-	resp := <-ch
+	var resp *Message
+	select {
+	case resp = <-ch:
+	case <-closed:
+		// The response may have arrived just before the connection closed
+		select {
+		case resp = <-ch:
+		default:
+			return fmt.Errorf("%w: no response to %s", ErrServerExited, method)
+		}
+	case <-timeout:
+		c.cancelRequest(id)
+		lspLogger.Error("Request %s (ID %v) timed out after %s", method, id, c.requestTimeout)
+		err := requestTimeoutError(method, params, c.requestTimeout)
+		// A server that is busy still answers other requests or reports progress, one
+		// that sent nothing at all is taken to be hung
+		if c.lastMessageAt.Load() < sentAt.UnixNano() {
+			return fmt.Errorf("%w: %w", ErrServerUnresponsive, err)
+		}
+		return err
+	case <-ctx.Done():
+		c.cancelRequest(id)
+		return ctx.Err()
+	}
 
 	lspLogger.Debug("Received response for request ID: %v", msg.ID)
 
 	if resp.Error != nil {
 		lspLogger.Error("Request failed: %s (code: %d)", resp.Error.Message, resp.Error.Code)
-		return fmt.Errorf("request failed: %s (code: %d)", resp.Error.Message, resp.Error.Code)
+		return fmt.Errorf("request failed: %w", resp.Error)
 	}
 
 	if result != nil {
@@ -249,6 +325,13 @@ func (c *Client) Call(ctx context.Context, method string, params any, result any
 	return nil
 }
 
+// cancelRequest asks the server to stop working on a request we no longer wait for
+func (c *Client) cancelRequest(id int32) {
+	if err := c.Notify(context.Background(), "$/cancelRequest", protocol.CancelParams{ID: id}); err != nil {
+		lspLogger.Debug("Failed to cancel request %d: %v", id, err)
+	}
+}
+
 // Notify sends a notification (a request without an ID that doesn't expect a response)
 func (c *Client) Notify(ctx context.Context, method string, params any) error {
 	lspLogger.Debug("Sending notification: method=%s", method)
@@ -258,7 +341,11 @@ func (c *Client) Notify(ctx context.Context, method string, params any) error {
 		return fmt.Errorf("failed to create notification: %w", err)
 	}
 
-	if err := WriteMessage(c.stdin, msg); err != nil {
+	c.connMu.RLock()
+	stdin := c.stdin
+	c.connMu.RUnlock()
+
+	if err := c.writeMessage(stdin, msg); err != nil {
 		return fmt.Errorf("failed to send notification: %w", err)
 	}
 
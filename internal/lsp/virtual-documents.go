@@ -0,0 +1,46 @@
+package lsp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// ErrNoDocumentContent is returned by ReadDocument for URI schemes without a known
+// content request
+var ErrNoDocumentContent = errors.New("no way to read document content")
+
+// ReadDocument returns the content of a document. file: URIs are read from disk.
+// Other schemes name documents that only the server can produce, such as jdtls's
+// jdt:// class files or Deno's deno: modules, and are fetched with the server's
+// content request.
+func (c *Client) ReadDocument(ctx context.Context, uri protocol.DocumentUri) ([]byte, error) {
+	scheme, _, _ := strings.Cut(string(uri), ":")
+	switch scheme {
+	case "file":
+		parsed, err := protocol.ParseDocumentUri(string(uri))
+		if err != nil {
+			return nil, fmt.Errorf("invalid file URI %s: %w", uri, err)
+		}
+		return os.ReadFile(parsed.Path())
+	case "jdt":
+		var content string
+		if err := c.Call(ctx, "java/classFileContents", protocol.TextDocumentIdentifier{URI: uri}, &content); err != nil {
+			return nil, fmt.Errorf("failed to get class file contents: %w", err)
+		}
+		return []byte(content), nil
+	case "deno":
+		var content string
+		params := map[string]any{"textDocument": protocol.TextDocumentIdentifier{URI: uri}}
+		if err := c.Call(ctx, "deno/virtualTextDocument", params, &content); err != nil {
+			return nil, fmt.Errorf("failed to get virtual text document: %w", err)
+		}
+		return []byte(content), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrNoDocumentContent, uri)
+	}
+}
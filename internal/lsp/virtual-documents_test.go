@@ -0,0 +1,27 @@
+package lsp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadDocument(t *testing.T) {
+	client := &Client{}
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "main go.go")
+	require.NoError(t, os.WriteFile(path, []byte("package main\n"), 0644))
+
+	content, err := client.ReadDocument(ctx, protocol.URIFromPath(path))
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", string(content))
+
+	_, err = client.ReadDocument(ctx, "zip:/lib/rt.jar!/java/lang/String.class")
+	assert.ErrorIs(t, err, ErrNoDocumentContent)
+}
@@ -0,0 +1,114 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// WorkspaceFolders returns the roots the server works on: the workspace it was
+// initialized with, followed by any additional folders
+func (c *Client) WorkspaceFolders() []string {
+	c.foldersMu.Lock()
+	defer c.foldersMu.Unlock()
+
+	var folders []string
+	if c.workspaceDir != "" {
+		folders = append(folders, c.workspaceDir)
+	}
+	return append(folders, c.folders...)
+}
+
+// additionalFolders returns the folders added on top of the workspace
+func (c *Client) additionalFolders() []string {
+	c.foldersMu.Lock()
+	defer c.foldersMu.Unlock()
+	return slices.Clone(c.folders)
+}
+
+// ChangeWorkspaceFolders adds and removes workspace folders, given as absolute
+// paths. Before the server is initialized the folders are only recorded, to be
+// sent in the initialize request. Afterwards the server is told about the change
+// with workspace/didChangeWorkspaceFolders, which fails if it does not support
+// workspace folders. The workspace itself cannot be removed.
+func (c *Client) ChangeWorkspaceFolders(ctx context.Context, added, removed []string) error {
+	c.foldersMu.Lock()
+	defer c.foldersMu.Unlock()
+
+	workspaceDir := c.workspaceDir
+	folders := slices.Clone(c.folders)
+
+	var event protocol.WorkspaceFoldersChangeEvent
+	for _, dir := range removed {
+		if dir == workspaceDir {
+			return fmt.Errorf("cannot remove the workspace %s", dir)
+		}
+		if i := slices.Index(folders, dir); i >= 0 {
+			folders = slices.Delete(folders, i, i+1)
+			event.Removed = append(event.Removed, workspaceFolder(dir))
+		}
+	}
+	for _, dir := range added {
+		if dir == workspaceDir || slices.Contains(folders, dir) {
+			continue
+		}
+		folders = append(folders, dir)
+		event.Added = append(event.Added, workspaceFolder(dir))
+	}
+
+	if len(event.Added) == 0 && len(event.Removed) == 0 {
+		return nil
+	}
+
+	// Not initialized yet, the folders go in the initialize request
+	if workspaceDir != "" {
+		if !supportsWorkspaceFolders(c.capabilities) {
+			return fmt.Errorf("the language server does not support workspace folders")
+		}
+		if err := c.DidChangeWorkspaceFolders(ctx, protocol.DidChangeWorkspaceFoldersParams{Event: event}); err != nil {
+			return fmt.Errorf("failed to notify workspace folder change: %w", err)
+		}
+		c.InvalidateSymbolCache()
+	}
+
+	c.folders = folders
+	lspLogger.Info("Workspace folders: %d added, %d removed", len(event.Added), len(event.Removed))
+	return nil
+}
+
+// initialWorkspaceFolders returns the folders sent in the initialize request
+func (c *Client) initialWorkspaceFolders(workspaceDir string) []protocol.WorkspaceFolder {
+	folders := []protocol.WorkspaceFolder{workspaceFolder(workspaceDir)}
+	for _, dir := range c.additionalFolders() {
+		if dir != workspaceDir {
+			folders = append(folders, workspaceFolder(dir))
+		}
+	}
+	return folders
+}
+
+// handleWorkspaceFolders answers workspace/workspaceFolders requests
+func (c *Client) handleWorkspaceFolders() []protocol.WorkspaceFolder {
+	var folders []protocol.WorkspaceFolder
+	for _, dir := range c.WorkspaceFolders() {
+		folders = append(folders, workspaceFolder(dir))
+	}
+	return folders
+}
+
+func workspaceFolder(dir string) protocol.WorkspaceFolder {
+	return protocol.WorkspaceFolder{
+		URI:  protocol.URI("file://" + dir),
+		Name: dir,
+	}
+}
+
+// supportsWorkspaceFolders reports whether a server accepts changes to its
+// workspace folders
+func supportsWorkspaceFolders(capabilities protocol.ServerCapabilities) bool {
+	return capabilities.Workspace != nil &&
+		capabilities.Workspace.WorkspaceFolders != nil &&
+		capabilities.Workspace.WorkspaceFolders.Supported
+}
@@ -0,0 +1,38 @@
+package lsp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangeWorkspaceFoldersBeforeInitialize(t *testing.T) {
+	client := &Client{}
+	ctx := context.Background()
+
+	require.NoError(t, client.ChangeWorkspaceFolders(ctx, []string{"/deps/a", "/deps/b", "/deps/a"}, nil))
+	require.NoError(t, client.ChangeWorkspaceFolders(ctx, nil, []string{"/deps/b", "/deps/missing"}))
+	assert.Equal(t, []string{"/deps/a"}, client.WorkspaceFolders())
+
+	folders := client.initialWorkspaceFolders("/repo")
+	assert.Equal(t, []protocol.WorkspaceFolder{
+		{URI: "file:///repo", Name: "/repo"},
+		{URI: "file:///deps/a", Name: "/deps/a"},
+	}, folders)
+}
+
+func TestChangeWorkspaceFoldersAfterInitialize(t *testing.T) {
+	client := &Client{workspaceDir: "/repo", folders: []string{"/deps/a"}}
+	ctx := context.Background()
+
+	assert.Equal(t, []string{"/repo", "/deps/a"}, client.WorkspaceFolders())
+	assert.ErrorContains(t, client.ChangeWorkspaceFolders(ctx, nil, []string{"/repo"}), "cannot remove the workspace")
+	assert.ErrorContains(t, client.ChangeWorkspaceFolders(ctx, []string{"/deps/b"}, nil), "does not support workspace folders")
+
+	// Nothing changes, so the server is not asked
+	assert.NoError(t, client.ChangeWorkspaceFolders(ctx, []string{"/repo", "/deps/a"}, []string{"/deps/c"}))
+	assert.Equal(t, []string{"/repo", "/deps/a"}, client.WorkspaceFolders())
+}
@@ -21,6 +21,14 @@ func (ws *WorkspaceSymbol) GetLocation() Location {
 }
 func (ws *WorkspaceSymbol) isWorkspaceSymbol() {}
 
+// HasRange reports whether the symbol's location includes a range. Servers that
+// support workspaceSymbol/resolve may return only a URI, which decodes as a
+// Location with an empty range.
+func (ws *WorkspaceSymbol) HasRange() bool {
+	loc, ok := ws.Location.Value.(Location)
+	return ok && loc.Range != (Range{})
+}
+
 func (si *SymbolInformation) GetName() string       { return si.Name }
 func (si *SymbolInformation) GetLocation() Location { return si.Location }
 func (si *SymbolInformation) isWorkspaceSymbol()    {}
@@ -115,3 +123,65 @@ func (e Or_TextDocumentEdit_edits_Elem) AsTextEdit() (TextEdit, error) {
 		return TextEdit{}, fmt.Errorf("unknown text edit type: %T", e.Value)
 	}
 }
+
+// locationsFrom flattens the Location, []Location and []LocationLink forms that
+// definition-like requests may return into a slice of Locations
+func locationsFrom(value any) ([]Location, error) {
+	switch v := value.(type) {
+	case nil:
+		return make([]Location, 0), nil
+	case Or_Definition:
+		return locationsFrom(v.Value)
+	case Or_Declaration:
+		return locationsFrom(v.Value)
+	case Location:
+		return []Location{v}, nil
+	case []Location:
+		return v, nil
+	case []LocationLink:
+		locations := make([]Location, len(v))
+		for i, link := range v {
+			locations[i] = Location{
+				URI:   link.TargetURI,
+				Range: link.TargetSelectionRange,
+			}
+		}
+		return locations, nil
+	default:
+		return nil, fmt.Errorf("unknown location type: %T", value)
+	}
+}
+
+// Locations converts the Value to a slice of Location
+func (r Or_Result_textDocument_typeDefinition) Locations() ([]Location, error) {
+	return locationsFrom(r.Value)
+}
+
+// Locations converts the Value to a slice of Location
+func (r Or_Result_textDocument_implementation) Locations() ([]Location, error) {
+	return locationsFrom(r.Value)
+}
+
+// Items converts the Value to a slice of CompletionItem
+func (r Or_Result_textDocument_completion) Items() ([]CompletionItem, error) {
+	switch v := r.Value.(type) {
+	case nil:
+		return nil, nil
+	case CompletionList:
+		return v.Items, nil
+	case []CompletionItem:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("unknown completion type: %T", r.Value)
+	}
+}
+
+// Locations converts the Value to a slice of Location
+func (r Or_Result_textDocument_declaration) Locations() ([]Location, error) {
+	return locationsFrom(r.Value)
+}
+
+// Locations converts the Value to a slice of Location
+func (r Or_Result_textDocument_definition) Locations() ([]Location, error) {
+	return locationsFrom(r.Value)
+}
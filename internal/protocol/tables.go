@@ -1,5 +1,7 @@
 package protocol
 
+import "fmt"
+
 var TableKindMap = map[SymbolKind]string{
 	File:          "File",
 	Module:        "Module",
@@ -28,3 +30,40 @@ var TableKindMap = map[SymbolKind]string{
 	Operator:      "Operator",
 	TypeParameter: "TypeParameter",
 }
+
+// SymbolKindName returns the name of a symbol kind, or "Unknown(n)" for kinds
+// missing from TableKindMap, such as ones added by newer protocol versions
+func SymbolKindName(kind SymbolKind) string {
+	if name, ok := TableKindMap[kind]; ok {
+		return name
+	}
+	return fmt.Sprintf("Unknown(%d)", kind)
+}
+
+var CompletionKindMap = map[CompletionItemKind]string{
+	TextCompletion:          "Text",
+	MethodCompletion:        "Method",
+	FunctionCompletion:      "Function",
+	ConstructorCompletion:   "Constructor",
+	FieldCompletion:         "Field",
+	VariableCompletion:      "Variable",
+	ClassCompletion:         "Class",
+	InterfaceCompletion:     "Interface",
+	ModuleCompletion:        "Module",
+	PropertyCompletion:      "Property",
+	UnitCompletion:          "Unit",
+	ValueCompletion:         "Value",
+	EnumCompletion:          "Enum",
+	KeywordCompletion:       "Keyword",
+	SnippetCompletion:       "Snippet",
+	ColorCompletion:         "Color",
+	FileCompletion:          "File",
+	ReferenceCompletion:     "Reference",
+	FolderCompletion:        "Folder",
+	EnumMemberCompletion:    "EnumMember",
+	ConstantCompletion:      "Constant",
+	StructCompletion:        "Struct",
+	EventCompletion:         "Event",
+	OperatorCompletion:      "Operator",
+	TypeParameterCompletion: "TypeParameter",
+}
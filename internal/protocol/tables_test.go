@@ -0,0 +1,44 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTableKindMapCoversSymbolKinds(t *testing.T) {
+	// Symbol kinds are numbered from File to TypeParameter without gaps
+	for kind := File; kind <= TypeParameter; kind++ {
+		name, ok := TableKindMap[kind]
+		assert.True(t, ok, "symbol kind %d has no table entry", kind)
+		assert.NotEmpty(t, name, "symbol kind %d has an empty name", kind)
+	}
+	assert.Len(t, TableKindMap, int(TypeParameter))
+
+	names := make(map[string]SymbolKind, len(TableKindMap))
+	for kind, name := range TableKindMap {
+		if other, ok := names[name]; ok {
+			t.Errorf("symbol kinds %d and %d are both named %q", other, kind, name)
+		}
+		names[name] = kind
+	}
+}
+
+func TestSymbolKindName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		kind     SymbolKind
+		expected string
+	}{
+		{"Known kind", Function, "Function"},
+		{"Last kind", TypeParameter, "TypeParameter"},
+		{"Zero kind", 0, "Unknown(0)"},
+		{"Newer kind", 27, "Unknown(27)"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, SymbolKindName(tc.kind))
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// maxHierarchyDepth caps how many levels a hierarchy tree is expanded
+const maxHierarchyDepth = 5
+
+// CallHierarchy shows the callers ("incoming") or callees ("outgoing") of a symbol as a
+// tree, following the hierarchy up to depth levels
+func CallHierarchy(ctx context.Context, client *lsp.Client, symbolName string, direction string, depth int) (string, error) {
+	symbolName, err := NormalizeSymbolName(symbolName)
+	if err != nil {
+		return "", err
+	}
+
+	if direction != "incoming" && direction != "outgoing" {
+		return "", fmt.Errorf("direction must be \"incoming\" or \"outgoing\", got %q", direction)
+	}
+	if depth < 1 {
+		depth = 1
+	}
+	if depth > maxHierarchyDepth {
+		depth = maxHierarchyDepth
+	}
+
+	results, err := findSymbols(ctx, client, symbolName)
+	if err != nil {
+		return "", err
+	}
+
+	var trees []string
+	for _, symbol := range results {
+		loc := symbol.GetLocation()
+
+		err := openURI(ctx, client, loc.URI)
+		if err != nil {
+			toolsLogger.Error("Error opening file: %v", err)
+			continue
+		}
+		defer closeURI(ctx, client, loc.URI)
+
+		items, err := client.PrepareCallHierarchy(ctx, protocol.CallHierarchyPrepareParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{
+					URI: loc.URI,
+				},
+				Position: loc.Range.Start,
+			},
+		})
+		if err != nil {
+			if isMethodNotSupported(err) {
+				return "The language server does not support call hierarchy.", nil
+			}
+			return "", fmt.Errorf("failed to prepare call hierarchy: %v", err)
+		}
+
+		for _, item := range items {
+			var output strings.Builder
+			output.WriteString(fmt.Sprintf("---\n\n%s calls for %s\n\n", strings.ToUpper(direction[:1])+direction[1:], formatCallHierarchyItem(item)))
+			visited := map[string]bool{callHierarchyItemKey(item): true}
+			writeCallHierarchy(ctx, client, &output, item, direction, 1, depth, visited)
+			trees = append(trees, output.String())
+		}
+	}
+
+	if len(trees) == 0 {
+		return fmt.Sprintf("No call hierarchy found for symbol: %s", symbolName), nil
+	}
+
+	return strings.Join(trees, "\n"), nil
+}
+
+// writeCallHierarchy writes the calls of item at the given level and recurses until
+// maxDepth. Items already on the visited set are printed but not expanded again so
+// that recursive functions terminate.
+func writeCallHierarchy(ctx context.Context, client *lsp.Client, output *strings.Builder, item protocol.CallHierarchyItem, direction string, level, maxDepth int, visited map[string]bool) {
+	type call struct {
+		item       protocol.CallHierarchyItem
+		fromRanges []protocol.Range
+	}
+
+	var calls []call
+	if direction == "incoming" {
+		incoming, err := client.IncomingCalls(ctx, protocol.CallHierarchyIncomingCallsParams{Item: item})
+		if err != nil {
+			toolsLogger.Error("Error getting incoming calls: %v", err)
+			return
+		}
+		for _, c := range incoming {
+			calls = append(calls, call{item: c.From, fromRanges: c.FromRanges})
+		}
+	} else {
+		outgoing, err := client.OutgoingCalls(ctx, protocol.CallHierarchyOutgoingCallsParams{Item: item})
+		if err != nil {
+			toolsLogger.Error("Error getting outgoing calls: %v", err)
+			return
+		}
+		for _, c := range outgoing {
+			calls = append(calls, call{item: c.To, fromRanges: c.FromRanges})
+		}
+	}
+
+	indent := strings.Repeat("  ", level)
+	if len(calls) == 0 && level == 1 {
+		output.WriteString(indent + "(none)\n")
+		return
+	}
+
+	for _, c := range calls {
+		var sites []string
+		for _, r := range c.fromRanges {
+			sites = append(sites, fmt.Sprintf("L%d:C%d", r.Start.Line+1, r.Start.Character+1))
+		}
+		line := indent + formatCallHierarchyItem(c.item)
+		if len(sites) > 0 {
+			line += " at " + strings.Join(sites, ", ")
+		}
+
+		key := callHierarchyItemKey(c.item)
+		if visited[key] {
+			output.WriteString(line + " (already shown)\n")
+			continue
+		}
+		output.WriteString(line + "\n")
+
+		if level < maxDepth {
+			visited[key] = true
+			writeCallHierarchy(ctx, client, output, c.item, direction, level+1, maxDepth, visited)
+		}
+	}
+}
+
+func formatCallHierarchyItem(item protocol.CallHierarchyItem) string {
+	return formatHierarchyItem(item.Name, item.Kind, item.URI, item.SelectionRange)
+}
+
+func callHierarchyItemKey(item protocol.CallHierarchyItem) string {
+	return hierarchyItemKey(item.URI, item.SelectionRange)
+}
+
+// formatHierarchyItem renders a call or type hierarchy entry as "name (kind) path:line"
+func formatHierarchyItem(name string, kind protocol.SymbolKind, uri protocol.DocumentUri, selectionRange protocol.Range) string {
+	return fmt.Sprintf("%s (%s) %s:L%d",
+		name,
+		protocol.SymbolKindName(kind),
+		uriToPath(uri),
+		selectionRange.Start.Line+1,
+	)
+}
+
+// hierarchyItemKey identifies a hierarchy entry by where its name is declared
+func hierarchyItemKey(uri protocol.DocumentUri, selectionRange protocol.Range) string {
+	return fmt.Sprintf("%s:%d:%d", uri, selectionRange.Start.Line, selectionRange.Start.Character)
+}
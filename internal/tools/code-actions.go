@@ -0,0 +1,177 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// GetCodeActions lists the code actions (quick fixes, refactorings, etc.) available at
+// a position. Actions are numbered so that one can be passed to ApplyCodeAction.
+func GetCodeActions(ctx context.Context, client *lsp.Client, filePath string, line, character int) (string, error) {
+	actions, err := fetchCodeActions(ctx, client, filePath, line, character)
+	if err != nil {
+		return "", err
+	}
+
+	if len(actions) == 0 {
+		return fmt.Sprintf("No code actions available at %s L%d:C%d", filePath, line, character), nil
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Code actions at %s L%d:C%d:\n\n", filePath, line, character))
+	for i, action := range actions {
+		switch v := action.Value.(type) {
+		case protocol.CodeAction:
+			output.WriteString(fmt.Sprintf("[%d] %s", i+1, v.Title))
+			if v.Kind != "" {
+				output.WriteString(fmt.Sprintf(" (%s)", v.Kind))
+			}
+			if v.IsPreferred {
+				output.WriteString(" [preferred]")
+			}
+			if v.Disabled != nil {
+				output.WriteString(fmt.Sprintf(" [disabled: %s]", v.Disabled.Reason))
+			}
+			output.WriteString("\n")
+		case protocol.Command:
+			output.WriteString(fmt.Sprintf("[%d] %s (command)\n", i+1, v.Title))
+		}
+	}
+
+	return output.String(), nil
+}
+
+// ApplyCodeAction re-fetches the code actions at a position and applies the one at
+// actionIndex (1-based, as listed by GetCodeActions). Edits are written to disk and
+// commands are executed on the server.
+func ApplyCodeAction(ctx context.Context, client *lsp.Client, filePath string, line, character, actionIndex int) (string, error) {
+	actions, err := fetchCodeActions(ctx, client, filePath, line, character)
+	if err != nil {
+		return "", err
+	}
+
+	if len(actions) == 0 {
+		return "", fmt.Errorf("no code actions available at L%d:C%d", line, character)
+	}
+
+	if actionIndex < 1 || actionIndex > len(actions) {
+		return "", fmt.Errorf("invalid code action index: %d. Available range: 1-%d", actionIndex, len(actions))
+	}
+
+	var command *protocol.Command
+	var output strings.Builder
+
+	switch v := actions[actionIndex-1].Value.(type) {
+	case protocol.Command:
+		command = &v
+	case protocol.CodeAction:
+		if v.Disabled != nil {
+			return "", fmt.Errorf("code action %q is disabled: %s", v.Title, v.Disabled.Reason)
+		}
+
+		// Servers may compute the edit lazily and only return it from codeAction/resolve
+		if v.Edit == nil && v.Data != nil {
+			resolved, err := client.ResolveCodeAction(ctx, v)
+			if err != nil {
+				return "", fmt.Errorf("failed to resolve code action: %v", err)
+			}
+			v = resolved
+		}
+
+		if v.Edit == nil && v.Command == nil {
+			return "", fmt.Errorf("code action %q has no edit or command", v.Title)
+		}
+
+		output.WriteString(fmt.Sprintf("Applied code action: %s\n", v.Title))
+
+		if v.Edit != nil {
+			editsByFile := countEditsByFile(*v.Edit)
+			if err := applyWorkspaceEdit(ctx, client, *v.Edit); err != nil {
+				return "", fmt.Errorf("failed to apply changes: %v", err)
+			}
+
+			uris := make([]string, 0, len(editsByFile))
+			for uri := range editsByFile {
+				uris = append(uris, uri)
+			}
+			sort.Strings(uris)
+
+			for _, uri := range uris {
+				output.WriteString(fmt.Sprintf("---\n\n%s\nEdits in File: %d\n",
+					uriToPath(protocol.DocumentUri(uri)),
+					editsByFile[uri],
+				))
+			}
+		}
+
+		command = v.Command
+	default:
+		return "", fmt.Errorf("unexpected code action type: %T", v)
+	}
+
+	if command != nil {
+		// Edits produced by the command are sent back as workspace/applyEdit requests
+		_, err := client.ExecuteCommand(ctx, protocol.ExecuteCommandParams{
+			Command:   command.Command,
+			Arguments: command.Arguments,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to execute command: %v", err)
+		}
+		output.WriteString(fmt.Sprintf("Executed command: %s\n", command.Title))
+	}
+
+	// Files may have changed through the edit or the command
+	client.InvalidateSymbolCache()
+
+	return output.String(), nil
+}
+
+// fetchCodeActions requests the code actions at a 1-indexed position, passing along
+// any diagnostics on that line so servers can offer matching quick fixes
+func fetchCodeActions(ctx context.Context, client *lsp.Client, filePath string, line, character int) ([]protocol.Or_Result_textDocument_codeAction_Item0_Elem, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file: %v", err)
+	}
+	defer client.CloseFile(ctx, filePath)
+
+	uri := protocol.DocumentUri("file://" + filePath)
+	position := protocol.Position{
+		Line:      uint32(line - 1),
+		Character: uint32(character - 1),
+	}
+
+	var diagnostics []protocol.Diagnostic
+	for _, diag := range client.GetFileDiagnostics(uri) {
+		if diag.Range.Start.Line <= position.Line && position.Line <= diag.Range.End.Line {
+			diagnostics = append(diagnostics, diag)
+		}
+	}
+	if diagnostics == nil {
+		diagnostics = []protocol.Diagnostic{}
+	}
+
+	actions, err := client.CodeAction(ctx, protocol.CodeActionParams{
+		TextDocument: protocol.TextDocumentIdentifier{
+			URI: uri,
+		},
+		Range: protocol.Range{
+			Start: position,
+			End:   position,
+		},
+		Context: protocol.CodeActionContext{
+			Diagnostics: diagnostics,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get code actions: %v", err)
+	}
+
+	return actions, nil
+}
@@ -0,0 +1,155 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// DefaultMaxCompletionResults limits how many completion items are listed when the
+// caller does not ask for a specific number
+const DefaultMaxCompletionResults = 25
+
+// Complete lists the completion items the language server offers at a position.
+// Snippet items are skipped and the rest are ordered by the server's sortText. The
+// first resolve items shown are sent to completionItem/resolve, one request each, so
+// that the documentation and extra edits servers leave out of the list are included.
+func Complete(ctx context.Context, client *lsp.Client, filePath string, line, character int, maxResults int, resolve int) (string, error) {
+	if maxResults <= 0 {
+		maxResults = DefaultMaxCompletionResults
+	}
+
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+	defer client.CloseFile(ctx, filePath)
+
+	// Convert 1-indexed line/column to 0-indexed for LSP protocol
+	result, err := client.Completion(ctx, protocol.CompletionParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{
+				URI: protocol.DocumentUri("file://" + filePath),
+			},
+			Position: protocol.Position{
+				Line:      uint32(line - 1),
+				Character: uint32(character - 1),
+			},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get completions: %v", err)
+	}
+
+	items, err := result.Items()
+	if err != nil {
+		return "", fmt.Errorf("failed to parse completions: %v", err)
+	}
+
+	items = sortCompletionItems(items)
+	if len(items) == 0 {
+		return fmt.Sprintf("No completions available at %s L%d:C%d", filePath, line, character), nil
+	}
+
+	total := len(items)
+	shown := min(total, maxResults)
+	items = items[:shown]
+
+	// Documentation is shown for the resolved items, or for as many items when the
+	// server can't resolve them
+	detailed := min(max(resolve, 0), shown)
+	note := ""
+	if detailed > 0 {
+		if provider := client.Capabilities().CompletionProvider; provider != nil && provider.ResolveProvider {
+			resolveCompletionItems(ctx, client, items[:detailed])
+		} else {
+			note = "\nThe language server does not resolve completion items, only documentation sent with the list is shown.\n"
+		}
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Completions at %s L%d:C%d (showing %d of %d):\n\n", filePath, line, character, shown, total))
+	for i, item := range items {
+		output.WriteString(formatCompletionItem(item) + "\n")
+		if i < detailed {
+			output.WriteString(formatCompletionDetails(item))
+		}
+	}
+	output.WriteString(note)
+
+	return output.String(), nil
+}
+
+// resolveCompletionItems replaces each item with its completionItem/resolve result.
+// Items that fail to resolve are kept as they are.
+func resolveCompletionItems(ctx context.Context, client *lsp.Client, items []protocol.CompletionItem) {
+	for i, item := range items {
+		resolved, err := client.ResolveCompletionItem(ctx, item)
+		if err != nil {
+			toolsLogger.Warn("Failed to resolve completion item %s: %v", item.Label, err)
+			continue
+		}
+		items[i] = resolved
+	}
+}
+
+// sortCompletionItems drops snippet items and orders the rest by sortText, falling
+// back to the label for items without one
+func sortCompletionItems(items []protocol.CompletionItem) []protocol.CompletionItem {
+	filtered := make([]protocol.CompletionItem, 0, len(items))
+	for _, item := range items {
+		if item.Kind == protocol.SnippetCompletion {
+			continue
+		}
+		filtered = append(filtered, item)
+	}
+
+	sortKey := func(item protocol.CompletionItem) string {
+		if item.SortText != "" {
+			return item.SortText
+		}
+		return item.Label
+	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return sortKey(filtered[i]) < sortKey(filtered[j])
+	})
+
+	return filtered
+}
+
+func formatCompletionItem(item protocol.CompletionItem) string {
+	kind := protocol.CompletionKindMap[item.Kind]
+	if kind == "" {
+		kind = "Unknown"
+	}
+
+	line := fmt.Sprintf("%s (%s)", item.Label, kind)
+	if item.Detail != "" {
+		line += ": " + item.Detail
+	}
+	if item.Deprecated {
+		line += " [deprecated]"
+	}
+	return line
+}
+
+// formatCompletionDetails returns the documentation of an item and the edits it
+// makes beyond inserting itself, such as adding an import, indented under it
+func formatCompletionDetails(item protocol.CompletionItem) string {
+	var output strings.Builder
+	if item.Documentation != nil {
+		if doc := documentationText(item.Documentation.Value); doc != "" {
+			for _, line := range strings.Split(doc, "\n") {
+				output.WriteString(strings.TrimRight("    "+line, " ") + "\n")
+			}
+		}
+	}
+	for _, edit := range item.AdditionalTextEdits {
+		output.WriteString(fmt.Sprintf("    Also edits L%d:C%d: %q\n", edit.Range.Start.Line+1, edit.Range.Start.Character+1, edit.NewText))
+	}
+	return output.String()
+}
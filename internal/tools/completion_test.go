@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortCompletionItems(t *testing.T) {
+	items := []protocol.CompletionItem{
+		{Label: "zeta", Kind: protocol.FunctionCompletion, SortText: "0002"},
+		{Label: "for", Kind: protocol.SnippetCompletion, SortText: "0000"},
+		{Label: "alpha", Kind: protocol.VariableCompletion, SortText: "0003"},
+		{Label: "mid", Kind: protocol.FieldCompletion, SortText: "0001"},
+		{Label: "beta", Kind: protocol.KeywordCompletion},
+	}
+
+	var labels []string
+	for _, item := range sortCompletionItems(items) {
+		labels = append(labels, item.Label)
+	}
+
+	assert.Equal(t, []string{"mid", "zeta", "alpha", "beta"}, labels)
+}
+
+func TestFormatCompletionDetails(t *testing.T) {
+	testCases := []struct {
+		name     string
+		item     protocol.CompletionItem
+		expected string
+	}{
+		{
+			name:     "Unresolved",
+			item:     protocol.CompletionItem{Label: "Println"},
+			expected: "",
+		},
+		{
+			name: "Markdown documentation",
+			item: protocol.CompletionItem{
+				Label: "Println",
+				Documentation: &protocol.Or_CompletionItem_documentation{Value: protocol.MarkupContent{
+					Kind:  protocol.Markdown,
+					Value: "Println formats using the default formats.\n\nSpaces are always added.",
+				}},
+			},
+			expected: "    Println formats using the default formats.\n\n    Spaces are always added.\n",
+		},
+		{
+			name: "String documentation with an import",
+			item: protocol.CompletionItem{
+				Label:         "Println",
+				Documentation: &protocol.Or_CompletionItem_documentation{Value: "Println prints."},
+				AdditionalTextEdits: []protocol.TextEdit{{
+					Range:   protocol.Range{Start: protocol.Position{Line: 2, Character: 0}, End: protocol.Position{Line: 2, Character: 0}},
+					NewText: "import \"fmt\"\n",
+				}},
+			},
+			expected: "    Println prints.\n    Also edits L3:C1: \"import \\\"fmt\\\"\\n\"\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, formatCompletionDetails(tc.item))
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// DebugInfo shows how the language server behind client was launched, with its
+// command line, environment, working directory and initialization options, and
+// summarizes its initialize response
+func DebugInfo(ctx context.Context, client *lsp.Client) (string, error) {
+	info, _ := client.ServerInfo()
+	capabilities := client.Capabilities()
+
+	supported, err := supportedCapabilities(capabilities)
+	if err != nil {
+		return "", fmt.Errorf("failed to read server capabilities: %v", err)
+	}
+
+	return formatDebugInfo(client.LaunchInfo(), info, capabilities.PositionEncoding, supported, time.Now()), nil
+}
+
+func formatDebugInfo(launch lsp.LaunchInfo, info protocol.ServerInfo, encoding *protocol.PositionEncodingKind, supported map[string]bool, now time.Time) string {
+	var output strings.Builder
+
+	// A server reached over TCP was not launched here, so only its address is known
+	if launch.Address != "" {
+		address := launch.Address
+		if launch.Command != "" {
+			address += " (" + launch.Command + ")"
+		}
+		output.WriteString(fmt.Sprintf("Connected over TCP to %s, up %s\n", address, now.Sub(launch.StartedAt).Round(time.Second)))
+	} else {
+		commandLine := []string{quoteArg(launch.Command)}
+		for _, arg := range launch.Args {
+			commandLine = append(commandLine, quoteArg(arg))
+		}
+		output.WriteString(fmt.Sprintf("Command: %s\n", strings.Join(commandLine, " ")))
+
+		dir := launch.Dir
+		if dir == "" {
+			dir = "(current directory)"
+		}
+		output.WriteString(fmt.Sprintf("Working directory: %s\n", dir))
+
+		if launch.PID != 0 {
+			output.WriteString(fmt.Sprintf("PID: %d, up %s\n", launch.PID, now.Sub(launch.StartedAt).Round(time.Second)))
+		}
+
+		if len(launch.Env) == 0 {
+			output.WriteString("Environment: inherited\n")
+		} else {
+			output.WriteString("Environment, on top of the inherited one:\n")
+			for _, key := range slices.Sorted(maps.Keys(launch.Env)) {
+				output.WriteString(fmt.Sprintf("  %s=%s\n", key, launch.Env[key]))
+			}
+		}
+	}
+
+	output.WriteString("Workspace folders:\n")
+	for _, folder := range launch.WorkspaceFolders {
+		output.WriteString("  " + folder + "\n")
+	}
+
+	if len(launch.InitializationOptions) > 0 {
+		options, err := json.MarshalIndent(launch.InitializationOptions, "  ", "  ")
+		if err != nil {
+			options = []byte(fmt.Sprintf("%v", launch.InitializationOptions))
+		}
+		output.WriteString(fmt.Sprintf("Initialization options:\n  %s\n", options))
+	}
+
+	output.WriteString("\nInitialize response:\n")
+	name := info.Name
+	if name == "" {
+		name = "unknown"
+	}
+	if info.Version != "" {
+		name += " " + info.Version
+	}
+	output.WriteString(fmt.Sprintf("  Server: %s\n", name))
+
+	// Servers that don't say use UTF-16, the LSP default
+	positionEncoding := protocol.UTF16
+	if encoding != nil && *encoding != "" {
+		positionEncoding = *encoding
+	}
+	output.WriteString(fmt.Sprintf("  Position encoding: %s\n", positionEncoding))
+
+	capabilities := slices.Sorted(maps.Keys(supported))
+	output.WriteString(fmt.Sprintf("  Capabilities (%d): %s\n", len(capabilities), strings.Join(capabilities, ", ")))
+	return output.String()
+}
+
+// quoteArg quotes a command line argument if it would otherwise be ambiguous
+func quoteArg(arg string) string {
+	if arg == "" || strings.ContainsAny(arg, " \t\"'") {
+		return strconv.Quote(arg)
+	}
+	return arg
+}
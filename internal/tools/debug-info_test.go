@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatDebugInfo(t *testing.T) {
+	started := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	launch := lsp.LaunchInfo{
+		Command:               "/usr/bin/clangd",
+		Args:                  []string{"--background-index", "--query-driver=/opt/my toolchain/*"},
+		Env:                   map[string]string{"CLANGD_FLAGS": "--log=error"},
+		PID:                   4242,
+		StartedAt:             started,
+		WorkspaceFolders:      []string{"/repo", "/deps"},
+		InitializationOptions: map[string]any{"fallbackFlags": []string{"-std=c++20"}},
+	}
+	supported := map[string]bool{"referencesProvider": true, "definitionProvider": true}
+
+	output := formatDebugInfo(launch, protocol.ServerInfo{Name: "clangd", Version: "17.0.6"}, nil, supported, started.Add(90*time.Second))
+
+	assert.Equal(t, `Command: /usr/bin/clangd --background-index "--query-driver=/opt/my toolchain/*"
+Working directory: (current directory)
+PID: 4242, up 1m30s
+Environment, on top of the inherited one:
+  CLANGD_FLAGS=--log=error
+Workspace folders:
+  /repo
+  /deps
+Initialization options:
+  {
+    "fallbackFlags": [
+      "-std=c++20"
+    ]
+  }
+
+Initialize response:
+  Server: clangd 17.0.6
+  Position encoding: utf-16
+  Capabilities (2): definitionProvider, referencesProvider
+`, output)
+}
+
+func TestFormatDebugInfoNotStarted(t *testing.T) {
+	utf8 := protocol.UTF8
+	output := formatDebugInfo(lsp.LaunchInfo{Command: "gopls", Dir: "/repo/go"}, protocol.ServerInfo{}, &utf8, map[string]bool{}, time.Now())
+
+	assert.Contains(t, output, "Command: gopls\nWorking directory: /repo/go\nEnvironment: inherited\n")
+	assert.NotContains(t, output, "PID")
+	assert.Contains(t, output, "  Server: unknown\n  Position encoding: utf-8\n  Capabilities (0): \n")
+}
+
+func TestFormatDebugInfoTCP(t *testing.T) {
+	utf8 := protocol.UTF8
+	started := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	launch := lsp.LaunchInfo{Command: "clangd", Address: "devcontainer:2087", StartedAt: started}
+	output := formatDebugInfo(launch, protocol.ServerInfo{}, &utf8, map[string]bool{}, started.Add(time.Minute))
+
+	assert.True(t, strings.HasPrefix(output, "Connected over TCP to devcontainer:2087 (clangd), up 1m0s\nWorkspace folders:\n"), output)
+	assert.NotContains(t, output, "Command:")
+}
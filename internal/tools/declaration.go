@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// ReadDeclaration reads the declaration of a symbol, such as a function prototype in
+// a C/C++ header, which may live apart from its definition
+func ReadDeclaration(ctx context.Context, client *lsp.Client, symbolName string) (string, error) {
+	symbolName, err := NormalizeSymbolName(symbolName)
+	if err != nil {
+		return "", err
+	}
+
+	results, err := findSymbols(ctx, client, symbolName)
+	if err != nil {
+		return "", err
+	}
+
+	var declarations []string
+	for _, symbol := range results {
+		kind, container, known := symbolKindAndContainer(symbol)
+		if !known && symbol.GetName() != symbolName {
+			continue
+		}
+
+		loc := symbol.GetLocation()
+
+		err := openURI(ctx, client, loc.URI)
+		if err != nil {
+			toolsLogger.Error("Error opening file: %v", err)
+			continue
+		}
+		defer closeURI(ctx, client, loc.URI)
+
+		position := protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{
+				URI: loc.URI,
+			},
+			Position: loc.Range.Start,
+		}
+
+		declResult, err := client.Declaration(ctx, protocol.DeclarationParams{
+			TextDocumentPositionParams: position,
+		})
+		if err != nil {
+			if isMethodNotSupported(err) {
+				return "The language server does not support finding declarations.", nil
+			}
+			toolsLogger.Error("Error getting declaration: %v", err)
+			continue
+		}
+
+		declLocations, err := declResult.Locations()
+		if err != nil {
+			toolsLogger.Error("Error parsing declaration: %v", err)
+			continue
+		}
+
+		// Used to point out when there is no separate declaration
+		var defLocations []protocol.Location
+		defResult, err := client.Definition(ctx, protocol.DefinitionParams{
+			TextDocumentPositionParams: position,
+		})
+		if err == nil {
+			defLocations, _ = defResult.Locations()
+		}
+
+		for _, declLoc := range declLocations {
+			err := openURI(ctx, client, declLoc.URI)
+			if err != nil {
+				toolsLogger.Error("Error opening file: %v", err)
+				continue
+			}
+			defer closeURI(ctx, client, declLoc.URI)
+
+			banner := "---\n\n"
+			declaration, fullLoc, err := GetFullDefinition(ctx, client, declLoc)
+			if err != nil {
+				toolsLogger.Error("Error getting declaration: %v", err)
+				continue
+			}
+
+			note := ""
+			for _, defLoc := range defLocations {
+				if defLoc.URI == declLoc.URI && defLoc.Range.Start.Line == declLoc.Range.Start.Line {
+					note = "Note: Declaration and definition are at the same location\n"
+					break
+				}
+			}
+
+			locationInfo := fmt.Sprintf(
+				"Symbol: %s\n"+
+					"File: %s\n"+
+					kind+
+					container+
+					"Range: L%d:C%d - L%d:C%d\n"+
+					note+
+					"\n",
+				symbol.GetName(),
+				uriToPath(fullLoc.URI),
+				fullLoc.Range.Start.Line+1,
+				fullLoc.Range.Start.Character+1,
+				fullLoc.Range.End.Line+1,
+				fullLoc.Range.End.Character+1,
+			)
+
+			declaration = addLineNumbers(declaration, int(fullLoc.Range.Start.Line)+1)
+
+			declarations = append(declarations, banner+locationInfo+declaration+"\n")
+		}
+	}
+
+	if len(declarations) == 0 {
+		return fmt.Sprintf("%s not found", symbolName), nil
+	}
+
+	return strings.Join(declarations, ""), nil
+}
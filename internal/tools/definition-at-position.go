@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// ReadDefinitionAtPosition returns the full source of the definition of the symbol
+// at a position (1-indexed line and column). Unlike ReadDefinition it asks the server
+// to resolve the symbol under the cursor, so it is exact for overloaded or common names.
+func ReadDefinitionAtPosition(ctx context.Context, client *lsp.Client, filePath string, line, character int) (string, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+	defer client.CloseFile(ctx, filePath)
+
+	// Convert 1-indexed line/column to 0-indexed for LSP protocol
+	result, err := client.Definition(ctx, protocol.DefinitionParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{
+				URI: protocol.DocumentUri("file://" + filePath),
+			},
+			Position: protocol.Position{
+				Line:      uint32(line - 1),
+				Character: uint32(character - 1),
+			},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get definition: %v", err)
+	}
+
+	locations, err := result.Locations()
+	if err != nil {
+		return "", fmt.Errorf("failed to process definition result: %v", err)
+	}
+
+	definitions := formatDefinitionLocations(ctx, client, locations)
+	if len(definitions) == 0 {
+		return fmt.Sprintf("No definition found at %s L%d:C%d", filePath, line, character), nil
+	}
+
+	return strings.Join(definitions, ""), nil
+}
+
+// formatDefinitionLocations reads the full definition at each location the server
+// returned and formats it with its file and range. Locations that can't be read are
+// logged and skipped.
+func formatDefinitionLocations(ctx context.Context, client *lsp.Client, locations []protocol.Location) []string {
+	var definitions []string
+	for _, loc := range locations {
+		banner := "---\n\n"
+
+		// Definitions in archives or generated sources are fetched from the server,
+		// or described by their hover
+		if !isFileURI(loc.URI) {
+			definition, err := virtualDefinition(ctx, client, loc)
+			if err != nil {
+				toolsLogger.Error("Error getting definition: %v", err)
+				continue
+			}
+			locationInfo := fmt.Sprintf(
+				"File: %s%s\n"+
+					"Position: L%d:C%d\n\n",
+				loc.URI,
+				externalMarker,
+				loc.Range.Start.Line+1,
+				loc.Range.Start.Character+1,
+			)
+			definitions = append(definitions, banner+locationInfo+definition+"\n")
+			continue
+		}
+
+		err := openURI(ctx, client, loc.URI)
+		if err != nil {
+			toolsLogger.Error("Error opening file: %v", err)
+			continue
+		}
+		defer closeURI(ctx, client, loc.URI)
+
+		definition, loc, err := GetFullDefinition(ctx, client, loc)
+		if err != nil {
+			toolsLogger.Error("Error getting definition: %v", err)
+			continue
+		}
+
+		locationInfo := fmt.Sprintf(
+			"File: %s%s\n"+
+				"Range: L%d:C%d - L%d:C%d\n\n",
+			uriToPath(loc.URI),
+			externalSuffix(client, loc.URI),
+			loc.Range.Start.Line+1,
+			loc.Range.Start.Character+1,
+			loc.Range.End.Line+1,
+			loc.Range.End.Character+1,
+		)
+
+		definition = addLineNumbers(definition, int(loc.Range.Start.Line)+1)
+		definitions = append(definitions, banner+locationInfo+definition+"\n")
+	}
+
+	return definitions
+}
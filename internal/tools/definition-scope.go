@@ -0,0 +1,130 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// Scopes select which part of a definition ReadDefinition returns
+const (
+	// ScopeFull returns the whole definition
+	ScopeFull = "full"
+	// ScopeSignature returns the declaration lines up to the opening brace
+	ScopeSignature = "signature"
+	// ScopeBody returns the lines between the opening and closing braces
+	ScopeBody = "body"
+)
+
+// scopeDefinition cuts a definition down to scope. definition holds the lines of
+// full, and selection is the range of the symbol's name within it. The signature
+// runs from the start of the definition to the first line at or after the name that
+// opens a body, with "{" or a trailing ":". Definitions without a body, such as
+// constants, are returned whole for either scope. It returns the text and the range
+// of its lines.
+func scopeDefinition(definition string, full, selection protocol.Range, scope string) (string, protocol.Range, error) {
+	if err := validateScope(scope); err != nil {
+		return "", protocol.Range{}, err
+	}
+	if scope == "" || scope == ScopeFull {
+		return definition, full, nil
+	}
+
+	lines := strings.Split(definition, "\n")
+	first := int(full.Start.Line)
+	signatureEnd := findSignatureEnd(lines, full, selection)
+
+	lineRange := func(start, end int) protocol.Range {
+		return protocol.Range{
+			Start: protocol.Position{Line: uint32(first + start)},
+			End:   protocol.Position{Line: uint32(first + end), Character: uint32(len(lines[end]))},
+		}
+	}
+
+	if signatureEnd < 0 {
+		return definition, full, nil
+	}
+	if scope == ScopeSignature {
+		return strings.Join(lines[:signatureEnd+1], "\n"), lineRange(0, signatureEnd), nil
+	}
+
+	bodyStart, bodyEnd := signatureEnd+1, len(lines)-1
+	if strings.HasPrefix(strings.TrimSpace(lines[bodyEnd]), "}") {
+		bodyEnd--
+	}
+	if bodyStart > bodyEnd {
+		// The body is on the signature line, as in "func f() { return 1 }"
+		return lines[signatureEnd], lineRange(signatureEnd, signatureEnd), nil
+	}
+	return strings.Join(lines[bodyStart:bodyEnd+1], "\n"), lineRange(bodyStart, bodyEnd), nil
+}
+
+// findSignatureEnd returns the index of the line that opens the body of a definition,
+// with "{" or a trailing ":", or -1 if it has no body. lines are the lines of the
+// definition spanning full, and selection is the range of the symbol's name.
+func findSignatureEnd(lines []string, full, selection protocol.Range) int {
+	// Start looking for the body at the name, so braces in attributes or
+	// annotations above it don't count. Servers that report no separate name range
+	// give the whole definition, so the search starts at its first line.
+	searchFrom := 0
+	if selection.Start.Line > full.Start.Line && int(selection.Start.Line) <= int(full.End.Line) {
+		searchFrom = int(selection.Start.Line) - int(full.Start.Line)
+	}
+
+	for i := searchFrom; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.Contains(lines[i], "{") || strings.HasSuffix(trimmed, ":") {
+			return i
+		}
+	}
+	return -1
+}
+
+// truncateDefinition shortens a definition of more than maxLines lines. It keeps the
+// signature, up to signatureEnd, and as many following lines as fit, then a marker
+// with the number of omitted lines and the closing line. maxLines <= 0 means no
+// limit. It returns the text and the number of lines omitted.
+func truncateDefinition(definition string, signatureEnd, maxLines int) (string, int) {
+	lines := strings.Split(strings.TrimSuffix(definition, "\n"), "\n")
+	if maxLines <= 0 || len(lines) <= maxLines {
+		return definition, 0
+	}
+
+	// The marker and closing line take two lines of the budget
+	head := max(maxLines-2, signatureEnd+1, 1)
+	omitted := len(lines) - 1 - head
+	if omitted <= 0 {
+		return definition, 0
+	}
+
+	kept := append([]string{}, lines[:head]...)
+	kept = append(kept, fmt.Sprintf("... %d more lines (use scope=signature)", omitted), lines[len(lines)-1])
+	text := strings.Join(kept, "\n")
+	if strings.HasSuffix(definition, "\n") {
+		text += "\n"
+	}
+	return text, omitted
+}
+
+// maxDefinitionLines returns the line limit from LSP_MAX_DEFINITION_LINES, or 0 for
+// no limit
+func maxDefinitionLines() int {
+	if envLines := os.Getenv("LSP_MAX_DEFINITION_LINES"); envLines != "" {
+		if val, err := strconv.Atoi(envLines); err == nil && val > 0 {
+			return val
+		}
+	}
+	return 0
+}
+
+// validateScope checks that scope is empty or one of the scope constants
+func validateScope(scope string) error {
+	switch scope {
+	case "", ScopeFull, ScopeSignature, ScopeBody:
+		return nil
+	}
+	return fmt.Errorf("invalid scope %q, expected one of %s, %s or %s", scope, ScopeFull, ScopeSignature, ScopeBody)
+}
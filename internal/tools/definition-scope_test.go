@@ -0,0 +1,192 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScopeDefinition(t *testing.T) {
+	lineRange := func(start, end uint32, endChar uint32) protocol.Range {
+		return protocol.Range{
+			Start: protocol.Position{Line: start},
+			End:   protocol.Position{Line: end, Character: endChar},
+		}
+	}
+	name := func(line uint32) protocol.Range {
+		return protocol.Range{Start: protocol.Position{Line: line, Character: 5}, End: protocol.Position{Line: line, Character: 8}}
+	}
+
+	goFunc := "// add sums two numbers\nfunc add(\n\ta, b int,\n) int {\n\tsum := a + b\n\treturn sum\n}"
+	goRange := lineRange(10, 16, 1)
+
+	testCases := []struct {
+		name          string
+		definition    string
+		full          protocol.Range
+		selection     protocol.Range
+		scope         string
+		expected      string
+		expectedRange protocol.Range
+	}{
+		{
+			name:          "Full is unchanged",
+			definition:    goFunc,
+			full:          goRange,
+			selection:     name(11),
+			scope:         ScopeFull,
+			expected:      goFunc,
+			expectedRange: goRange,
+		},
+		{
+			name:          "Multi-line signature",
+			definition:    goFunc,
+			full:          goRange,
+			selection:     name(11),
+			scope:         ScopeSignature,
+			expected:      "// add sums two numbers\nfunc add(\n\ta, b int,\n) int {",
+			expectedRange: lineRange(10, 13, 7),
+		},
+		{
+			name:          "Body",
+			definition:    goFunc,
+			full:          goRange,
+			selection:     name(11),
+			scope:         ScopeBody,
+			expected:      "\tsum := a + b\n\treturn sum",
+			expectedRange: lineRange(14, 15, 11),
+		},
+		{
+			name:          "Braces before the name are ignored",
+			definition:    "@Annotation({1, 2})\nvoid run() {\n  go();\n}",
+			full:          lineRange(0, 3, 1),
+			selection:     name(1),
+			scope:         ScopeSignature,
+			expected:      "@Annotation({1, 2})\nvoid run() {",
+			expectedRange: lineRange(0, 1, 12),
+		},
+		{
+			name:          "Python body",
+			definition:    "def add(a, b):\n    return a + b",
+			full:          lineRange(0, 1, 16),
+			selection:     name(0),
+			scope:         ScopeBody,
+			expected:      "    return a + b",
+			expectedRange: lineRange(1, 1, 16),
+		},
+		{
+			name:          "One-line body",
+			definition:    "func one() int { return 1 }",
+			full:          lineRange(3, 3, 27),
+			selection:     name(3),
+			scope:         ScopeBody,
+			expected:      "func one() int { return 1 }",
+			expectedRange: lineRange(3, 3, 27),
+		},
+		{
+			name:          "No body",
+			definition:    "const answer = 42",
+			full:          lineRange(2, 2, 17),
+			selection:     name(2),
+			scope:         ScopeBody,
+			expected:      "const answer = 42",
+			expectedRange: lineRange(2, 2, 17),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			text, rng, err := scopeDefinition(tc.definition, tc.full, tc.selection, tc.scope)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, text)
+			assert.Equal(t, tc.expectedRange, rng)
+		})
+	}
+}
+
+func TestScopeDefinitionInvalid(t *testing.T) {
+	_, _, err := scopeDefinition("x", protocol.Range{}, protocol.Range{}, "header")
+	assert.ErrorContains(t, err, `invalid scope "header"`)
+}
+
+func TestSelectionInfo(t *testing.T) {
+	full := protocol.Range{
+		Start: protocol.Position{Line: 9},
+		End:   protocol.Position{Line: 15, Character: 1},
+	}
+	selection := protocol.Range{
+		Start: protocol.Position{Line: 10, Character: 5},
+		End:   protocol.Position{Line: 10, Character: 8},
+	}
+
+	assert.Equal(t, "Selection: L11:C6 - L11:C9\n", selectionInfo(selection, full))
+	assert.Equal(t, &ResultRange{
+		Start: ResultPosition{Line: 11, Column: 6},
+		End:   ResultPosition{Line: 11, Column: 9},
+	}, selectionRange(selection, full))
+
+	assert.Empty(t, selectionInfo(full, full))
+	assert.Nil(t, selectionRange(full, full))
+}
+
+func TestTruncateDefinition(t *testing.T) {
+	definition := "func big() {\n\ta := 1\n\tb := 2\n\tc := 3\n\td := 4\n\treturn\n}"
+
+	testCases := []struct {
+		name            string
+		definition      string
+		signatureEnd    int
+		maxLines        int
+		expected        string
+		expectedOmitted int
+	}{
+		{
+			name:         "No limit",
+			definition:   definition,
+			signatureEnd: 0,
+			maxLines:     0,
+			expected:     definition,
+		},
+		{
+			name:         "Within limit",
+			definition:   definition,
+			signatureEnd: 0,
+			maxLines:     7,
+			expected:     definition,
+		},
+		{
+			name:            "Truncated",
+			definition:      definition,
+			signatureEnd:    0,
+			maxLines:        4,
+			expected:        "func big() {\n\ta := 1\n... 4 more lines (use scope=signature)\n}",
+			expectedOmitted: 4,
+		},
+		{
+			name:            "Keeps a long signature",
+			definition:      "func big(\n\ta int,\n\tb int,\n) {\n\treturn\n\treturn\n}",
+			signatureEnd:    3,
+			maxLines:        3,
+			expected:        "func big(\n\ta int,\n\tb int,\n) {\n... 2 more lines (use scope=signature)\n}",
+			expectedOmitted: 2,
+		},
+		{
+			name:            "Numbered lines",
+			definition:      "1|func big() {\n2|\ta := 1\n3|\tb := 2\n4|\treturn\n5|}\n",
+			signatureEnd:    0,
+			maxLines:        3,
+			expected:        "1|func big() {\n... 3 more lines (use scope=signature)\n5|}\n",
+			expectedOmitted: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			text, omitted := truncateDefinition(tc.definition, tc.signatureEnd, tc.maxLines)
+			assert.Equal(t, tc.expected, text)
+			assert.Equal(t, tc.expectedOmitted, omitted)
+		})
+	}
+}
@@ -3,51 +3,81 @@ package tools
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
-func ReadDefinition(ctx context.Context, client *lsp.Client, symbolName string) (string, error) {
-	symbolResult, err := client.Symbol(ctx, protocol.WorkspaceSymbolParams{
-		Query: symbolName,
-	})
+// ReadDefinition returns the full source of the definitions of a symbol. opts selects
+// how strictly symbol names must match and caps the number of definitions.
+func ReadDefinition(ctx context.Context, client *lsp.Client, symbolName string, opts DefinitionOptions) (string, error) {
+	symbolName, err := NormalizeSymbolName(symbolName)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch symbol: %v", err)
+		return "", err
+	}
+
+	if err := validateScope(opts.Scope); err != nil {
+		return "", err
 	}
 
-	results, err := symbolResult.Results()
+	opened := make(map[protocol.DocumentUri]bool)
+	defer closeURIs(ctx, client, opened)
+	text, _, err := readDefinition(ctx, client, symbolName, opts, opened)
+	return text, err
+}
+
+// readDefinition formats the definitions of one symbol and reports whether any were
+// found. Files already in opened are not opened again, and files it opens are added.
+func readDefinition(ctx context.Context, client *lsp.Client, symbolName string, opts DefinitionOptions, opened map[protocol.DocumentUri]bool) (string, bool, error) {
+	results, err := findSymbols(ctx, client, symbolName)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse results: %v", err)
+		return "", false, err
+	}
+	return readSymbolDefinitions(ctx, client, symbolName, results, opts, opened)
+}
+
+// readSymbolDefinitions is readDefinition for symbols already looked up with
+// findSymbols
+func readSymbolDefinitions(ctx context.Context, client *lsp.Client, symbolName string, results []protocol.WorkspaceSymbolResult, opts DefinitionOptions, opened map[protocol.DocumentUri]bool) (string, bool, error) {
+	maxResults := opts.MaxResults
+	if maxResults <= 0 {
+		maxResults = DefaultMaxDefinitions
+	}
+	maxLines := opts.MaxLines
+	if maxLines <= 0 {
+		maxLines = maxDefinitionLines()
 	}
 
-	var definitions []string
+	results, err := filterSymbols(results, symbolName, opts.MatchMode)
+	if err != nil {
+		return "", false, err
+	}
+	matched := len(results)
+	// Sort before maxResults cuts the list, so the same definitions are kept
+	// whatever order the server returned them in
+	results = sortSymbolsByLocation(filterSymbolsByFile(results, opts.InFile))
+
+	var definitions []formattedDefinition
+	jsonResult := DefinitionsResult{Symbol: symbolName, Definitions: []DefinitionResult{}}
+	truncated := false
 	for _, symbol := range results {
-		kind := ""
-		container := ""
+		if len(definitions) >= maxResults || len(jsonResult.Definitions) >= maxResults {
+			truncated = true
+			break
+		}
 
 		// Skip symbols that we are not looking for. workspace/symbol may return
 		// a large number of fuzzy matches.
-		var containerName string
-
-		switch v := symbol.(type) {
-		case *protocol.SymbolInformation:
-			// SymbolInformation results have richer data.
-			kind = fmt.Sprintf("Kind: %s\n", protocol.TableKindMap[v.Kind])
-			containerName = v.ContainerName
-			if containerName != "" {
-				container = fmt.Sprintf("Container Name: %s\n", containerName)
-			}
-		case *protocol.WorkspaceSymbol:
-			// WorkspaceSymbol (used by clangd)
-			// Only add Kind if there's a container name to distinguish from legacy output
-			if v.ContainerName != "" {
-				kind = fmt.Sprintf("Kind: %s\n", protocol.TableKindMap[v.Kind])
-				container = fmt.Sprintf("Container Name: %s\n", v.ContainerName)
-			}
-			containerName = v.ContainerName
-		default:
+		kind, container, known := symbolKindAndContainer(symbol)
+		deprecated := symbolDeprecated(symbol)
+		deprecatedInfo := ""
+		if deprecated {
+			deprecatedInfo = "Deprecated: true\n"
+		}
+		if !known {
 			// Unknown symbol type, use basic matching
 			if symbol.GetName() != symbolName {
 				continue
@@ -67,23 +97,79 @@ func ReadDefinition(ctx context.Context, client *lsp.Client, symbolName string)
 
 		toolsLogger.Debug("Found symbol: %s", symbol.GetName())
 		loc := symbol.GetLocation()
+		banner := "---\n\n"
 
-		err := client.OpenFile(ctx, loc.URI.Path())
-		if err != nil {
-			toolsLogger.Error("Error opening file: %v", err)
+		// Definitions in archives or generated sources are fetched from the server,
+		// or described by their hover
+		if !isFileURI(loc.URI) {
+			definition, err := virtualDefinition(ctx, client, loc)
+			if err != nil {
+				toolsLogger.Error("Error getting definition: %v", err)
+				continue
+			}
+
+			if outputFormat() == OutputFormatJSON {
+				kindName, containerName := symbolKindNameAndContainer(symbol)
+				jsonResult.Definitions = append(jsonResult.Definitions, DefinitionResult{
+					Symbol:     symbol.GetName(),
+					File:       string(loc.URI),
+					External:   true,
+					Kind:       kindName,
+					Container:  containerName,
+					Deprecated: deprecated,
+					Range:      newResultRange(loc.Range),
+					Code:       definition,
+				})
+				continue
+			}
+
+			locationInfo := fmt.Sprintf(
+				"Symbol: %s\n"+
+					"File: %s%s\n"+
+					kind+
+					container+
+					deprecatedInfo+
+					"Position: L%d:C%d\n\n",
+				symbol.GetName(),
+				loc.URI,
+				externalMarker,
+				loc.Range.Start.Line+1,
+				loc.Range.Start.Character+1,
+			)
+			definitions = append(definitions, formattedDefinition{
+				file:  string(loc.URI),
+				start: newResultRange(loc.Range).Start,
+				text:  banner + locationInfo + definition + "\n",
+			})
 			continue
 		}
 
-		banner := "---\n\n"
+		if !opened[loc.URI] {
+			err := openURI(ctx, client, loc.URI)
+			if err != nil {
+				toolsLogger.Error("Error opening file: %v", err)
+				continue
+			}
+			opened[loc.URI] = true
+		}
+
+		selection := loc.Range
 		definition, loc, err := GetFullDefinition(ctx, client, loc)
+		if err == nil {
+			definition, loc.Range, err = scopeDefinition(definition, loc.Range, selection, opts.Scope)
+		}
 		locationInfo := fmt.Sprintf(
 			"Symbol: %s\n"+
-				"File: %s\n"+
+				"File: %s%s\n"+
 				kind+
 				container+
-				"Range: L%d:C%d - L%d:C%d\n\n",
+				deprecatedInfo+
+				"Range: L%d:C%d - L%d:C%d\n"+
+				selectionInfo(selection, loc.Range)+
+				"\n",
 			symbol.GetName(),
-			strings.TrimPrefix(string(loc.URI), "file://"),
+			uriToPath(loc.URI),
+			externalSuffix(client, loc.URI),
 			loc.Range.Start.Line+1,
 			loc.Range.Start.Character+1,
 			loc.Range.End.Line+1,
@@ -95,14 +181,164 @@ func ReadDefinition(ctx context.Context, client *lsp.Client, symbolName string)
 			continue
 		}
 
+		// Only whole definitions start with the signature
+		signatureEnd := -1
+		if opts.Scope == "" || opts.Scope == ScopeFull {
+			signatureEnd = findSignatureEnd(strings.Split(definition, "\n"), loc.Range, selection)
+		}
+
+		if outputFormat() == OutputFormatJSON {
+			kindName, containerName := symbolKindNameAndContainer(symbol)
+			code, omitted := truncateDefinition(definition, signatureEnd, maxLines)
+			jsonResult.Definitions = append(jsonResult.Definitions, DefinitionResult{
+				Symbol:       symbol.GetName(),
+				File:         uriToPath(loc.URI),
+				External:     externalSuffix(client, loc.URI) != "",
+				Kind:         kindName,
+				Container:    containerName,
+				Deprecated:   deprecated,
+				Range:        newResultRange(loc.Range),
+				Selection:    selectionRange(selection, loc.Range),
+				Code:         code,
+				OmittedLines: omitted,
+			})
+			continue
+		}
+
 		definition = addLineNumbers(definition, int(loc.Range.Start.Line)+1)
+		definition, _ = truncateDefinition(definition, signatureEnd, maxLines)
+
+		definitions = append(definitions, formattedDefinition{
+			file:  uriToPath(loc.URI),
+			start: newResultRange(loc.Range).Start,
+			text:  banner + locationInfo + definition + "\n",
+		})
+	}
 
-		definitions = append(definitions, banner+locationInfo+definition+"\n")
+	// Servers return symbols in no particular order, sort them so repeated calls
+	// give the same output
+	if outputFormat() == OutputFormatJSON {
+		sort.SliceStable(jsonResult.Definitions, func(i, j int) bool {
+			a, b := jsonResult.Definitions[i], jsonResult.Definitions[j]
+			return definitionBefore(a.File, a.Range.Start, b.File, b.Range.Start)
+		})
+		jsonResult.Truncated = truncated
+		text, err := formatJSON(jsonResult)
+		return text, len(jsonResult.Definitions) > 0, err
 	}
 
 	if len(definitions) == 0 {
-		return fmt.Sprintf("%s not found", symbolName), nil
+		if opts.InFile != "" && matched > 0 {
+			return fmt.Sprintf("%s not found in files matching %q (%d matches in other files)", symbolName, opts.InFile, matched), false, nil
+		}
+		return fmt.Sprintf("%s not found", symbolName), false, nil
+	}
+
+	sort.SliceStable(definitions, func(i, j int) bool {
+		a, b := definitions[i], definitions[j]
+		return definitionBefore(a.file, a.start, b.file, b.start)
+	})
+	texts := make([]string, 0, len(definitions))
+	for _, definition := range definitions {
+		texts = append(texts, definition.text)
+	}
+
+	note := ""
+	if truncated {
+		note = fmt.Sprintf("---\n\nShowing the first %d definitions. Use matchMode \"exact\", inFile or a more specific name to narrow the results.\n", maxResults)
 	}
 
-	return strings.Join(definitions, ""), nil
+	return joinSections(texts, "", note), true, nil
+}
+
+// formattedDefinition is the text of one definition with where it starts, to sort by
+type formattedDefinition struct {
+	file  string
+	start ResultPosition
+	text  string
+}
+
+// definitionBefore orders definitions by file path, then by start line and column
+func definitionBefore(fileA string, startA ResultPosition, fileB string, startB ResultPosition) bool {
+	if fileA != fileB {
+		return fileA < fileB
+	}
+	if startA.Line != startB.Line {
+		return startA.Line < startB.Line
+	}
+	return startA.Column < startB.Column
+}
+
+// selectionInfo returns the "Selection:" line giving the identifier's range when it
+// differs from the range of the definition shown, and an empty string otherwise
+func selectionInfo(selection, full protocol.Range) string {
+	if selection == full {
+		return ""
+	}
+	return fmt.Sprintf("Selection: L%d:C%d - L%d:C%d\n",
+		selection.Start.Line+1,
+		selection.Start.Character+1,
+		selection.End.Line+1,
+		selection.End.Character+1,
+	)
+}
+
+// selectionRange is the structured form of selectionInfo
+func selectionRange(selection, full protocol.Range) *ResultRange {
+	if selection == full {
+		return nil
+	}
+	r := newResultRange(selection)
+	return &r
+}
+
+// symbolKindAndContainer returns the "Kind:" and "Container Name:" lines shown for a
+// workspace symbol, and false if the symbol type carries no such information
+func symbolKindAndContainer(symbol protocol.WorkspaceSymbolResult) (kind string, container string, known bool) {
+	switch v := symbol.(type) {
+	case *protocol.SymbolInformation:
+		// SymbolInformation results have richer data.
+		kind = fmt.Sprintf("Kind: %s\n", protocol.SymbolKindName(v.Kind))
+		if v.ContainerName != "" {
+			container = fmt.Sprintf("Container Name: %s\n", v.ContainerName)
+		}
+		return kind, container, true
+	case *protocol.WorkspaceSymbol:
+		// WorkspaceSymbol (used by clangd)
+		// Only add Kind if there's a container name to distinguish from legacy output
+		if v.ContainerName != "" {
+			kind = fmt.Sprintf("Kind: %s\n", protocol.SymbolKindName(v.Kind))
+			container = fmt.Sprintf("Container Name: %s\n", v.ContainerName)
+		}
+		return kind, container, true
+	}
+	return "", "", false
+}
+
+// symbolDeprecated reports whether a workspace symbol is tagged deprecated, or for
+// older servers has the deprecated flag set
+func symbolDeprecated(symbol protocol.WorkspaceSymbolResult) bool {
+	var tags []protocol.SymbolTag
+	switch v := symbol.(type) {
+	case *protocol.SymbolInformation:
+		if v.Deprecated {
+			return true
+		}
+		tags = v.Tags
+	case *protocol.WorkspaceSymbol:
+		tags = v.Tags
+	}
+	return slices.Contains(tags, protocol.DeprecatedSymbol)
+}
+
+// symbolKindNameAndContainer returns the kind name and container of a workspace
+// symbol for structured output
+func symbolKindNameAndContainer(symbol protocol.WorkspaceSymbolResult) (kind string, container string) {
+	switch v := symbol.(type) {
+	case *protocol.SymbolInformation:
+		return protocol.SymbolKindName(v.Kind), v.ContainerName
+	case *protocol.WorkspaceSymbol:
+		return protocol.SymbolKindName(v.Kind), v.ContainerName
+	}
+	return "", ""
 }
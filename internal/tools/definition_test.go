@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefinitionBefore(t *testing.T) {
+	definitions := []formattedDefinition{
+		{file: "/src/b.go", start: ResultPosition{Line: 3, Column: 1}, text: "b3"},
+		{file: "/src/a.go", start: ResultPosition{Line: 20, Column: 1}, text: "a20"},
+		{file: "/src/a.go", start: ResultPosition{Line: 4, Column: 9}, text: "a4:9"},
+		{file: "/src/a.go", start: ResultPosition{Line: 4, Column: 2}, text: "a4:2"},
+	}
+
+	sort.SliceStable(definitions, func(i, j int) bool {
+		a, b := definitions[i], definitions[j]
+		return definitionBefore(a.file, a.start, b.file, b.start)
+	})
+
+	var order []string
+	for _, definition := range definitions {
+		order = append(order, definition.text)
+	}
+	assert.Equal(t, []string{"a4:2", "a4:9", "a20", "b3"}, order)
+}
+
+func TestSymbolDeprecated(t *testing.T) {
+	testCases := []struct {
+		name     string
+		symbol   protocol.WorkspaceSymbolResult
+		expected bool
+	}{
+		{
+			name:     "Untagged",
+			symbol:   &protocol.SymbolInformation{Name: "Get"},
+			expected: false,
+		},
+		{
+			name:     "Deprecated flag",
+			symbol:   &protocol.SymbolInformation{Name: "Get", Deprecated: true},
+			expected: true,
+		},
+		{
+			name:     "Deprecated tag",
+			symbol:   &protocol.SymbolInformation{Name: "Get", Tags: []protocol.SymbolTag{protocol.DeprecatedSymbol}},
+			expected: true,
+		},
+		{
+			name: "Workspace symbol tag",
+			symbol: &protocol.WorkspaceSymbol{
+				BaseSymbolInformation: protocol.BaseSymbolInformation{
+					Name: "Get",
+					Tags: []protocol.SymbolTag{protocol.DeprecatedSymbol},
+				},
+			},
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, symbolDeprecated(tc.symbol))
+		})
+	}
+}
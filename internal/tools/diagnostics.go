@@ -12,6 +12,14 @@ import (
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
+// diagnosticsTimeout bounds how long to wait for the server to publish diagnostics
+const diagnosticsTimeout = 5 * time.Second
+
+// GetDiagnostics retrieves diagnostics for a file with a couple of context lines around each one
+func GetDiagnostics(ctx context.Context, client *lsp.Client, filePath string) (string, error) {
+	return GetDiagnosticsForFile(ctx, client, filePath, 2, true)
+}
+
 // GetDiagnosticsForFile retrieves diagnostics for a specific file from the language server
 func GetDiagnosticsForFile(ctx context.Context, client *lsp.Client, filePath string, contextLines int, showLineNumbers bool) (string, error) {
 	// Override with environment variable if specified
@@ -21,17 +29,29 @@ func GetDiagnosticsForFile(ctx context.Context, client *lsp.Client, filePath str
 		}
 	}
 
+	// Convert the file path to URI format
+	uri := protocol.DocumentUri("file://" + filePath)
+
+	// Diagnostics are published asynchronously after didOpen. Only wait if the file
+	// isn't open already, as those published before it was last closed may be stale,
+	// or the server hasn't published any for it yet. Listen before didOpen is sent so
+	// a quick reply isn't missed.
+	var waiter *lsp.DiagnosticsWaiter
+	if !client.IsFileOpen(filePath) || !client.HasDiagnostics(uri) {
+		waiter = client.ExpectDiagnostics(uri)
+	}
 	err := client.OpenFile(ctx, filePath)
 	if err != nil {
+		if waiter != nil {
+			waiter.Cancel()
+		}
 		return "", fmt.Errorf("could not open file: %v", err)
 	}
+	defer client.CloseFile(ctx, filePath)
 
-	// Wait for diagnostics
-	// TODO: wait for notification
-	time.Sleep(time.Second * 3)
-
-	// Convert the file path to URI format
-	uri := protocol.DocumentUri("file://" + filePath)
+	if waiter != nil && !waiter.Wait(ctx, diagnosticsTimeout) {
+		toolsLogger.Debug("No diagnostics published for %s within %v", filePath, diagnosticsTimeout)
+	}
 
 	// Request fresh diagnostics
 	diagParams := protocol.DocumentDiagnosticParams{
@@ -96,7 +116,7 @@ func GetDiagnosticsForFile(ctx context.Context, client *lsp.Client, filePath str
 		return fileInfo + "\nError reading file: " + err.Error(), nil
 	}
 
-	lines := strings.Split(string(fileContent), "\n")
+	lines := splitLines(fileContent)
 
 	// Collect lines to display
 	var linesToShow map[int]bool
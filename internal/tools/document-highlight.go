@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+var documentHighlightKindNames = map[protocol.DocumentHighlightKind]string{
+	protocol.Text:  "Text",
+	protocol.Read:  "Read",
+	protocol.Write: "Write",
+}
+
+// DocumentHighlight lists the occurrences of the symbol at a position within the same
+// file, marking each as a read, write or plain text occurrence
+func DocumentHighlight(ctx context.Context, client *lsp.Client, filePath string, line, character int) (string, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+	defer client.CloseFile(ctx, filePath)
+
+	// Convert 1-indexed line/column to 0-indexed for LSP protocol
+	uri := protocol.DocumentUri("file://" + filePath)
+	highlights, err := client.DocumentHighlight(ctx, protocol.DocumentHighlightParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{
+				URI: uri,
+			},
+			Position: protocol.Position{
+				Line:      uint32(line - 1),
+				Character: uint32(character - 1),
+			},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get document highlights: %v", err)
+	}
+
+	if len(highlights) == 0 {
+		return fmt.Sprintf("No highlights found at %s L%d:C%d", filePath, line, character), nil
+	}
+
+	fileContent, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	lines := splitLines(fileContent)
+
+	var locations []protocol.Location
+	var locStrings []string
+	for _, highlight := range highlights {
+		locations = append(locations, protocol.Location{URI: uri, Range: highlight.Range})
+
+		// The kind defaults to Text when omitted
+		kind := documentHighlightKindNames[highlight.Kind]
+		if kind == "" {
+			kind = "Text"
+		}
+		locStrings = append(locStrings, fmt.Sprintf("L%d:C%d (%s)",
+			highlight.Range.Start.Line+1,
+			highlight.Range.Start.Character+1,
+			kind))
+	}
+
+	linesToShow, err := GetLineRangesToDisplay(ctx, client, locations, len(lines), defaultContextLines())
+	if err != nil {
+		return "", fmt.Errorf("failed to get lines to display: %v", err)
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("---\n\n%s\nHighlights in File: %d\n", filePath, len(highlights)))
+	output.WriteString("At: " + strings.Join(locStrings, ", ") + "\n")
+	output.WriteString("\n" + FormatLinesWithRanges(lines, ConvertLinesToRanges(linesToShow, len(lines))))
+
+	return output.String(), nil
+}
@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// GetDocumentLinks lists the links in a file, such as C++ #include targets or
+// import paths, with the file or URI each one points to. Links the server sends
+// without a target are resolved with documentLink/resolve when it supports that.
+func GetDocumentLinks(ctx context.Context, client *lsp.Client, filePath string) (string, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+	defer client.CloseFile(ctx, filePath)
+
+	links, err := client.DocumentLink(ctx, protocol.DocumentLinkParams{
+		TextDocument: protocol.TextDocumentIdentifier{
+			URI: protocol.DocumentUri("file://" + filePath),
+		},
+	})
+	if err != nil {
+		if isMethodNotSupported(err) {
+			return "The language server does not support document links.", nil
+		}
+		return "", fmt.Errorf("failed to get document links: %v", err)
+	}
+
+	if len(links) == 0 {
+		return fmt.Sprintf("No document links found in %s", filePath), nil
+	}
+
+	provider := client.Capabilities().DocumentLinkProvider
+	if provider != nil && provider.ResolveProvider {
+		for i, link := range links {
+			if link.Target != nil {
+				continue
+			}
+			resolved, err := client.ResolveDocumentLink(ctx, link)
+			if err != nil {
+				toolsLogger.Warn("Failed to resolve document link at L%d: %v", link.Range.Start.Line+1, err)
+				continue
+			}
+			links[i] = resolved
+		}
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+
+	return formatDocumentLinks(links, splitLines(content), filePath), nil
+}
+
+func formatDocumentLinks(links []protocol.DocumentLink, lines []string, filePath string) string {
+	sortedLinks := make([]protocol.DocumentLink, len(links))
+	copy(sortedLinks, links)
+	sort.SliceStable(sortedLinks, func(i, j int) bool {
+		return positionBefore(sortedLinks[i].Range.Start, sortedLinks[j].Range.Start)
+	})
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Document links in %s:\n\n", filePath))
+	for _, link := range sortedLinks {
+		r := link.Range
+		output.WriteString(fmt.Sprintf("L%d:C%d-L%d:C%d", r.Start.Line+1, r.Start.Character+1, r.End.Line+1, r.End.Character+1))
+		if r.Start.Line == r.End.Line && int(r.Start.Line) < len(lines) {
+			output.WriteString(fmt.Sprintf(" %q", singleLineText(lines[r.Start.Line], r)))
+		}
+
+		target := "(unresolved)"
+		if link.Target != nil && *link.Target != "" {
+			target = *link.Target
+			if isFileURI(protocol.DocumentUri(target)) {
+				target = uriToPath(protocol.DocumentUri(target))
+			}
+		}
+		output.WriteString(" -> " + target)
+
+		if link.Tooltip != "" {
+			output.WriteString(" (" + link.Tooltip + ")")
+		}
+		output.WriteString("\n")
+	}
+	return output.String()
+}
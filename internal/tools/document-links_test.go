@@ -0,0 +1,40 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatDocumentLinks(t *testing.T) {
+	lines := []string{
+		"#include <vector>",
+		"#include \"cache.h\"",
+		"// See https://example.com/docs",
+	}
+	link := func(line, start, end uint32, target string) protocol.DocumentLink {
+		l := protocol.DocumentLink{Range: protocol.Range{
+			Start: protocol.Position{Line: line, Character: start},
+			End:   protocol.Position{Line: line, Character: end},
+		}}
+		if target != "" {
+			l.Target = &target
+		}
+		return l
+	}
+
+	links := []protocol.DocumentLink{
+		link(2, 7, 31, "https://example.com/docs"),
+		link(1, 9, 18, "file:///src/cache.h"),
+		link(0, 9, 17, ""),
+	}
+	links[1].Tooltip = "cache.h"
+
+	expected := "Document links in /src/main.cpp:\n\n" +
+		"L1:C10-L1:C18 \"<vector>\" -> (unresolved)\n" +
+		"L2:C10-L2:C19 \"\\\"cache.h\\\"\" -> /src/cache.h (cache.h)\n" +
+		"L3:C8-L3:C32 \"https://example.com/docs\" -> https://example.com/docs\n"
+
+	assert.Equal(t, expected, formatDocumentLinks(links, lines, "/src/main.cpp"))
+}
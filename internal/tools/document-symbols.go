@@ -0,0 +1,214 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// symbolNode is a document symbol in a form shared by the hierarchical
+// DocumentSymbol and the flat SymbolInformation responses
+type symbolNode struct {
+	Name     string
+	Detail   string
+	Kind     protocol.SymbolKind
+	Range    protocol.Range
+	Children []*symbolNode
+}
+
+// DocumentSymbols returns an outline of all symbols in a file, indented to show
+// nesting. If kinds is not empty, only symbols of those kinds (such as "Method" or
+// "Class") are listed, along with the symbols enclosing them.
+func DocumentSymbols(ctx context.Context, client *lsp.Client, filePath string, kinds []string) (string, error) {
+	kindFilter, err := parseSymbolKinds(kinds)
+	if err != nil {
+		return "", err
+	}
+
+	err = client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+	defer client.CloseFile(ctx, filePath)
+
+	symResult, err := client.DocumentSymbol(ctx, protocol.DocumentSymbolParams{
+		TextDocument: protocol.TextDocumentIdentifier{
+			URI: protocol.DocumentUri("file://" + filePath),
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get document symbols: %v", err)
+	}
+
+	symbols, err := symResult.Results()
+	if err != nil {
+		return "", fmt.Errorf("failed to process document symbols: %v", err)
+	}
+
+	if len(symbols) == 0 {
+		return fmt.Sprintf("No symbols found in %s", filePath), nil
+	}
+
+	tree := buildSymbolTree(symbols)
+	if len(kindFilter) > 0 {
+		tree = filterSymbolTree(tree, kindFilter)
+		if len(tree) == 0 {
+			return fmt.Sprintf("No symbols of kind %s found in %s", strings.Join(kinds, ", "), filePath), nil
+		}
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Symbols in %s:\n\n", filePath))
+	writeSymbolTree(&output, tree, 0)
+
+	return output.String(), nil
+}
+
+// buildSymbolTree converts a documentSymbol response into a tree. Servers that
+// return the flat SymbolInformation form have their nesting reconstructed from
+// container names.
+func buildSymbolTree(symbols []protocol.DocumentSymbolResult) []*symbolNode {
+	var nodes []*symbolNode
+	var flat []*protocol.SymbolInformation
+
+	for _, sym := range symbols {
+		switch v := sym.(type) {
+		case *protocol.DocumentSymbol:
+			nodes = append(nodes, symbolNodeFromDocumentSymbol(v))
+		case *protocol.SymbolInformation:
+			flat = append(flat, v)
+		}
+	}
+
+	if len(flat) > 0 {
+		nodes = append(nodes, nestSymbolInformation(flat)...)
+	}
+
+	return nodes
+}
+
+func symbolNodeFromDocumentSymbol(ds *protocol.DocumentSymbol) *symbolNode {
+	node := &symbolNode{
+		Name:   ds.Name,
+		Detail: ds.Detail,
+		Kind:   ds.Kind,
+		Range:  ds.Range,
+	}
+	for i := range ds.Children {
+		node.Children = append(node.Children, symbolNodeFromDocumentSymbol(&ds.Children[i]))
+	}
+	return node
+}
+
+// nestSymbolInformation reconstructs the symbol hierarchy of a flat list. Each
+// symbol is attached to the innermost symbol named after its container that
+// also encloses it. Symbols without a matching container stay at the top level.
+func nestSymbolInformation(symbols []*protocol.SymbolInformation) []*symbolNode {
+	nodes := make([]*symbolNode, len(symbols))
+	for i, si := range symbols {
+		nodes[i] = &symbolNode{
+			Name:  si.Name,
+			Kind:  si.Kind,
+			Range: si.Location.Range,
+		}
+	}
+
+	var roots []*symbolNode
+	for i, si := range symbols {
+		parent := -1
+		if si.ContainerName != "" {
+			for j, candidate := range symbols {
+				if i == j || candidate.Name != si.ContainerName {
+					continue
+				}
+				if !rangeContains(candidate.Location.Range, si.Location.Range) {
+					continue
+				}
+				// Prefer the innermost enclosing container
+				if parent == -1 || rangeContains(symbols[parent].Location.Range, candidate.Location.Range) {
+					parent = j
+				}
+			}
+		}
+
+		if parent == -1 {
+			roots = append(roots, nodes[i])
+		} else {
+			nodes[parent].Children = append(nodes[parent].Children, nodes[i])
+		}
+	}
+
+	return roots
+}
+
+// parseSymbolKinds maps kind names, as in protocol.TableKindMap, to symbol kinds.
+// Names are matched case insensitively.
+func parseSymbolKinds(names []string) (map[protocol.SymbolKind]bool, error) {
+	kinds := make(map[protocol.SymbolKind]bool, len(names))
+	for _, name := range names {
+		found := false
+		for kind, kindName := range protocol.TableKindMap {
+			if strings.EqualFold(kindName, strings.TrimSpace(name)) {
+				kinds[kind] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown symbol kind %q", name)
+		}
+	}
+	return kinds, nil
+}
+
+// filterSymbolTree keeps the symbols whose kind is in kinds. Symbols of other kinds
+// are kept only when they enclose a match, to show where it is nested.
+func filterSymbolTree(nodes []*symbolNode, kinds map[protocol.SymbolKind]bool) []*symbolNode {
+	var filtered []*symbolNode
+	for _, node := range nodes {
+		children := filterSymbolTree(node.Children, kinds)
+		if !kinds[node.Kind] && len(children) == 0 {
+			continue
+		}
+		kept := *node
+		kept.Children = children
+		filtered = append(filtered, &kept)
+	}
+	return filtered
+}
+
+// rangeContains reports whether inner lies within outer
+func rangeContains(outer, inner protocol.Range) bool {
+	if inner.Start.Line < outer.Start.Line || inner.End.Line > outer.End.Line {
+		return false
+	}
+	if inner.Start.Line == outer.Start.Line && inner.Start.Character < outer.Start.Character {
+		return false
+	}
+	if inner.End.Line == outer.End.Line && inner.End.Character > outer.End.Character {
+		return false
+	}
+	return true
+}
+
+func writeSymbolTree(output *strings.Builder, nodes []*symbolNode, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for _, node := range nodes {
+		detail := ""
+		if node.Detail != "" {
+			detail = " " + node.Detail
+		}
+		output.WriteString(fmt.Sprintf("%s%s %s%s (L%d-L%d)\n",
+			indent,
+			protocol.SymbolKindName(node.Kind),
+			node.Name,
+			detail,
+			node.Range.Start.Line+1,
+			node.Range.End.Line+1,
+		))
+		writeSymbolTree(output, node.Children, depth+1)
+	}
+}
@@ -0,0 +1,140 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func symbolInfo(name, container string, kind protocol.SymbolKind, startLine, endLine uint32) protocol.SymbolInformation {
+	return protocol.SymbolInformation{
+		Name:          name,
+		Kind:          kind,
+		ContainerName: container,
+		Location: protocol.Location{
+			URI: "file:///path/to/file.cpp",
+			Range: protocol.Range{
+				Start: protocol.Position{Line: startLine},
+				End:   protocol.Position{Line: endLine},
+			},
+		},
+	}
+}
+
+func TestBuildSymbolTree_Flat(t *testing.T) {
+	flat := []protocol.SymbolInformation{
+		symbolInfo("ns", "", protocol.Namespace, 0, 20),
+		symbolInfo("Widget", "ns", protocol.Class, 2, 10),
+		symbolInfo("draw", "Widget", protocol.Method, 3, 5),
+		symbolInfo("resize", "Widget", protocol.Method, 6, 9),
+		// Same container name but outside its range, stays at the top level
+		symbolInfo("draw", "Widget", protocol.Method, 30, 32),
+		symbolInfo("main", "", protocol.Function, 22, 25),
+	}
+	symbols := make([]protocol.DocumentSymbolResult, len(flat))
+	for i := range flat {
+		symbols[i] = &flat[i]
+	}
+
+	var output strings.Builder
+	writeSymbolTree(&output, buildSymbolTree(symbols), 0)
+
+	expected := "Namespace ns (L1-L21)\n" +
+		"  Class Widget (L3-L11)\n" +
+		"    Method draw (L4-L6)\n" +
+		"    Method resize (L7-L10)\n" +
+		"Method draw (L31-L33)\n" +
+		"Function main (L23-L26)\n"
+	assert.Equal(t, expected, output.String())
+}
+
+func TestBuildSymbolTree_Hierarchical(t *testing.T) {
+	symbols := []protocol.DocumentSymbolResult{
+		&protocol.DocumentSymbol{
+			Name:   "TestStruct",
+			Detail: "struct{...}",
+			Kind:   protocol.Struct,
+			Range: protocol.Range{
+				Start: protocol.Position{Line: 4},
+				End:   protocol.Position{Line: 7},
+			},
+			Children: []protocol.DocumentSymbol{
+				{
+					Name:   "Name",
+					Detail: "string",
+					Kind:   protocol.Field,
+					Range: protocol.Range{
+						Start: protocol.Position{Line: 5},
+						End:   protocol.Position{Line: 5},
+					},
+				},
+			},
+		},
+	}
+
+	var output strings.Builder
+	writeSymbolTree(&output, buildSymbolTree(symbols), 0)
+
+	expected := "Struct TestStruct struct{...} (L5-L8)\n" +
+		"  Field Name string (L6-L6)\n"
+	assert.Equal(t, expected, output.String())
+}
+
+func TestFilterSymbolTree(t *testing.T) {
+	flat := []protocol.SymbolInformation{
+		symbolInfo("ns", "", protocol.Namespace, 0, 20),
+		symbolInfo("Widget", "ns", protocol.Class, 2, 10),
+		symbolInfo("draw", "Widget", protocol.Method, 3, 5),
+		symbolInfo("size", "Widget", protocol.Field, 6, 6),
+		symbolInfo("Config", "ns", protocol.Struct, 11, 15),
+		symbolInfo("main", "", protocol.Function, 22, 25),
+	}
+	symbols := make([]protocol.DocumentSymbolResult, len(flat))
+	for i := range flat {
+		symbols[i] = &flat[i]
+	}
+
+	testCases := []struct {
+		name     string
+		kinds    []string
+		expected string
+	}{
+		{
+			name:  "Methods keep their enclosing symbols",
+			kinds: []string{"method"},
+			expected: "Namespace ns (L1-L21)\n" +
+				"  Class Widget (L3-L11)\n" +
+				"    Method draw (L4-L6)\n",
+		},
+		{
+			name:  "Several kinds",
+			kinds: []string{"Function", "Struct"},
+			expected: "Namespace ns (L1-L21)\n" +
+				"  Struct Config (L12-L16)\n" +
+				"Function main (L23-L26)\n",
+		},
+		{
+			name:     "No matches",
+			kinds:    []string{"Enum"},
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			kinds, err := parseSymbolKinds(tc.kinds)
+			assert.NoError(t, err)
+
+			var output strings.Builder
+			writeSymbolTree(&output, filterSymbolTree(buildSymbolTree(symbols), kinds), 0)
+			assert.Equal(t, tc.expected, output.String())
+		})
+	}
+}
+
+func TestParseSymbolKindsUnknown(t *testing.T) {
+	_, err := parseSymbolKinds([]string{"Method", "Gadget"})
+	assert.ErrorContains(t, err, `unknown symbol kind "Gadget"`)
+}
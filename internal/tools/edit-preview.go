@@ -0,0 +1,146 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// previewWorkspaceEdit renders the changes a workspace edit would make as a unified
+// diff per file, using the current file contents. Nothing is written to disk.
+func previewWorkspaceEdit(edit protocol.WorkspaceEdit) (string, error) {
+	var notes []string
+	var order []string
+	original := make(map[string][]byte)
+	updated := make(map[string][]byte)
+
+	// content returns the content of path as changed by the edit so far
+	content := func(path string) ([]byte, error) {
+		if content, ok := updated[path]; ok {
+			return content, nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %v", err)
+		}
+		original[path] = content
+		updated[path] = content
+		order = append(order, path)
+		return content, nil
+	}
+
+	applyEdits := func(uri protocol.DocumentUri, edits []protocol.TextEdit) error {
+		path := uriToPath(uri)
+		content, err := content(path)
+		if err != nil {
+			return err
+		}
+
+		newContent, err := utilities.ApplyTextEditsToContent(content, edits)
+		if err != nil {
+			return fmt.Errorf("failed to apply edits to %s: %v", path, err)
+		}
+		updated[path] = newContent
+		return nil
+	}
+
+	// Changes is ignored when there are DocumentChanges
+	if len(edit.DocumentChanges) == 0 {
+		for uri, edits := range edit.Changes {
+			if err := applyEdits(uri, edits); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	for _, change := range edit.DocumentChanges {
+		switch {
+		case change.TextDocumentEdit != nil:
+			edits := make([]protocol.TextEdit, len(change.TextDocumentEdit.Edits))
+			for i, e := range change.TextDocumentEdit.Edits {
+				var err error
+				edits[i], err = e.AsTextEdit()
+				if err != nil {
+					return "", fmt.Errorf("invalid edit type: %v", err)
+				}
+			}
+			if err := applyEdits(change.TextDocumentEdit.TextDocument.URI, edits); err != nil {
+				return "", err
+			}
+		case change.CreateFile != nil:
+			// Later edits to the new file start from an empty file
+			path := uriToPath(change.CreateFile.URI)
+			if _, ok := updated[path]; !ok {
+				original[path] = nil
+				order = append(order, path)
+			}
+			updated[path] = nil
+			notes = append(notes, fmt.Sprintf("Would create %s", change.CreateFile.URI.Path()))
+		case change.RenameFile != nil:
+			// Later edits to the new path apply to the renamed content, and its
+			// diff is shown under the new path
+			oldPath, newPath := uriToPath(change.RenameFile.OldURI), uriToPath(change.RenameFile.NewURI)
+			renamed, err := content(oldPath)
+			if err != nil {
+				return "", err
+			}
+			original[newPath] = original[oldPath]
+			updated[newPath] = renamed
+			delete(original, oldPath)
+			delete(updated, oldPath)
+			order = slices.DeleteFunc(order, func(path string) bool { return path == oldPath || path == newPath })
+			order = append(order, newPath)
+			notes = append(notes, fmt.Sprintf("Would rename %s to %s", change.RenameFile.OldURI.Path(), change.RenameFile.NewURI.Path()))
+		case change.DeleteFile != nil:
+			notes = append(notes, fmt.Sprintf("Would delete %s", change.DeleteFile.URI.Path()))
+		}
+	}
+
+	sort.Strings(order)
+
+	var output strings.Builder
+	for _, path := range order {
+		diff, err := unifiedDiff(path, string(original[path]), string(updated[path]))
+		if err != nil {
+			return "", err
+		}
+		output.WriteString(diff)
+	}
+	for _, note := range notes {
+		output.WriteString(note + "\n")
+	}
+	return output.String(), nil
+}
+
+// unifiedDiff renders a unified diff of one file with 3 lines of context
+func unifiedDiff(path, before, after string) (string, error) {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        diffLines(before),
+		B:        diffLines(after),
+		FromFile: "a" + path,
+		ToFile:   "b" + path,
+		Context:  3,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to diff %s: %v", path, err)
+	}
+	return diff, nil
+}
+
+// diffLines splits text into lines that each end with a newline. Unlike
+// difflib.SplitLines it adds no empty line after a trailing newline.
+func diffLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if last := lines[len(lines)-1]; last == "" {
+		lines = lines[:len(lines)-1]
+	} else {
+		lines[len(lines)-1] = last + "\n"
+	}
+	return lines
+}
@@ -0,0 +1,107 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewWorkspaceEdit(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	content := "package main\n\nfunc old() {}\n\nfunc main() {\n\told()\n}\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	replace := func(line, start, end uint32) protocol.TextEdit {
+		return protocol.TextEdit{
+			Range: protocol.Range{
+				Start: protocol.Position{Line: line, Character: start},
+				End:   protocol.Position{Line: line, Character: end},
+			},
+			NewText: "renamed",
+		}
+	}
+
+	edit := protocol.WorkspaceEdit{
+		Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+			protocol.DocumentUri("file://" + path): {replace(2, 5, 8), replace(5, 1, 4)},
+		},
+	}
+
+	expected := "--- a" + path + "\n" +
+		"+++ b" + path + "\n" +
+		"@@ -1,7 +1,7 @@\n" +
+		" package main\n" +
+		" \n" +
+		"-func old() {}\n" +
+		"+func renamed() {}\n" +
+		" \n" +
+		" func main() {\n" +
+		"-\told()\n" +
+		"+\trenamed()\n" +
+		" }\n"
+
+	preview, err := previewWorkspaceEdit(edit)
+	require.NoError(t, err)
+	assert.Equal(t, expected, preview)
+
+	// Nothing is written to disk
+	after, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(after))
+}
+
+func TestPreviewWorkspaceEditRenameFile(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "Old.java")
+	newPath := filepath.Join(dir, "New.java")
+	content := "public class Old {}\n"
+	require.NoError(t, os.WriteFile(oldPath, []byte(content), 0644))
+
+	// The class is renamed after its file is moved, so the edit targets the new path
+	edit := protocol.WorkspaceEdit{
+		DocumentChanges: []protocol.DocumentChange{
+			{RenameFile: &protocol.RenameFile{
+				Kind:   "rename",
+				OldURI: protocol.DocumentUri("file://" + oldPath),
+				NewURI: protocol.DocumentUri("file://" + newPath),
+			}},
+			{TextDocumentEdit: &protocol.TextDocumentEdit{
+				TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
+					TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: protocol.DocumentUri("file://" + newPath)},
+				},
+				Edits: []protocol.Or_TextDocumentEdit_edits_Elem{
+					{Value: protocol.TextEdit{
+						Range: protocol.Range{
+							Start: protocol.Position{Line: 0, Character: 13},
+							End:   protocol.Position{Line: 0, Character: 16},
+						},
+						NewText: "New",
+					}},
+				},
+			}},
+		},
+	}
+
+	expected := "--- a" + newPath + "\n" +
+		"+++ b" + newPath + "\n" +
+		"@@ -1 +1 @@\n" +
+		"-public class Old {}\n" +
+		"+public class New {}\n" +
+		"Would rename " + oldPath + " to " + newPath + "\n"
+
+	preview, err := previewWorkspaceEdit(edit)
+	require.NoError(t, err)
+	assert.Equal(t, expected, preview)
+
+	// Nothing is moved on disk
+	_, err = os.Stat(newPath)
+	assert.True(t, os.IsNotExist(err))
+	after, err := os.ReadFile(oldPath)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(after))
+}
@@ -10,7 +10,6 @@ import (
 
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
-	"github.com/isaacphi/mcp-language-server/internal/utilities"
 )
 
 type TextEdit struct {
@@ -24,6 +23,7 @@ func ApplyTextEdits(ctx context.Context, client *lsp.Client, filePath string, ed
 	if err != nil {
 		return "", fmt.Errorf("could not open file: %v", err)
 	}
+	defer client.CloseFile(ctx, filePath)
 
 	// Create a sorted copy of edits for reporting
 	sortedEdits := make([]TextEdit, len(edits))
@@ -78,7 +78,7 @@ func ApplyTextEdits(ctx context.Context, client *lsp.Client, filePath string, ed
 		},
 	}
 
-	if err := utilities.ApplyWorkspaceEdit(edit); err != nil {
+	if err := applyWorkspaceEdit(ctx, client, edit); err != nil {
 		return "", fmt.Errorf("failed to apply text edits: %v", err)
 	}
 
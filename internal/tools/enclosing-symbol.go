@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// EnclosingSymbol returns the innermost symbol, such as a function or class, whose
+// range contains a position (1-indexed line and column), along with the symbols
+// containing it. A column of 0 matches any symbol spanning the line, for positions
+// that only have a line number, like stack trace entries.
+func EnclosingSymbol(ctx context.Context, client *lsp.Client, filePath string, line, character int) (string, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+	defer client.CloseFile(ctx, filePath)
+
+	symResult, err := client.DocumentSymbol(ctx, protocol.DocumentSymbolParams{
+		TextDocument: protocol.TextDocumentIdentifier{
+			URI: protocol.DocumentUri("file://" + filePath),
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get document symbols: %v", err)
+	}
+
+	symbols, err := symResult.Results()
+	if err != nil {
+		return "", fmt.Errorf("failed to process document symbols: %v", err)
+	}
+
+	chain := enclosingChain(buildSymbolTree(symbols), line, character)
+	return formatEnclosingSymbol(chain, filePath, line, character), nil
+}
+
+// enclosingChain returns the symbols containing a 1-indexed position, outermost
+// first. A character of 0 or less compares lines only.
+func enclosingChain(nodes []*symbolNode, line, character int) []*symbolNode {
+	var chain []*symbolNode
+	for {
+		var next *symbolNode
+		for _, node := range nodes {
+			if nodeContains(node, line, character) {
+				// Prefer the innermost of overlapping siblings
+				if next == nil || rangeContains(next.Range, node.Range) {
+					next = node
+				}
+			}
+		}
+		if next == nil {
+			return chain
+		}
+		chain = append(chain, next)
+		nodes = next.Children
+	}
+}
+
+// nodeContains reports whether a symbol's range contains a 1-indexed position
+func nodeContains(node *symbolNode, line, character int) bool {
+	if character <= 0 {
+		return int(node.Range.Start.Line)+1 <= line && line <= int(node.Range.End.Line)+1
+	}
+	return containsPosition(node.Range, protocol.Position{
+		Line:      uint32(line - 1),
+		Character: uint32(character - 1),
+	})
+}
+
+func formatEnclosingSymbol(chain []*symbolNode, filePath string, line, character int) string {
+	position := fmt.Sprintf("L%d", line)
+	if character > 0 {
+		position += fmt.Sprintf(":C%d", character)
+	}
+
+	if len(chain) == 0 {
+		return fmt.Sprintf("No symbol encloses %s %s", filePath, position)
+	}
+
+	describe := func(node *symbolNode) string {
+		return fmt.Sprintf("%s %s (L%d-L%d)",
+			protocol.SymbolKindName(node.Kind),
+			node.Name,
+			node.Range.Start.Line+1,
+			node.Range.End.Line+1,
+		)
+	}
+
+	var output strings.Builder
+	innermost := chain[len(chain)-1]
+	output.WriteString(fmt.Sprintf("Innermost symbol at %s %s: %s\n", filePath, position, describe(innermost)))
+	if innermost.Detail != "" {
+		output.WriteString(fmt.Sprintf("Detail: %s\n", innermost.Detail))
+	}
+	if len(chain) > 1 {
+		output.WriteString("Containers, outermost first:\n")
+		for _, node := range chain[:len(chain)-1] {
+			output.WriteString(fmt.Sprintf("  %s\n", describe(node)))
+		}
+	}
+	return output.String()
+}
@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnclosingChain(t *testing.T) {
+	span := func(startLine, startChar, endLine, endChar uint32) protocol.Range {
+		return protocol.Range{
+			Start: protocol.Position{Line: startLine, Character: startChar},
+			End:   protocol.Position{Line: endLine, Character: endChar},
+		}
+	}
+
+	method := &symbolNode{Name: "process", Kind: protocol.Method, Range: span(4, 4, 8, 5)}
+	class := &symbolNode{Name: "Worker", Kind: protocol.Class, Range: span(2, 2, 10, 3), Children: []*symbolNode{
+		{Name: "id", Kind: protocol.Field, Range: span(3, 4, 3, 12)},
+		method,
+	}}
+	namespace := &symbolNode{Name: "jobs", Kind: protocol.Namespace, Range: span(0, 0, 12, 1), Children: []*symbolNode{class}}
+	helper := &symbolNode{Name: "helper", Kind: protocol.Function, Range: span(14, 0, 16, 1)}
+	tree := []*symbolNode{namespace, helper}
+
+	testCases := []struct {
+		name      string
+		line      int
+		character int
+		expected  []*symbolNode
+	}{
+		{name: "Inside a method", line: 6, character: 9, expected: []*symbolNode{namespace, class, method}},
+		{name: "Line only", line: 5, character: 0, expected: []*symbolNode{namespace, class, method}},
+		{name: "Before the method on its first line", line: 5, character: 1, expected: []*symbolNode{namespace, class}},
+		{name: "Top level function", line: 16, character: 0, expected: []*symbolNode{helper}},
+		{name: "Between symbols", line: 14, character: 0, expected: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, enclosingChain(tree, tc.line, tc.character))
+		})
+	}
+}
+
+func TestFormatEnclosingSymbol(t *testing.T) {
+	chain := []*symbolNode{
+		{Name: "jobs", Kind: protocol.Namespace, Range: protocol.Range{End: protocol.Position{Line: 12}}},
+		{Name: "process", Detail: "func() error", Kind: protocol.Method, Range: protocol.Range{
+			Start: protocol.Position{Line: 4},
+			End:   protocol.Position{Line: 8},
+		}},
+	}
+
+	assert.Equal(t,
+		"Innermost symbol at /src/jobs.go L6:C9: Method process (L5-L9)\n"+
+			"Detail: func() error\n"+
+			"Containers, outermost first:\n"+
+			"  Namespace jobs (L1-L13)\n",
+		formatEnclosingSymbol(chain, "/src/jobs.go", 6, 9))
+	assert.Equal(t, "No symbol encloses /src/jobs.go L14", formatEnclosingSymbol(nil, "/src/jobs.go", 14, 0))
+}
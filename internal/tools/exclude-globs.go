@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"os"
+	"path"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// excludeGlobsFromEnv returns the comma separated globs in LSP_EXCLUDE_GLOBS
+func excludeGlobsFromEnv() []string {
+	var globs []string
+	for _, glob := range strings.Split(os.Getenv("LSP_EXCLUDE_GLOBS"), ",") {
+		if glob = strings.TrimSpace(glob); glob != "" {
+			globs = append(globs, glob)
+		}
+	}
+	return globs
+}
+
+// matchesGlob reports whether a slash separated path matches a glob. The glob is
+// matched against every run of consecutive path components, so "*_test.go" matches
+// test files in any directory and "third_party" or "third_party/**" matches
+// everything below a third_party directory.
+func matchesGlob(filePath, glob string) bool {
+	glob = strings.TrimSuffix(strings.TrimSuffix(glob, "/**"), "/")
+	glob = strings.TrimPrefix(glob, "/")
+	if glob == "" {
+		return false
+	}
+
+	components := strings.Split(strings.TrimPrefix(filePath, "/"), "/")
+	for start := range components {
+		for end := start + 1; end <= len(components); end++ {
+			if ok, _ := path.Match(glob, strings.Join(components[start:end], "/")); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// excludeLocations drops the locations in files matching any of the globs. It
+// returns the kept locations and the number of distinct files excluded.
+func excludeLocations(locations []protocol.Location, globs []string) ([]protocol.Location, int) {
+	if len(globs) == 0 {
+		return locations, 0
+	}
+
+	var kept []protocol.Location
+	excludedFiles := make(map[protocol.DocumentUri]bool)
+	for _, loc := range locations {
+		if excludedFiles[loc.URI] {
+			continue
+		}
+
+		filePath := uriToPath(loc.URI)
+		excluded := false
+		for _, glob := range globs {
+			if matchesGlob(filePath, glob) {
+				excluded = true
+				break
+			}
+		}
+
+		if excluded {
+			excludedFiles[loc.URI] = true
+			continue
+		}
+		kept = append(kept, loc)
+	}
+	return kept, len(excludedFiles)
+}
@@ -0,0 +1,57 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchesGlob(t *testing.T) {
+	testCases := []struct {
+		path     string
+		glob     string
+		expected bool
+	}{
+		{"/repo/pkg/server_test.go", "*_test.go", true},
+		{"/repo/pkg/server.go", "*_test.go", false},
+		{"/repo/api/types.generated.ts", "*.generated.*", true},
+		{"/repo/third_party/lib/x.go", "third_party", true},
+		{"/repo/third_party/lib/x.go", "third_party/**", true},
+		{"/repo/third_party/lib/x.go", "third_party/", true},
+		{"/repo/not_third_party/x.go", "third_party", false},
+		{"/repo/internal/foo/testdata/a.go", "internal/*/testdata", true},
+		{"/repo/internal/testdata/a.go", "internal/*/testdata", false},
+		{"/repo/main.go", "/repo", true},
+		{"/repo/main.go", "", false},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, matchesGlob(tc.path, tc.glob), "%s against %s", tc.path, tc.glob)
+	}
+}
+
+func TestExcludeLocations(t *testing.T) {
+	loc := func(uri string, line uint32) protocol.Location {
+		return protocol.Location{
+			URI:   protocol.DocumentUri(uri),
+			Range: protocol.Range{Start: protocol.Position{Line: line}},
+		}
+	}
+
+	locations := []protocol.Location{
+		loc("file:///repo/a.go", 1),
+		loc("file:///repo/a_test.go", 2),
+		loc("file:///repo/a_test.go", 3),
+		loc("file:///repo/vendor/b.go", 4),
+		loc("file:///repo/c.go", 5),
+	}
+
+	kept, excluded := excludeLocations(locations, []string{"*_test.go", "vendor"})
+	assert.Equal(t, []protocol.Location{loc("file:///repo/a.go", 1), loc("file:///repo/c.go", 5)}, kept)
+	assert.Equal(t, 2, excluded)
+
+	kept, excluded = excludeLocations(locations, nil)
+	assert.Equal(t, locations, kept)
+	assert.Zero(t, excluded)
+}
@@ -16,6 +16,7 @@ func ExecuteCodeLens(ctx context.Context, client *lsp.Client, filePath string, i
 	if err != nil {
 		return "", fmt.Errorf("could not open file: %v", err)
 	}
+	defer client.CloseFile(ctx, filePath)
 	// TODO: find a more appropriate way to wait
 	time.Sleep(time.Second)
 
@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// Caps on ExploreSymbol's output, which is meant as an overview. The definition and
+// references tools give the full results.
+const (
+	exploreMaxDefinitions     = 3
+	exploreMaxDefinitionLines = 80
+	exploreMaxReferenceFiles  = 10
+	exploreMaxRefsPerFile     = 5
+	exploreContextLines       = 1
+)
+
+// ExploreSymbol returns the definitions of a symbol followed by its references. The
+// symbol is looked up once and each file is opened once for both, so this is cheaper
+// than calling ReadDefinition and FindReferences separately. Output is capped to a
+// few definitions and the first files of references, with notes on what was left out.
+func ExploreSymbol(ctx context.Context, client *lsp.Client, symbolName string) (string, error) {
+	symbolName, err := NormalizeSymbolName(symbolName)
+	if err != nil {
+		return "", err
+	}
+
+	results, err := findSymbols(ctx, client, symbolName)
+	if err != nil {
+		return "", err
+	}
+
+	opened := make(map[protocol.DocumentUri]bool)
+	defer closeURIs(ctx, client, opened)
+	definitions, found, err := readSymbolDefinitions(ctx, client, symbolName, results, DefinitionOptions{
+		MaxResults: exploreMaxDefinitions,
+		MaxLines:   exploreMaxDefinitionLines,
+	}, opened)
+	if err != nil {
+		return "", fmt.Errorf("failed to read definition: %v", err)
+	}
+	if !found && outputFormat() != OutputFormatJSON {
+		return definitions, nil
+	}
+
+	references, err := findSymbolReferences(ctx, client, symbolName, results, ReferenceOptions{
+		ContextLines:   exploreContextLines,
+		MaxFiles:       exploreMaxReferenceFiles,
+		MaxRefsPerFile: exploreMaxRefsPerFile,
+	}, opened)
+	if err != nil {
+		return "", fmt.Errorf("failed to find references: %v", err)
+	}
+
+	if outputFormat() == OutputFormatJSON {
+		return formatJSON(ExploreResult{
+			Definitions: json.RawMessage(definitions),
+			References:  json.RawMessage(references),
+		})
+	}
+
+	return fmt.Sprintf("=== Definition ===\n\n%s\n=== References ===\n\n%s", definitions, references), nil
+}
@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// externalMarker is appended to the file of definitions outside the workspace,
+// such as the standard library, the module cache or system headers
+const externalMarker = " [external]"
+
+// isFileURI reports whether uri is a file: URI. Some servers point into archives
+// or generated sources with schemes such as jdt:// or zip:, which can't be read
+// from disk.
+func isFileURI(uri protocol.DocumentUri) bool {
+	return strings.HasPrefix(string(uri), "file:")
+}
+
+// openURI opens the document at uri on the server. Only file: URIs are opened, since
+// documents with other schemes belong to the server and can't be read from disk.
+func openURI(ctx context.Context, client *lsp.Client, uri protocol.DocumentUri) error {
+	if !isFileURI(uri) {
+		return nil
+	}
+	return client.OpenFile(ctx, uriToPath(uri))
+}
+
+// closeURI releases a document opened with openURI
+func closeURI(ctx context.Context, client *lsp.Client, uri protocol.DocumentUri) {
+	if !isFileURI(uri) {
+		return
+	}
+	if err := client.CloseFile(ctx, uriToPath(uri)); err != nil {
+		toolsLogger.Warn("Failed to close %s: %v", uri, err)
+	}
+}
+
+// closeURIs releases the documents recorded in opened by the tools that share one
+// set of opened files across several lookups
+func closeURIs(ctx context.Context, client *lsp.Client, opened map[protocol.DocumentUri]bool) {
+	for uri := range opened {
+		closeURI(ctx, client, uri)
+	}
+}
+
+// isOutsideDir reports whether path lies outside dir, after resolving symlinks
+func isOutsideDir(dir, path string) bool {
+	rel, err := filepath.Rel(canonicalPath(dir), canonicalPath(path))
+	if err != nil {
+		return true
+	}
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// externalSuffix returns externalMarker for definitions outside every workspace
+// folder
+func externalSuffix(client *lsp.Client, uri protocol.DocumentUri) string {
+	if !isFileURI(uri) {
+		return externalMarker
+	}
+	folders := client.WorkspaceFolders()
+	for _, folder := range folders {
+		if !isOutsideDir(folder, uriToPath(uri)) {
+			return ""
+		}
+	}
+	if len(folders) > 0 {
+		return externalMarker
+	}
+	return ""
+}
+
+// virtualDefinition returns the source of a definition in a document that is not on
+// disk, fetched from the server, or its hover content if the server can't provide
+// the document
+func virtualDefinition(ctx context.Context, client *lsp.Client, loc protocol.Location) (string, error) {
+	if definition, _, err := GetFullDefinition(ctx, client, loc); err == nil {
+		return definition, nil
+	}
+	return hoverDefinition(ctx, client, loc)
+}
+
+// hoverDefinition describes a definition that can't be read from disk by its
+// hover content, which usually includes the signature and documentation
+func hoverDefinition(ctx context.Context, client *lsp.Client, loc protocol.Location) (string, error) {
+	hover, err := client.Hover(ctx, protocol.HoverParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: loc.URI},
+			Position:     loc.Range.Start,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get hover for %s: %v", loc.URI, err)
+	}
+	return formatMarkupContent(hover.Contents), nil
+}
@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsFileURI(t *testing.T) {
+	assert.True(t, isFileURI("file:///src/main.go"))
+	assert.True(t, isFileURI("file://src/main.go"))
+	assert.False(t, isFileURI("jdt://contents/rt.jar/java.lang/String.class"))
+	assert.False(t, isFileURI("zip:/deps/lib.jar::Foo.java"))
+	assert.False(t, isFileURI(protocol.DocumentUri("")))
+}
+
+func TestIsOutsideDir(t *testing.T) {
+	dir := t.TempDir()
+	workspace := filepath.Join(dir, "workspace")
+	require.NoError(t, os.MkdirAll(filepath.Join(workspace, "pkg"), 0755))
+	// A symlink inside the workspace that points outside of it
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "vendor"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "vendor", "lib.go"), []byte("package lib\n"), 0644))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "vendor"), filepath.Join(workspace, "vendor")))
+
+	testCases := []struct {
+		name     string
+		path     string
+		expected bool
+	}{
+		{name: "Workspace file", path: filepath.Join(workspace, "pkg", "main.go"), expected: false},
+		{name: "Workspace root", path: workspace, expected: false},
+		{name: "Sibling directory with a common prefix", path: workspace + "-other/main.go", expected: true},
+		{name: "Module cache", path: "/root/go/pkg/mod/golang.org/x/tools/go.mod", expected: true},
+		{name: "Symlink out of the workspace", path: filepath.Join(workspace, "vendor", "lib.go"), expected: true},
+		{name: "Dotted file name", path: filepath.Join(workspace, "..hidden"), expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, isOutsideDir(workspace, tc.path))
+		})
+	}
+}
+
+func TestOpenURISkipsNonFileURIs(t *testing.T) {
+	// Non-file documents belong to the server and are never opened from disk
+	assert.NoError(t, openURI(context.Background(), nil, "jdt://contents/rt.jar/java.lang/String.class"))
+}
@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// GetFoldingRanges lists the foldable regions of a file. With collapse set, it
+// instead prints the file with each outermost region folded into a single summary
+// line, giving a condensed overview of large files.
+func GetFoldingRanges(ctx context.Context, client *lsp.Client, filePath string, collapse bool) (string, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+	defer client.CloseFile(ctx, filePath)
+
+	ranges, err := client.FoldingRange(ctx, protocol.FoldingRangeParams{
+		TextDocument: protocol.TextDocumentIdentifier{
+			URI: protocol.DocumentUri("file://" + filePath),
+		},
+	})
+	if err != nil {
+		if isMethodNotSupported(err) {
+			return "The language server does not support folding ranges.", nil
+		}
+		return "", fmt.Errorf("failed to get folding ranges: %v", err)
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].StartLine != ranges[j].StartLine {
+			return ranges[i].StartLine < ranges[j].StartLine
+		}
+		return ranges[i].EndLine > ranges[j].EndLine
+	})
+
+	if collapse {
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read file: %v", err)
+		}
+		lines := splitLines(content)
+		return collapseFoldingRanges(lines, ranges), nil
+	}
+
+	if len(ranges) == 0 {
+		return fmt.Sprintf("No folding ranges found in %s", filePath), nil
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Folding ranges in %s:\n\n", filePath))
+	for _, r := range ranges {
+		kind := r.Kind
+		if kind == "" {
+			kind = "region"
+		}
+		output.WriteString(fmt.Sprintf("L%d-L%d %s\n", r.StartLine+1, r.EndLine+1, kind))
+	}
+	return output.String(), nil
+}
+
+// collapseFoldingRanges renders numbered lines with each outermost folding range
+// replaced by its first line followed by "... N lines". ranges must be sorted by
+// start line.
+func collapseFoldingRanges(lines []string, ranges []protocol.FoldingRange) string {
+	padding := len(strconv.Itoa(len(lines)))
+	writeLine := func(output *strings.Builder, lineNum int, text string) {
+		num := strconv.Itoa(lineNum)
+		output.WriteString(fmt.Sprintf("%s%s|%s\n", strings.Repeat(" ", padding-len(num)), num, text))
+	}
+
+	var output strings.Builder
+	next := 0
+	for i := 0; i < len(lines); i++ {
+		// Skip ranges that start before this line, they are nested in a folded range
+		for next < len(ranges) && int(ranges[next].StartLine) < i {
+			next++
+		}
+
+		if next < len(ranges) && int(ranges[next].StartLine) == i && ranges[next].EndLine > ranges[next].StartLine {
+			end := min(int(ranges[next].EndLine), len(lines)-1)
+			writeLine(&output, i+1, fmt.Sprintf("%s ... %d lines", lines[i], end-i))
+			i = end
+			continue
+		}
+
+		writeLine(&output, i+1, lines[i])
+	}
+	return output.String()
+}
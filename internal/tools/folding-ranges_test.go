@@ -0,0 +1,34 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollapseFoldingRanges(t *testing.T) {
+	lines := []string{
+		"import (",
+		"\t\"fmt\"",
+		"\t\"os\"",
+		")",
+		"",
+		"func main() {",
+		"\tif true {",
+		"\t\tfmt.Println()",
+		"\t}",
+		"}",
+	}
+	ranges := []protocol.FoldingRange{
+		{StartLine: 0, EndLine: 3, Kind: "imports"},
+		{StartLine: 5, EndLine: 9},
+		// Nested inside main, folded along with it
+		{StartLine: 6, EndLine: 8},
+	}
+
+	expected := " 1|import ( ... 3 lines\n" +
+		" 5|\n" +
+		" 6|func main() { ... 4 lines\n"
+	assert.Equal(t, expected, collapseFoldingRanges(lines, ranges))
+}
@@ -0,0 +1,50 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// defaultFormattingOptions are sent with formatting requests. Most servers defer to
+// the project's own formatter configuration when one exists.
+var defaultFormattingOptions = protocol.FormattingOptions{
+	TabSize:      4,
+	InsertSpaces: false,
+}
+
+// FormatDocument formats a file with the language server and writes the result to disk
+func FormatDocument(ctx context.Context, client *lsp.Client, filePath string) (string, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+	defer client.CloseFile(ctx, filePath)
+
+	uri := protocol.DocumentUri("file://" + filePath)
+	edits, err := client.Formatting(ctx, protocol.DocumentFormattingParams{
+		TextDocument: protocol.TextDocumentIdentifier{
+			URI: uri,
+		},
+		Options: defaultFormattingOptions,
+	})
+	if err != nil {
+		if isMethodNotSupported(err) {
+			return "The language server does not support formatting.", nil
+		}
+		return "", fmt.Errorf("failed to format document: %v", err)
+	}
+
+	if len(edits) == 0 {
+		return fmt.Sprintf("No formatting changes needed. %s is already formatted.", filePath), nil
+	}
+
+	// Edits are applied from the bottom of the file up so earlier offsets stay valid
+	if err := applyTextEdits(ctx, client, uri, edits); err != nil {
+		return "", fmt.Errorf("failed to apply formatting edits: %v", err)
+	}
+
+	return fmt.Sprintf("Successfully formatted %s. Applied %d edits.", filePath, len(edits)), nil
+}
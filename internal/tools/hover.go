@@ -16,6 +16,7 @@ func GetHoverInfo(ctx context.Context, client *lsp.Client, filePath string, line
 	if err != nil {
 		return "", fmt.Errorf("could not open file: %v", err)
 	}
+	defer client.CloseFile(ctx, filePath)
 
 	params := protocol.HoverParams{}
 
@@ -64,3 +65,76 @@ func GetHoverInfo(ctx context.Context, client *lsp.Client, filePath string, line
 
 	return result.String(), nil
 }
+
+// Hover resolves a symbol by name and returns the hover information (type, documentation)
+// the language server reports at each matching location
+func Hover(ctx context.Context, client *lsp.Client, symbolName string) (string, error) {
+	symbolName, err := NormalizeSymbolName(symbolName)
+	if err != nil {
+		return "", err
+	}
+
+	results, err := findSymbols(ctx, client, symbolName)
+	if err != nil {
+		return "", err
+	}
+
+	var hovers []string
+	for _, symbol := range results {
+		loc := symbol.GetLocation()
+
+		err := openURI(ctx, client, loc.URI)
+		if err != nil {
+			toolsLogger.Error("Error opening file: %v", err)
+			continue
+		}
+		defer closeURI(ctx, client, loc.URI)
+
+		hoverResult, err := client.Hover(ctx, protocol.HoverParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{
+					URI: loc.URI,
+				},
+				Position: loc.Range.Start,
+			},
+		})
+		if err != nil {
+			toolsLogger.Error("Error getting hover: %v", err)
+			continue
+		}
+
+		banner := "---\n\n"
+		locationInfo := fmt.Sprintf(
+			"Symbol: %s\n"+
+				"File: %s\n"+
+				"Position: L%d:C%d\n\n",
+			symbol.GetName(),
+			uriToPath(loc.URI),
+			loc.Range.Start.Line+1,
+			loc.Range.Start.Character+1,
+		)
+
+		hovers = append(hovers, banner+locationInfo+formatMarkupContent(hoverResult.Contents)+"\n")
+	}
+
+	if len(hovers) == 0 {
+		return fmt.Sprintf("%s not found", symbolName), nil
+	}
+
+	return strings.Join(hovers, ""), nil
+}
+
+// formatMarkupContent renders hover contents. Markdown is returned as is, plaintext
+// is fenced so that its formatting is preserved.
+func formatMarkupContent(content protocol.MarkupContent) string {
+	value := strings.TrimSpace(content.Value)
+	if value == "" {
+		return "No hover information available for this symbol\n"
+	}
+
+	if content.Kind == protocol.PlainText {
+		return "```\n" + value + "\n```\n"
+	}
+
+	return value + "\n"
+}
@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// FindImplementations finds the concrete implementations of an interface, abstract
+// type or virtual method, grouped by file like FindReferences
+func FindImplementations(ctx context.Context, client *lsp.Client, symbolName string) (string, error) {
+	symbolName, err := NormalizeSymbolName(symbolName)
+	if err != nil {
+		return "", err
+	}
+
+	contextLines := defaultContextLines()
+
+	results, err := findSymbols(ctx, client, symbolName)
+	if err != nil {
+		return "", err
+	}
+
+	var allImplementations, unreadable []string
+	for _, symbol := range results {
+		loc := symbol.GetLocation()
+
+		err := openURI(ctx, client, loc.URI)
+		if err != nil {
+			toolsLogger.Error("Error opening file: %v", err)
+			continue
+		}
+		defer closeURI(ctx, client, loc.URI)
+
+		implResult, err := client.Implementation(ctx, protocol.ImplementationParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{
+					URI: loc.URI,
+				},
+				Position: loc.Range.Start,
+			},
+		})
+		if err != nil {
+			if isMethodNotSupported(err) {
+				return "The language server does not support finding implementations.", nil
+			}
+			return "", fmt.Errorf("failed to get implementations: %v", err)
+		}
+
+		impls, err := implResult.Locations()
+		if err != nil {
+			return "", fmt.Errorf("failed to parse implementations: %v", err)
+		}
+
+		formatted, unreadableFiles := formatLocationsByFile(ctx, client, impls, nil, snippetOptions{contextLines: contextLines}, "Implementations")
+		allImplementations = append(allImplementations, formatted...)
+		unreadable = append(unreadable, unreadableFiles...)
+	}
+
+	note := unreadableNote(unreadable)
+	if len(allImplementations) == 0 {
+		if note != "" {
+			return fmt.Sprintf("No implementations found for symbol: %s\n%s", symbolName, note), nil
+		}
+		return fmt.Sprintf("No implementations found for symbol: %s", symbolName), nil
+	}
+
+	output := strings.Join(allImplementations, "\n")
+	if note != "" {
+		output += "\n---\n\n" + note + "\n"
+	}
+	return output, nil
+}
+
+// isMethodNotSupported reports whether a request failed because the server does
+// not implement the method, which it answers with a MethodNotFound error
+func isMethodNotSupported(err error) bool {
+	var responseErr *lsp.ResponseError
+	return errors.As(err, &responseErr) && responseErr.Code == int(protocol.MethodNotFound)
+}
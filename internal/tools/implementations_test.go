@@ -0,0 +1,52 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsMethodNotSupported(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "Method not found",
+			err:      &lsp.ResponseError{Code: -32601, Message: "Unhandled method textDocument/implementation"},
+			expected: true,
+		},
+		{
+			name:     "Wrapped by the client",
+			err:      fmt.Errorf("request failed: %w", &lsp.ResponseError{Code: -32601, Message: "method not found"}),
+			expected: true,
+		},
+		{
+			name:     "Wrapped after a restart",
+			err:      fmt.Errorf("%w, the language server was restarted", fmt.Errorf("request failed: %w", &lsp.ResponseError{Code: -32601})),
+			expected: true,
+		},
+		{
+			name: "Other error code",
+			err:  &lsp.ResponseError{Code: -32603, Message: "internal error"},
+		},
+		{
+			name: "Message mentioning not supported",
+			err:  &lsp.ResponseError{Code: -32602, Message: "position not supported in this file"},
+		},
+		{
+			name: "Plain error with the code in its text",
+			err:  errors.New("request failed: method not found (code: -32601)"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, isMethodNotSupported(tc.err))
+		})
+	}
+}
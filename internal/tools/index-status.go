@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// indexProbeQueries are broad workspace/symbol queries tried in order until one
+// returns symbols. Some servers answer nothing for the empty query, so common
+// letters are tried as well.
+var indexProbeQueries = []string{"", "a", "e"}
+
+// IndexStatus reports whether the server's symbol index appears populated, by sending
+// broad workspace/symbol queries. It bypasses the symbol cache so a freshly built
+// index is seen. An empty index usually means the server is still indexing, such as
+// clangd before its background index is loaded. Work the server reports with
+// $/progress, such as background indexing, is listed as well, along with the last
+// work it finished.
+func IndexStatus(ctx context.Context, client *lsp.Client) (string, error) {
+	var finished *lsp.ProgressState
+	if last, ok := client.LastProgress(); ok && last.Done {
+		finished = &last
+	}
+
+	for _, query := range indexProbeQueries {
+		symbolResult, err := client.Symbol(ctx, protocol.WorkspaceSymbolParams{Query: query})
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch symbols: %v", err)
+		}
+		results, err := symbolResult.Results()
+		if err != nil {
+			return "", fmt.Errorf("failed to parse results: %v", err)
+		}
+
+		if len(results) > 0 {
+			return formatIndexStatus(query, results, client.ActiveProgress(), finished), nil
+		}
+	}
+
+	return formatIndexStatus("", nil, client.ActiveProgress(), finished), nil
+}
+
+// formatIndexStatus describes the symbols a probe query returned, nil if none did,
+// the progress the server reports and the last progress it finished, if any
+func formatIndexStatus(query string, results []protocol.WorkspaceSymbolResult, progress []lsp.ProgressState, finished *lsp.ProgressState) string {
+	var output strings.Builder
+	if len(results) == 0 {
+		output.WriteString("Index: empty\n\n")
+		if len(progress) > 0 {
+			output.WriteString("No symbols were returned for broad workspace/symbol queries while the server reports work in progress. The index is still being built; try again shortly.\n")
+		} else {
+			output.WriteString("No symbols were returned for broad workspace/symbol queries. The index is probably still being built; try again shortly.\n")
+		}
+	} else {
+		files := make(map[protocol.DocumentUri]bool)
+		for _, symbol := range results {
+			files[symbol.GetLocation().URI] = true
+		}
+		output.WriteString("Index: populated\n\n")
+		output.WriteString(fmt.Sprintf("Query %q returned %d symbols from %d files.\n", query, len(results), len(files)))
+	}
+
+	if len(progress) > 0 {
+		output.WriteString("\nIn progress:\n")
+		for _, state := range progress {
+			line := "  " + state.Title
+			if state.Message != "" {
+				line += ": " + state.Message
+			}
+			if state.Percentage >= 0 {
+				line += fmt.Sprintf(" (%d%%)", state.Percentage)
+			}
+			output.WriteString(line + "\n")
+		}
+	}
+
+	if finished != nil {
+		line := finished.Title
+		if finished.Message != "" {
+			line += ": " + finished.Message
+		}
+		output.WriteString(fmt.Sprintf("\nLast finished: %s (%s ago)\n", line, time.Since(finished.UpdatedAt).Round(time.Second)))
+	}
+	return output.String()
+}
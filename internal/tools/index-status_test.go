@@ -0,0 +1,42 @@
+package tools
+
+import (
+	"testing"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatIndexStatus(t *testing.T) {
+	symbol := func(name, uri string) protocol.WorkspaceSymbolResult {
+		return &protocol.SymbolInformation{
+			Name:     name,
+			Location: protocol.Location{URI: protocol.DocumentUri(uri)},
+		}
+	}
+
+	output := formatIndexStatus("", []protocol.WorkspaceSymbolResult{
+		symbol("main", "file:///src/main.cpp"),
+		symbol("helper", "file:///src/main.cpp"),
+		symbol("Widget", "file:///src/widget.h"),
+	}, nil, nil)
+	assert.Equal(t, "Index: populated\n\nQuery \"\" returned 3 symbols from 2 files.\n", output)
+}
+
+func TestFormatIndexStatusInProgress(t *testing.T) {
+	output := formatIndexStatus("", nil, []lsp.ProgressState{
+		{Title: "indexing", Message: "12/40", Percentage: 30},
+		{Title: "Loading workspace", Percentage: -1},
+	}, nil)
+	assert.Contains(t, output, "Index: empty\n\n")
+	assert.Contains(t, output, "while the server reports work in progress")
+	assert.Contains(t, output, "\nIn progress:\n  indexing: 12/40 (30%)\n  Loading workspace\n")
+}
+
+func TestFormatIndexStatusLastFinished(t *testing.T) {
+	finished := &lsp.ProgressState{Title: "indexing", Message: "done", Percentage: -1, Done: true, UpdatedAt: time.Now().Add(-90 * time.Second)}
+	output := formatIndexStatus("", nil, nil, finished)
+	assert.Contains(t, output, "\nLast finished: indexing: done (1m30s ago)\n")
+}
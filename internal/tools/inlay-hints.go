@@ -0,0 +1,116 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// GetInlayHints renders the lines startLine to endLine (1-indexed, inclusive) of a
+// file with the language server's inlay hints, such as inferred types and parameter
+// names, inserted into the source text
+func GetInlayHints(ctx context.Context, client *lsp.Client, filePath string, startLine, endLine int) (string, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+	defer client.CloseFile(ctx, filePath)
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	lines := splitLines(content)
+
+	if startLine < 1 {
+		startLine = 1
+	}
+	if endLine < startLine || endLine > len(lines) {
+		endLine = len(lines)
+	}
+
+	hints, err := client.InlayHint(ctx, protocol.InlayHintParams{
+		TextDocument: protocol.TextDocumentIdentifier{
+			URI: protocol.DocumentUri("file://" + filePath),
+		},
+		Range: protocol.Range{
+			Start: protocol.Position{Line: uint32(startLine - 1)},
+			End:   protocol.Position{Line: uint32(endLine)},
+		},
+	})
+	if err != nil {
+		if isMethodNotSupported(err) {
+			return "The language server does not support inlay hints.", nil
+		}
+		return "", fmt.Errorf("failed to get inlay hints: %v", err)
+	}
+
+	annotated := inlineHints(lines[startLine-1:endLine], startLine-1, hints)
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("%s with %d inlay hints (L%d-L%d):\n\n", filePath, len(hints), startLine, endLine))
+	output.WriteString(addLineNumbers(strings.Join(annotated, "\n"), startLine))
+	return output.String(), nil
+}
+
+// inlineHints returns a copy of lines, which start at the 0-indexed firstLine, with
+// each hint's label inserted at its position
+func inlineHints(lines []string, firstLine int, hints []protocol.InlayHint) []string {
+	annotated := make([]string, len(lines))
+	copy(annotated, lines)
+
+	// Insert from the end of each line backwards so earlier offsets stay valid. Hints
+	// at the same position are reversed first so they end up in their original order.
+	sorted := make([]protocol.InlayHint, len(hints))
+	for i, hint := range hints {
+		sorted[len(hints)-1-i] = hint
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Position.Line != sorted[j].Position.Line {
+			return sorted[i].Position.Line < sorted[j].Position.Line
+		}
+		return sorted[i].Position.Character > sorted[j].Position.Character
+	})
+
+	for _, hint := range sorted {
+		index := int(hint.Position.Line) - firstLine
+		if index < 0 || index >= len(annotated) {
+			continue
+		}
+		line := annotated[index]
+		col := min(int(hint.Position.Character), len(line))
+
+		label := inlayHintLabel(hint.Label)
+		if hint.PaddingLeft {
+			label = " " + label
+		}
+		if hint.PaddingRight {
+			label += " "
+		}
+
+		annotated[index] = line[:col] + label + line[col:]
+	}
+
+	return annotated
+}
+
+// inlayHintLabel returns the text of a hint label, which is either a string or a
+// list of label parts
+func inlayHintLabel(label protocol.Or_InlayHint_label) string {
+	switch v := label.Value.(type) {
+	case string:
+		return v
+	case []protocol.InlayHintLabelPart:
+		var text strings.Builder
+		for _, part := range v {
+			text.WriteString(part.Value)
+		}
+		return text.String()
+	}
+	return ""
+}
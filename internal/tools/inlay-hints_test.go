@@ -0,0 +1,40 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInlineHints(t *testing.T) {
+	lines := []string{
+		"x := compute(1, true)",
+		"for i, v := range items {",
+	}
+
+	hint := func(line, character uint32, label any, paddingLeft, paddingRight bool) protocol.InlayHint {
+		return protocol.InlayHint{
+			Position:     protocol.Position{Line: line, Character: character},
+			Label:        protocol.Or_InlayHint_label{Value: label},
+			PaddingLeft:  paddingLeft,
+			PaddingRight: paddingRight,
+		}
+	}
+
+	hints := []protocol.InlayHint{
+		hint(10, 1, " int", false, false),
+		hint(10, 13, "n:", false, true),
+		hint(10, 16, []protocol.InlayHintLabelPart{{Value: "verbose"}, {Value: ":"}}, false, true),
+		hint(11, 5, " int", false, false),
+		hint(11, 8, " string", false, false),
+		// Outside the requested lines
+		hint(12, 0, "ignored", false, false),
+	}
+
+	expected := []string{
+		"x int := compute(n: 1, verbose: true)",
+		"for i int, v string := range items {",
+	}
+	assert.Equal(t, expected, inlineHints(lines, 10, hints))
+}
@@ -0,0 +1,165 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// Anchors for InsertNearSymbol
+const (
+	// InsertBefore inserts on new lines before the definition, e.g. a doc comment
+	InsertBefore = "before"
+	// InsertAfter inserts on new lines after the definition
+	InsertAfter = "after"
+	// InsertBodyStart inserts on new lines after the opening brace of the body, or
+	// after the first line ending with ':' for languages such as Python
+	InsertBodyStart = "body-start"
+	// InsertBodyEnd inserts on new lines before the closing brace of the body, or
+	// after the definition if it has no braces
+	InsertBodyEnd = "body-end"
+)
+
+// insertContextLines is the number of lines shown around inserted text
+const insertContextLines = 2
+
+// InsertNearSymbol inserts text at an anchor relative to a symbol's definition and
+// returns the inserted lines with some context. The text is inserted as is, so it
+// should carry its own indentation. If several symbols share the name, matchIndex
+// (1-indexed) picks one. With dryRun set, it returns a diff instead of writing.
+func InsertNearSymbol(ctx context.Context, client *lsp.Client, symbolName, text, position string, matchIndex int, dryRun bool) (string, error) {
+	symbolName, err := NormalizeSymbolName(symbolName)
+	if err != nil {
+		return "", err
+	}
+
+	switch position {
+	case InsertBefore, InsertAfter, InsertBodyStart, InsertBodyEnd:
+	default:
+		return "", fmt.Errorf("invalid position %q, expected one of %s, %s, %s or %s", position, InsertBefore, InsertAfter, InsertBodyStart, InsertBodyEnd)
+	}
+
+	symbol, err := resolveSymbol(ctx, client, symbolName, matchIndex)
+	if errors.Is(err, errSymbolNotFound) {
+		return fmt.Sprintf("%s not found", symbolName), nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	loc := symbol.GetLocation()
+	filePath := loc.URI.Path()
+	if err := client.OpenFile(ctx, filePath); err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+	defer client.CloseFile(ctx, filePath)
+
+	_, fullLoc, err := GetFullDefinition(ctx, client, loc)
+	if err != nil {
+		return "", fmt.Errorf("failed to get definition: %v", err)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	edit, err := insertionEdit(splitLines(content), fullLoc.Range, text, position)
+	if err != nil {
+		return "", err
+	}
+
+	workspaceEdit := protocol.WorkspaceEdit{
+		Changes: map[protocol.DocumentUri][]protocol.TextEdit{loc.URI: {edit}},
+	}
+
+	if dryRun {
+		diff, err := previewWorkspaceEdit(workspaceEdit)
+		if err != nil {
+			return "", fmt.Errorf("failed to preview changes: %v", err)
+		}
+		return fmt.Sprintf("Dry run: would insert %s %s in %s. No files were changed.\n\n%s", position, symbolName, filePath, diff), nil
+	}
+
+	if err := applyWorkspaceEdit(ctx, client, workspaceEdit); err != nil {
+		return "", fmt.Errorf("failed to apply changes: %v", err)
+	}
+
+	content, err = os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	lines := splitLines(content)
+
+	// Show the lines the edit touched with a little context
+	linesToShow := make(map[int]bool)
+	first := int(edit.Range.Start.Line)
+	last := first + strings.Count(edit.NewText, "\n")
+	for i := first - insertContextLines; i <= last+insertContextLines; i++ {
+		linesToShow[i] = true
+	}
+
+	return fmt.Sprintf("Inserted text %s %s in %s:\n\n%s", position, symbolName, filePath,
+		FormatLinesWithRanges(lines, ConvertLinesToRanges(linesToShow, len(lines)))), nil
+}
+
+// insertionEdit returns an edit inserting text on its own lines at an anchor of a
+// definition range
+func insertionEdit(lines []string, definition protocol.Range, text, position string) (protocol.TextEdit, error) {
+	startLine := int(definition.Start.Line)
+	endLine := int(definition.End.Line)
+	if endLine >= len(lines) || startLine > endLine {
+		return protocol.TextEdit{}, fmt.Errorf("definition at lines %d-%d is outside the file", startLine+1, endLine+1)
+	}
+	text = strings.TrimSuffix(text, "\n")
+
+	// Insert text as new lines before a line, or after a column of a line
+	before := func(line int) protocol.TextEdit {
+		pos := protocol.Position{Line: uint32(line)}
+		return protocol.TextEdit{Range: protocol.Range{Start: pos, End: pos}, NewText: text + "\n"}
+	}
+	after := func(line, col int) protocol.TextEdit {
+		pos := protocol.Position{Line: uint32(line), Character: uint32(col)}
+		return protocol.TextEdit{Range: protocol.Range{Start: pos, End: pos}, NewText: "\n" + text}
+	}
+
+	switch position {
+	case InsertBefore:
+		return before(startLine), nil
+
+	case InsertAfter:
+		return after(endLine, len(lines[endLine])), nil
+
+	case InsertBodyStart:
+		for i := startLine; i <= endLine; i++ {
+			if col := strings.Index(lines[i], "{"); col >= 0 {
+				return after(i, col+1), nil
+			}
+			if strings.HasSuffix(strings.TrimSpace(lines[i]), ":") {
+				return after(i, len(lines[i])), nil
+			}
+		}
+		return protocol.TextEdit{}, fmt.Errorf("could not find the start of the body")
+
+	case InsertBodyEnd:
+		for i := endLine; i >= startLine; i-- {
+			col := strings.LastIndex(lines[i], "}")
+			if col < 0 {
+				continue
+			}
+			// A closing brace on its own line gets the text on the lines above it
+			if strings.TrimSpace(lines[i][:col]) == "" {
+				return before(i), nil
+			}
+			pos := protocol.Position{Line: uint32(i), Character: uint32(col)}
+			return protocol.TextEdit{Range: protocol.Range{Start: pos, End: pos}, NewText: "\n" + text + "\n"}, nil
+		}
+		return after(endLine, len(lines[endLine])), nil
+	}
+
+	return protocol.TextEdit{}, fmt.Errorf("invalid position %q", position)
+}
@@ -0,0 +1,105 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertionEdit(t *testing.T) {
+	goContent := "package main\n\nfunc add(a, b int) int {\n\treturn a + b\n}\n"
+	goDefinition := protocol.Range{
+		Start: protocol.Position{Line: 2},
+		End:   protocol.Position{Line: 4, Character: 1},
+	}
+	pyContent := "def add(a, b):\n    return a + b\n"
+	pyDefinition := protocol.Range{
+		End: protocol.Position{Line: 1, Character: 16},
+	}
+
+	testCases := []struct {
+		name       string
+		content    string
+		definition protocol.Range
+		text       string
+		position   string
+		expected   string
+	}{
+		{
+			name:       "Doc comment before",
+			content:    goContent,
+			definition: goDefinition,
+			text:       "// add returns the sum of a and b\n",
+			position:   InsertBefore,
+			expected:   "package main\n\n// add returns the sum of a and b\nfunc add(a, b int) int {\n\treturn a + b\n}\n",
+		},
+		{
+			name:       "After",
+			content:    goContent,
+			definition: goDefinition,
+			text:       "\nfunc sub(a, b int) int {\n\treturn a - b\n}",
+			position:   InsertAfter,
+			expected:   "package main\n\nfunc add(a, b int) int {\n\treturn a + b\n}\n\nfunc sub(a, b int) int {\n\treturn a - b\n}\n",
+		},
+		{
+			name:       "Body start",
+			content:    goContent,
+			definition: goDefinition,
+			text:       "\tlog.Println(a, b)",
+			position:   InsertBodyStart,
+			expected:   "package main\n\nfunc add(a, b int) int {\n\tlog.Println(a, b)\n\treturn a + b\n}\n",
+		},
+		{
+			name:       "Body end",
+			content:    "package main\n\nfunc run() {\n\tstart()\n}\n",
+			definition: goDefinition,
+			text:       "\tstop()",
+			position:   InsertBodyEnd,
+			expected:   "package main\n\nfunc run() {\n\tstart()\n\tstop()\n}\n",
+		},
+		{
+			name:       "Body end on the same line",
+			content:    "package main\n\nfunc run() { start() }\n",
+			definition: protocol.Range{Start: protocol.Position{Line: 2}, End: protocol.Position{Line: 2, Character: 22}},
+			text:       "stop()",
+			position:   InsertBodyEnd,
+			expected:   "package main\n\nfunc run() { start() \nstop()\n}\n",
+		},
+		{
+			name:       "Python body start",
+			content:    pyContent,
+			definition: pyDefinition,
+			text:       "    \"\"\"Add two numbers.\"\"\"",
+			position:   InsertBodyStart,
+			expected:   "def add(a, b):\n    \"\"\"Add two numbers.\"\"\"\n    return a + b\n",
+		},
+		{
+			name:       "Python body end",
+			content:    pyContent,
+			definition: pyDefinition,
+			text:       "    # done",
+			position:   InsertBodyEnd,
+			expected:   "def add(a, b):\n    return a + b\n    # done\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			edit, err := insertionEdit(splitLines([]byte(tc.content)), tc.definition, tc.text, tc.position)
+			require.NoError(t, err)
+
+			result, err := utilities.ApplyTextEditsToContent([]byte(tc.content), []protocol.TextEdit{edit})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(result))
+		})
+	}
+}
+
+func TestInsertionEditNoBody(t *testing.T) {
+	lines := []string{"const answer = 42"}
+	_, err := insertionEdit(lines, protocol.Range{End: protocol.Position{Character: 17}}, "x", InsertBodyStart)
+	assert.ErrorContains(t, err, "could not find the start of the body")
+}
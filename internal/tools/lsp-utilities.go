@@ -3,12 +3,11 @@ package tools
 import (
 	"context"
 	"fmt"
-	"net/url"
-	"os"
 	"strings"
 
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
 )
 
 // Gets the full code block surrounding the start of the input location
@@ -59,20 +58,14 @@ func GetFullDefinition(ctx context.Context, client *lsp.Client, startLocation pr
 	found = searchSymbols(symbols)
 
 	if found {
-		// Convert URI to filesystem path
-		filePath, err := url.PathUnescape(strings.TrimPrefix(string(startLocation.URI), "file://"))
-		if err != nil {
-			return "", protocol.Location{}, fmt.Errorf("failed to unescape URI: %w", err)
-		}
-
 		// Read the file to get the full lines of the definition
 		// because we may have a start and end column
-		content, err := os.ReadFile(filePath)
+		content, err := client.ReadDocument(ctx, startLocation.URI)
 		if err != nil {
 			return "", protocol.Location{}, fmt.Errorf("failed to read file: %w", err)
 		}
 
-		lines := strings.Split(string(content), "\n")
+		lines := splitLines(content)
 
 		// Extend start to beginning of line
 		symbolRange.Start.Character = 0
@@ -142,9 +135,16 @@ func GetFullDefinition(ctx context.Context, client *lsp.Client, startLocation pr
 func GetLineRangesToDisplay(ctx context.Context, client *lsp.Client, locations []protocol.Location, totalLines int, contextLines int) (map[int]bool, error) {
 	// Set to track which lines need to be displayed
 	linesToShow := make(map[int]bool)
+	// Lines already handled, since references on the same line share their context
+	seenLines := make(map[int]bool)
 
 	// For each location, get its container and add relevant lines
 	for _, loc := range locations {
+		if seenLines[int(loc.Range.Start.Line)] {
+			continue
+		}
+		seenLines[int(loc.Range.Start.Line)] = true
+
 		// Use GetFullDefinition to find container
 		_, containerLoc, err := GetFullDefinition(ctx, client, loc)
 		if err != nil {
@@ -181,3 +181,121 @@ func GetLineRangesToDisplay(ctx context.Context, client *lsp.Client, locations [
 
 	return linesToShow, nil
 }
+
+// findSymbols looks up a symbol name with workspace/symbol. Results are cached by the
+// client for a short time since tools often query the same symbol repeatedly.
+func findSymbols(ctx context.Context, client *lsp.Client, symbolName string) ([]protocol.WorkspaceSymbolResult, error) {
+	symbolResult, err := client.CachedSymbol(ctx, protocol.WorkspaceSymbolParams{
+		Query: symbolName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch symbol: %v", err)
+	}
+
+	results, err := symbolResult.Results()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse results: %v", err)
+	}
+
+	// Some servers don't match qualified names such as "Foo::bar". Retry with the
+	// bare name and keep the symbols whose container matches the qualifier.
+	if len(results) == 0 {
+		if qualifier, name := splitQualifiedName(symbolName); qualifier != "" && name != "" {
+			toolsLogger.Debug("No symbols for %s, retrying with %s", symbolName, name)
+			fallback, err := findSymbols(ctx, client, name)
+			if err != nil {
+				return nil, err
+			}
+			results = filterByQualifier(fallback, qualifier)
+		}
+	}
+
+	return resolveSymbolLocations(ctx, client, results), nil
+}
+
+// resolveSymbolLocations fills in the ranges of workspace symbols the server
+// returned with only a URI, using workspaceSymbol/resolve. Symbols that fail to
+// resolve are kept as they are.
+func resolveSymbolLocations(ctx context.Context, client *lsp.Client, results []protocol.WorkspaceSymbolResult) []protocol.WorkspaceSymbolResult {
+	if !supportsSymbolResolve(client.Capabilities()) {
+		return results
+	}
+
+	// Results may be shared with the symbol cache, so resolved symbols go in a copy
+	resolved := make([]protocol.WorkspaceSymbolResult, len(results))
+	for i, result := range results {
+		resolved[i] = result
+		symbol, ok := result.(*protocol.WorkspaceSymbol)
+		if !ok || symbol.HasRange() {
+			continue
+		}
+
+		full, err := client.ResolveWorkspaceSymbol(ctx, *symbol)
+		if err != nil {
+			toolsLogger.Warn("Failed to resolve location of %s: %v", symbol.Name, err)
+			continue
+		}
+		resolved[i] = &full
+	}
+	return resolved
+}
+
+// supportsSymbolResolve reports whether the server handles workspaceSymbol/resolve
+func supportsSymbolResolve(capabilities protocol.ServerCapabilities) bool {
+	if capabilities.WorkspaceSymbolProvider == nil {
+		return false
+	}
+	options, ok := capabilities.WorkspaceSymbolProvider.Value.(protocol.WorkspaceSymbolOptions)
+	return ok && options.ResolveProvider
+}
+
+// applyWorkspaceEdit writes a workspace edit to disk and sends the resulting content
+// of each edited document to the server, so open documents stay in sync when several
+// edits are chained. Documents
+// renamed or deleted by the edit are closed.
+func applyWorkspaceEdit(ctx context.Context, client *lsp.Client, edit protocol.WorkspaceEdit) error {
+	if err := utilities.ApplyWorkspaceEdit(edit); err != nil {
+		return err
+	}
+	defer client.InvalidateSymbolCache()
+
+	// Changes is ignored when there are DocumentChanges
+	if len(edit.DocumentChanges) == 0 {
+		for uri := range edit.Changes {
+			if err := client.SyncEdits(ctx, uri); err != nil {
+				toolsLogger.Warn("Failed to sync edits of %s with the language server: %v", uri, err)
+			}
+		}
+	}
+	for _, change := range edit.DocumentChanges {
+		var removed protocol.DocumentUri
+		switch {
+		case change.RenameFile != nil:
+			removed = change.RenameFile.OldURI
+		case change.DeleteFile != nil:
+			removed = change.DeleteFile.URI
+		}
+		if removed != "" {
+			if err := client.CloseRemovedFile(ctx, uriToPath(removed)); err != nil {
+				toolsLogger.Warn("Failed to close %s: %v", removed, err)
+			}
+			continue
+		}
+
+		if change.TextDocumentEdit == nil {
+			continue
+		}
+		uri := change.TextDocumentEdit.TextDocument.URI
+		if err := client.SyncEdits(ctx, uri); err != nil {
+			toolsLogger.Warn("Failed to sync edits of %s with the language server: %v", uri, err)
+		}
+	}
+	return nil
+}
+
+// applyTextEdits writes edits to one file and sends them to the server
+func applyTextEdits(ctx context.Context, client *lsp.Client, uri protocol.DocumentUri, edits []protocol.TextEdit) error {
+	return applyWorkspaceEdit(ctx, client, protocol.WorkspaceEdit{
+		Changes: map[protocol.DocumentUri][]protocol.TextEdit{uri: edits},
+	})
+}
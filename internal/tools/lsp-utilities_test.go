@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupportsSymbolResolve(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider *protocol.Or_ServerCapabilities_workspaceSymbolProvider
+		expected bool
+	}{
+		{name: "Not advertised", provider: nil, expected: false},
+		{name: "Boolean provider", provider: &protocol.Or_ServerCapabilities_workspaceSymbolProvider{Value: true}, expected: false},
+		{
+			name: "Resolve provider",
+			provider: &protocol.Or_ServerCapabilities_workspaceSymbolProvider{
+				Value: protocol.WorkspaceSymbolOptions{ResolveProvider: true},
+			},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			capabilities := protocol.ServerCapabilities{WorkspaceSymbolProvider: tt.provider}
+			assert.Equal(t, tt.expected, supportsSymbolResolve(capabilities))
+		})
+	}
+}
+
+func TestWorkspaceSymbolHasRange(t *testing.T) {
+	var symbols []protocol.WorkspaceSymbol
+	err := json.Unmarshal([]byte(`[
+		{"name": "Resolved", "kind": 12, "location": {"uri": "file:///a.ts", "range": {"start": {"line": 3, "character": 9}, "end": {"line": 3, "character": 17}}}},
+		{"name": "Lazy", "kind": 12, "location": {"uri": "file:///a.ts"}, "data": {"id": 7}}
+	]`), &symbols)
+	require.NoError(t, err)
+
+	assert.True(t, symbols[0].HasRange())
+	assert.False(t, symbols[1].HasRange())
+	assert.Equal(t, protocol.DocumentUri("file:///a.ts"), symbols[1].GetLocation().URI)
+}
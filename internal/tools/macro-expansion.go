@@ -0,0 +1,117 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// maxASTDepth is how many levels of the AST below the node at the position are
+// shown. Deeper nodes are only counted.
+const maxASTDepth = 6
+
+// MacroExpansion describes what the code at a position (1-indexed line and column)
+// expands to: the hover, which for C and C++ macros under clangd includes the
+// expansion, and the AST node at the position when the server provides clangd's
+// textDocument/ast extension
+func MacroExpansion(ctx context.Context, client *lsp.Client, filePath string, line, column int) (string, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+	defer client.CloseFile(ctx, filePath)
+
+	// Convert 1-indexed line/column to 0-indexed for LSP protocol
+	uri := protocol.DocumentUri("file://" + filePath)
+	position := protocol.Position{
+		Line:      uint32(line - 1),
+		Character: uint32(column - 1),
+	}
+
+	hover, err := client.Hover(ctx, protocol.HoverParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+			Position:     position,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get hover information: %v", err)
+	}
+
+	var node *lsp.ASTNode
+	astNote := ""
+	if client.SupportsAST() {
+		node, err = client.AST(ctx, lsp.ASTParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+			Range:        protocol.Range{Start: position, End: position},
+		})
+		if err != nil {
+			toolsLogger.Warn("Failed to get AST at %s L%d:C%d: %v", filePath, line, column, err)
+			astNote = fmt.Sprintf("AST: unavailable (%v)\n", err)
+		} else if node == nil {
+			astNote = "AST: no node at this position\n"
+		}
+	}
+
+	return formatMacroExpansion(hover.Contents, node, astNote, filePath, line, column), nil
+}
+
+func formatMacroExpansion(contents protocol.MarkupContent, node *lsp.ASTNode, astNote, filePath string, line, column int) string {
+	var output strings.Builder
+	if isMacroHover(contents.Value) {
+		output.WriteString(fmt.Sprintf("Macro expansion at %s L%d:C%d:\n\n", filePath, line, column))
+	} else {
+		output.WriteString(fmt.Sprintf("Hover at %s L%d:C%d (no macro found at this position):\n\n", filePath, line, column))
+	}
+	output.WriteString(formatMarkupContent(contents))
+
+	if node != nil {
+		output.WriteString("\nAST:\n")
+		writeASTNode(&output, *node, 0)
+	} else if astNote != "" {
+		output.WriteString("\n" + astNote)
+	}
+	return output.String()
+}
+
+// isMacroHover reports whether hover text describes a macro. clangd titles macro
+// hovers "### macro `NAME`" and lists the expansion after "// Expands to".
+func isMacroHover(text string) bool {
+	return strings.HasPrefix(strings.TrimSpace(text), "### macro") || strings.Contains(text, "// Expands to")
+}
+
+func writeASTNode(output *strings.Builder, node lsp.ASTNode, depth int) {
+	indent := strings.Repeat("  ", depth+1)
+	output.WriteString(indent + node.Role + " " + node.Kind)
+	if node.Detail != "" {
+		output.WriteString(" " + node.Detail)
+	}
+	if node.Range != nil {
+		output.WriteString(fmt.Sprintf(" (L%d:C%d-L%d:C%d)",
+			node.Range.Start.Line+1, node.Range.Start.Character+1,
+			node.Range.End.Line+1, node.Range.End.Character+1))
+	}
+	output.WriteString("\n")
+
+	if len(node.Children) == 0 {
+		return
+	}
+	if depth >= maxASTDepth {
+		output.WriteString(fmt.Sprintf("%s  ... %d more nodes\n", indent, countASTNodes(node.Children)))
+		return
+	}
+	for _, child := range node.Children {
+		writeASTNode(output, child, depth+1)
+	}
+}
+
+func countASTNodes(nodes []lsp.ASTNode) int {
+	count := len(nodes)
+	for _, node := range nodes {
+		count += countASTNodes(node.Children)
+	}
+	return count
+}
@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatMacroExpansion(t *testing.T) {
+	macroHover := protocol.MarkupContent{
+		Kind:  protocol.Markdown,
+		Value: "### macro `SQUARE`\n\n---\n```cpp\n#define SQUARE(x) ((x) * (x))\n\n// Expands to\n((2) * (2))\n```",
+	}
+
+	testCases := []struct {
+		name     string
+		contents protocol.MarkupContent
+		node     *lsp.ASTNode
+		astNote  string
+		expected string
+	}{
+		{
+			name:     "Macro without AST",
+			contents: macroHover,
+			expected: "Macro expansion at /src/main.cpp L3:C10:\n\n" + macroHover.Value + "\n",
+		},
+		{
+			name:     "Not a macro",
+			contents: protocol.MarkupContent{Kind: protocol.PlainText, Value: "int x"},
+			expected: "Hover at /src/main.cpp L3:C10 (no macro found at this position):\n\n```\nint x\n```\n",
+		},
+		{
+			name:     "AST unavailable",
+			contents: macroHover,
+			astNote:  "AST: no node at this position\n",
+			expected: "Macro expansion at /src/main.cpp L3:C10:\n\n" + macroHover.Value + "\n\nAST: no node at this position\n",
+		},
+		{
+			name:     "With AST",
+			contents: macroHover,
+			node: &lsp.ASTNode{
+				Role: "expression",
+				Kind: "Paren",
+				Range: &protocol.Range{
+					Start: protocol.Position{Line: 2, Character: 9},
+					End:   protocol.Position{Line: 2, Character: 18},
+				},
+				Children: []lsp.ASTNode{
+					{Role: "expression", Kind: "BinaryOperator", Detail: "*"},
+				},
+			},
+			expected: "Macro expansion at /src/main.cpp L3:C10:\n\n" + macroHover.Value + "\n\n" +
+				"AST:\n" +
+				"  expression Paren (L3:C10-L3:C19)\n" +
+				"    expression BinaryOperator *\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, formatMacroExpansion(tc.contents, tc.node, tc.astNote, "/src/main.cpp", 3, 10))
+		})
+	}
+}
+
+func TestWriteASTNodeDepthLimit(t *testing.T) {
+	// The leaves are one level deeper than shown, so only their count is
+	node := lsp.ASTNode{Role: "expression", Kind: "Leaf"}
+	node = lsp.ASTNode{Role: "expression", Kind: "Parent", Children: []lsp.ASTNode{node, node}}
+	for i := 0; i < maxASTDepth; i++ {
+		node = lsp.ASTNode{Role: "expression", Kind: "Paren", Children: []lsp.ASTNode{node}}
+	}
+
+	var output strings.Builder
+	writeASTNode(&output, node, 0)
+	lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+	assert.Len(t, lines, maxASTDepth+2)
+	assert.Equal(t, strings.Repeat("  ", maxASTDepth+1)+"  ... 2 more nodes", lines[len(lines)-1])
+}
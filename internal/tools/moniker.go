@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// GetMoniker returns the monikers of the symbol at a position (1-indexed line and
+// column): stable identifiers, such as those of LSIF and SCIP indexes, that name the
+// same symbol across repositories
+func GetMoniker(ctx context.Context, client *lsp.Client, filePath string, line, character int) (string, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+	defer client.CloseFile(ctx, filePath)
+
+	// Convert 1-indexed line/column to 0-indexed for LSP protocol
+	monikers, err := client.Moniker(ctx, protocol.MonikerParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{
+				URI: protocol.DocumentUri("file://" + filePath),
+			},
+			Position: protocol.Position{
+				Line:      uint32(line - 1),
+				Character: uint32(character - 1),
+			},
+		},
+	})
+	if err != nil {
+		if isMethodNotSupported(err) {
+			return "The language server does not support monikers.", nil
+		}
+		return "", fmt.Errorf("failed to get monikers: %v", err)
+	}
+
+	if len(monikers) == 0 {
+		return fmt.Sprintf("No moniker found at %s L%d:C%d", filePath, line, character), nil
+	}
+	return formatMonikers(monikers, filePath, line, character), nil
+}
+
+// formatMonikers lists monikers one per line as "scheme identifier", followed by
+// their uniqueness and kind
+func formatMonikers(monikers []protocol.Moniker, filePath string, line, character int) string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Monikers at %s L%d:C%d:\n\n", filePath, line, character))
+	for _, moniker := range monikers {
+		output.WriteString(fmt.Sprintf("%s %s (unique: %s", moniker.Scheme, moniker.Identifier, moniker.Unique))
+		if moniker.Kind != nil {
+			output.WriteString(fmt.Sprintf(", kind: %s", *moniker.Kind))
+		}
+		output.WriteString(")\n")
+	}
+	return output.String()
+}
@@ -0,0 +1,21 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatMonikers(t *testing.T) {
+	export := protocol.Export
+	monikers := []protocol.Moniker{
+		{Scheme: "tsc", Identifier: "lib/cache:Cache.get", Unique: protocol.Global, Kind: &export},
+		{Scheme: "npm", Identifier: "cache-lib::lib/cache:Cache.get", Unique: protocol.Scheme},
+	}
+
+	expected := "Monikers at /src/cache.ts L4:C3:\n\n" +
+		"tsc lib/cache:Cache.get (unique: global, kind: export)\n" +
+		"npm cache-lib::lib/cache:Cache.get (unique: scheme)\n"
+	assert.Equal(t, expected, formatMonikers(monikers, "/src/cache.ts", 4, 3))
+}
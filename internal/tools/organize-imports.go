@@ -0,0 +1,125 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// OrganizeImports applies the server's source.organizeImports code action to a file,
+// which for gopls adds missing imports, removes unused ones and sorts them. With
+// dryRun set the changes are returned as a diff and nothing is written.
+func OrganizeImports(ctx context.Context, client *lsp.Client, filePath string, dryRun bool) (string, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+	defer client.CloseFile(ctx, filePath)
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+
+	actions, err := client.CodeAction(ctx, protocol.CodeActionParams{
+		TextDocument: protocol.TextDocumentIdentifier{
+			URI: protocol.DocumentUri("file://" + filePath),
+		},
+		Range: protocol.Range{
+			Start: protocol.Position{Line: 0, Character: 0},
+			End:   protocol.Position{Line: uint32(len(splitLines(content))), Character: 0},
+		},
+		Context: protocol.CodeActionContext{
+			Diagnostics: []protocol.Diagnostic{},
+			Only:        []protocol.CodeActionKind{protocol.SourceOrganizeImports},
+		},
+	})
+	if err != nil {
+		if isMethodNotSupported(err) {
+			return "The language server does not support code actions.", nil
+		}
+		return "", fmt.Errorf("failed to get code actions: %v", err)
+	}
+
+	action, ok := organizeImportsAction(actions)
+	if !ok {
+		return fmt.Sprintf("Imports in %s are already organized.", filePath), nil
+	}
+
+	// Servers may compute the edit lazily and only return it from codeAction/resolve
+	if action.Edit == nil && action.Data != nil {
+		resolved, err := client.ResolveCodeAction(ctx, action)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve code action: %v", err)
+		}
+		action = resolved
+	}
+
+	if action.Edit == nil {
+		if action.Command == nil {
+			return fmt.Sprintf("Imports in %s are already organized.", filePath), nil
+		}
+		if dryRun {
+			return fmt.Sprintf("Dry run: the server organizes imports in %s by running the command %q, which can't be previewed. No files were changed.", filePath, action.Command.Title), nil
+		}
+
+		// Edits produced by the command are sent back as workspace/applyEdit requests
+		_, err := client.ExecuteCommand(ctx, protocol.ExecuteCommandParams{
+			Command:   action.Command.Command,
+			Arguments: action.Command.Arguments,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to execute command: %v", err)
+		}
+		client.InvalidateSymbolCache()
+		return fmt.Sprintf("Organized imports in %s with the command %q.", filePath, action.Command.Title), nil
+	}
+
+	if dryRun {
+		diff, err := previewWorkspaceEdit(*action.Edit)
+		if err != nil {
+			return "", fmt.Errorf("failed to preview changes: %v", err)
+		}
+		if diff == "" {
+			return fmt.Sprintf("Imports in %s are already organized.", filePath), nil
+		}
+		return fmt.Sprintf("Dry run: organizing imports would make these changes. No files were changed.\n\n%s", diff), nil
+	}
+
+	editCount := 0
+	for _, count := range countEditsByFile(*action.Edit) {
+		editCount += count
+	}
+	if err := applyWorkspaceEdit(ctx, client, *action.Edit); err != nil {
+		return "", fmt.Errorf("failed to apply changes: %v", err)
+	}
+	client.InvalidateSymbolCache()
+
+	return fmt.Sprintf("Organized imports in %s (%d edits).", filePath, editCount), nil
+}
+
+// organizeImportsAction returns the first organize imports action among the code
+// actions a server returned. Servers that ignore the kind filter may list other
+// actions too. A bare command is wrapped in an action.
+func organizeImportsAction(actions []protocol.Or_Result_textDocument_codeAction_Item0_Elem) (protocol.CodeAction, bool) {
+	for _, item := range actions {
+		switch v := item.Value.(type) {
+		case protocol.CodeAction:
+			if v.Disabled != nil {
+				continue
+			}
+			if v.Kind == protocol.SourceOrganizeImports || strings.HasPrefix(string(v.Kind), string(protocol.SourceOrganizeImports)+".") {
+				return v, true
+			}
+		case protocol.Command:
+			if strings.Contains(strings.ToLower(v.Title), "organize imports") {
+				return protocol.CodeAction{Title: v.Title, Command: &v}, true
+			}
+		}
+	}
+	return protocol.CodeAction{}, false
+}
@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrganizeImportsAction(t *testing.T) {
+	action := func(value any) protocol.Or_Result_textDocument_codeAction_Item0_Elem {
+		return protocol.Or_Result_textDocument_codeAction_Item0_Elem{Value: value}
+	}
+
+	testCases := []struct {
+		name          string
+		actions       []protocol.Or_Result_textDocument_codeAction_Item0_Elem
+		expectedTitle string
+		expectedFound bool
+	}{
+		{
+			name: "Other kinds are skipped",
+			actions: []protocol.Or_Result_textDocument_codeAction_Item0_Elem{
+				action(protocol.CodeAction{Title: "Fill struct", Kind: protocol.RefactorRewrite}),
+				action(protocol.CodeAction{Title: "Organize Imports", Kind: protocol.SourceOrganizeImports}),
+			},
+			expectedTitle: "Organize Imports",
+			expectedFound: true,
+		},
+		{
+			name: "Sub kind",
+			actions: []protocol.Or_Result_textDocument_codeAction_Item0_Elem{
+				action(protocol.CodeAction{Title: "Sort imports", Kind: "source.organizeImports.ruff"}),
+			},
+			expectedTitle: "Sort imports",
+			expectedFound: true,
+		},
+		{
+			name: "Disabled",
+			actions: []protocol.Or_Result_textDocument_codeAction_Item0_Elem{
+				action(protocol.CodeAction{Title: "Organize Imports", Kind: protocol.SourceOrganizeImports, Disabled: &protocol.CodeActionDisabled{Reason: "nothing to do"}}),
+			},
+		},
+		{
+			name: "Bare command",
+			actions: []protocol.Or_Result_textDocument_codeAction_Item0_Elem{
+				action(protocol.Command{Title: "Organize imports", Command: "_typescript.organizeImports"}),
+			},
+			expectedTitle: "Organize imports",
+			expectedFound: true,
+		},
+		{
+			name: "Similar kind name",
+			actions: []protocol.Or_Result_textDocument_codeAction_Item0_Elem{
+				action(protocol.CodeAction{Title: "Other", Kind: "source.organizeImportsLater"}),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, found := organizeImportsAction(tc.actions)
+			assert.Equal(t, tc.expectedFound, found)
+			assert.Equal(t, tc.expectedTitle, got.Title)
+		})
+	}
+}
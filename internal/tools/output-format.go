@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+const (
+	// OutputFormatText is the default human readable output
+	OutputFormatText = "text"
+	// OutputFormatJSON makes tools that support it return the result types below as JSON
+	OutputFormatJSON = "json"
+)
+
+// outputFormat returns the output format configured by the LSP_OUTPUT_FORMAT
+// environment variable, or OutputFormatText if it is unset or unknown
+func outputFormat() string {
+	if strings.EqualFold(os.Getenv("LSP_OUTPUT_FORMAT"), OutputFormatJSON) {
+		return OutputFormatJSON
+	}
+	return OutputFormatText
+}
+
+// ResultPosition is a 1-indexed position in a file
+type ResultPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// ResultRange is a 1-indexed range in a file
+type ResultRange struct {
+	Start ResultPosition `json:"start"`
+	End   ResultPosition `json:"end"`
+}
+
+func newResultRange(r protocol.Range) ResultRange {
+	return ResultRange{
+		Start: ResultPosition{Line: int(r.Start.Line) + 1, Column: int(r.Start.Character) + 1},
+		End:   ResultPosition{Line: int(r.End.Line) + 1, Column: int(r.End.Character) + 1},
+	}
+}
+
+// DefinitionResult is one definition of a symbol. Code is the source of the whole
+// definition, without line numbers.
+type DefinitionResult struct {
+	Symbol string `json:"symbol"`
+	File   string `json:"file"`
+	// External is set for definitions outside the workspace. If File is not a
+	// file URI, Code is the hover content instead of source.
+	External  bool   `json:"external,omitempty"`
+	Kind      string `json:"kind,omitempty"`
+	Container string `json:"container,omitempty"`
+	// Deprecated is set when the server tags the symbol as deprecated
+	Deprecated bool        `json:"deprecated,omitempty"`
+	Range      ResultRange `json:"range"`
+	// Selection is the range of the symbol's identifier, set when it differs from Range
+	Selection *ResultRange `json:"selection,omitempty"`
+	Code      string       `json:"code"`
+	// OmittedLines is the number of lines cut from Code by the line limit
+	OmittedLines int `json:"omittedLines,omitempty"`
+}
+
+// DefinitionsResult is the JSON output of ReadDefinition
+type DefinitionsResult struct {
+	Symbol      string             `json:"symbol"`
+	Definitions []DefinitionResult `json:"definitions"`
+	// Truncated is set when more definitions matched than the maxResults cap
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// DefinitionsBatchResult is the JSON output of ReadDefinitions. Symbols holds a
+// DefinitionsResult for each symbol that was found.
+type DefinitionsBatchResult struct {
+	Symbols  []json.RawMessage `json:"symbols"`
+	NotFound []string          `json:"notFound"`
+}
+
+// FileReferencesResult holds the references to a symbol within one file. Snippet is
+// the numbered source lines around the references, with "..." between gaps.
+type FileReferencesResult struct {
+	File       string        `json:"file"`
+	References []ResultRange `json:"references"`
+	// Declaration is the reference at the symbol's declaration, when it is in
+	// this file and was requested
+	Declaration *ResultRange `json:"declaration,omitempty"`
+	Snippet     string       `json:"snippet,omitempty"`
+	Error       string       `json:"error,omitempty"`
+}
+
+// ReferencesResult is the JSON output of FindReferences
+type ReferencesResult struct {
+	Symbol string                 `json:"symbol"`
+	Files  []FileReferencesResult `json:"files"`
+	// ExcludedFiles counts the files left out because they matched an exclude glob
+	ExcludedFiles int `json:"excludedFiles,omitempty"`
+	// OmittedReferences counts the references dropped by the per file cap
+	OmittedReferences int `json:"omittedReferences,omitempty"`
+	// TotalFiles is set when the files are split into pages, and NextPage when
+	// there are more pages
+	TotalFiles int `json:"totalFiles,omitempty"`
+	NextPage   int `json:"nextPage,omitempty"`
+	// TotalReferences is set for summaries, whose files have no snippets
+	TotalReferences int `json:"totalReferences,omitempty"`
+}
+
+// ExploreResult is the JSON output of ExploreSymbol. Definitions is a
+// DefinitionsResult and References a ReferencesResult.
+type ExploreResult struct {
+	Definitions json.RawMessage `json:"definitions"`
+	References  json.RawMessage `json:"references"`
+}
+
+// formatJSON renders a result type as indented JSON
+func formatJSON(v any) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode result: %v", err)
+	}
+	return string(data), nil
+}
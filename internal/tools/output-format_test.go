@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutputFormat(t *testing.T) {
+	testCases := []struct {
+		name     string
+		env      string
+		expected string
+	}{
+		{name: "Unset", env: "", expected: OutputFormatText},
+		{name: "JSON", env: "json", expected: OutputFormatJSON},
+		{name: "JSON uppercase", env: "JSON", expected: OutputFormatJSON},
+		{name: "Unknown", env: "xml", expected: OutputFormatText},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("LSP_OUTPUT_FORMAT", tc.env)
+			assert.Equal(t, tc.expected, outputFormat())
+		})
+	}
+}
+
+func TestFormatJSON_ReferencesResult(t *testing.T) {
+	result := ReferencesResult{
+		Symbol: "foo",
+		Files: []FileReferencesResult{
+			{
+				File: "/path/to/file.go",
+				References: []ResultRange{newResultRange(protocol.Range{
+					Start: protocol.Position{Line: 4, Character: 1},
+					End:   protocol.Position{Line: 4, Character: 4},
+				})},
+			},
+		},
+	}
+
+	expected := `{
+  "symbol": "foo",
+  "files": [
+    {
+      "file": "/path/to/file.go",
+      "references": [
+        {
+          "start": {
+            "line": 5,
+            "column": 2
+          },
+          "end": {
+            "line": 5,
+            "column": 5
+          }
+        }
+      ]
+    }
+  ]
+}`
+
+	output, err := formatJSON(result)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, output)
+}
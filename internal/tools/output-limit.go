@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// minOutputBytes is the smallest output limit, so that the truncation marker always
+// fits within it
+const minOutputBytes = 40
+
+// maxOutputBytes returns the size limit of tool output from LSP_MAX_OUTPUT_BYTES, or
+// 0 for no limit, the default. MCP clients may reject or cut off larger messages.
+// Limits below minOutputBytes are raised to it.
+func maxOutputBytes() int {
+	value := os.Getenv("LSP_MAX_OUTPUT_BYTES")
+	if value == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		toolsLogger.Warn("Invalid LSP_MAX_OUTPUT_BYTES %q, not limiting output", value)
+		return 0
+	}
+	if n > 0 && n < minOutputBytes {
+		toolsLogger.Warn("LSP_MAX_OUTPUT_BYTES %d is too small, limiting output to %d bytes", n, minOutputBytes)
+		return minOutputBytes
+	}
+	return n
+}
+
+// truncationMarker ends output that was cut to fit limit bytes
+func truncationMarker(limit int) string {
+	return fmt.Sprintf("[truncated: output exceeded %d bytes]\n", limit)
+}
+
+// joinSections joins sections, such as the files of references or the definitions
+// of a symbol, with sep and appends trailer. If that would exceed maxOutputBytes, the
+// sections that don't fit are dropped whole and a truncation marker is put before
+// trailer, so that notes at the end are kept. If not even the first section fits,
+// it is cut at a line break, and if the trailer doesn't fit either the whole output
+// is cut like any other.
+func joinSections(sections []string, sep string, trailer string) string {
+	output := strings.Join(sections, sep) + trailer
+	limit := maxOutputBytes()
+	if limit == 0 || len(output) <= limit {
+		return output
+	}
+
+	marker := truncationMarker(limit)
+	budget := limit - len(trailer) - len(marker)
+	if budget <= 0 {
+		return LimitOutput(output)
+	}
+	var result strings.Builder
+	for i, section := range sections {
+		size := len(section)
+		if i > 0 {
+			size += len(sep)
+		}
+		if result.Len()+size > budget {
+			if i == 0 {
+				result.WriteString(cutAtLine(section, budget))
+			}
+			break
+		}
+		if i > 0 {
+			result.WriteString(sep)
+		}
+		result.WriteString(section)
+	}
+
+	text := result.String()
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return text + marker + trailer
+}
+
+// LimitOutput cuts text that exceeds maxOutputBytes at the last line break that
+// fits and marks it as truncated. It is applied to the output of every tool, and
+// tools whose output has sections use joinSections to cut between them first.
+func LimitOutput(text string) string {
+	limit := maxOutputBytes()
+	if limit == 0 || len(text) <= limit {
+		return text
+	}
+	marker := truncationMarker(limit)
+	return cutAtLine(text, limit-len(marker)) + marker
+}
+
+// cutAtLine returns the longest prefix of text, at most size bytes, that ends with a
+// line break, or nothing if no line fits
+func cutAtLine(text string, size int) string {
+	if size <= 0 {
+		return ""
+	}
+	if len(text) <= size {
+		return text
+	}
+	return text[:strings.LastIndex(text[:size], "\n")+1]
+}
@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJoinSections(t *testing.T) {
+	section := func(file string) string {
+		return "---\n\n" + file + "\n" + strings.Repeat("x", 30) + "\n"
+	}
+	sections := []string{section("a.go"), section("b.go"), section("c.go")}
+	trailer := "\nnote\n"
+	full := sections[0] + "\n" + sections[1] + "\n" + sections[2] + trailer
+
+	t.Run("No limit", func(t *testing.T) {
+		assert.Equal(t, full, joinSections(sections, "\n", trailer))
+	})
+
+	t.Run("Within limit", func(t *testing.T) {
+		t.Setenv("LSP_MAX_OUTPUT_BYTES", "131")
+		assert.Equal(t, full, joinSections(sections, "\n", trailer))
+	})
+
+	t.Run("Whole sections are dropped", func(t *testing.T) {
+		t.Setenv("LSP_MAX_OUTPUT_BYTES", "130")
+		output := joinSections(sections, "\n", trailer)
+		assert.Equal(t, sections[0]+"\n"+sections[1]+"[truncated: output exceeded 130 bytes]\n"+trailer, output)
+		assert.LessOrEqual(t, len(output), 130)
+	})
+
+	t.Run("First section is cut at a line", func(t *testing.T) {
+		t.Setenv("LSP_MAX_OUTPUT_BYTES", "50")
+		long := []string{strings.Repeat("abc\n", 20)}
+		assert.Equal(t, "abc\nabc\nabc\n[truncated: output exceeded 50 bytes]\n", joinSections(long, "\n", ""))
+	})
+
+	t.Run("Trailer over the limit", func(t *testing.T) {
+		t.Setenv("LSP_MAX_OUTPUT_BYTES", "60")
+		longTrailer := "\n" + strings.Repeat("note\n", 20)
+		output := joinSections(sections, "\n", longTrailer)
+		assert.Equal(t, "---\n\na.go\n[truncated: output exceeded 60 bytes]\n", output)
+		assert.LessOrEqual(t, len(output), 60)
+	})
+}
+
+func TestLimitOutput(t *testing.T) {
+	text := "first line\nsecond line\nthird line\n"
+	assert.Equal(t, text, LimitOutput(text))
+
+	t.Setenv("LSP_MAX_OUTPUT_BYTES", "60")
+	assert.Equal(t, "first line\n[truncated: output exceeded 60 bytes]\n", LimitOutput(text+text))
+
+	t.Setenv("LSP_MAX_OUTPUT_BYTES", "invalid")
+	assert.Equal(t, text+text, LimitOutput(text+text))
+
+	t.Setenv("LSP_MAX_OUTPUT_BYTES", "10")
+	output := LimitOutput(text + text)
+	assert.Equal(t, "[truncated: output exceeded 40 bytes]\n", output)
+	assert.LessOrEqual(t, len(output), minOutputBytes)
+	assert.LessOrEqual(t, len(joinSections([]string{text, text}, "\n", "trailer\n")), minOutputBytes)
+}
@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// rawRequestBlocked are the lifecycle methods RawRequest refuses, since sending
+// them would leave the client and server out of step
+var rawRequestBlocked = map[string]bool{
+	"initialize": true,
+	"shutdown":   true,
+	"exit":       true,
+}
+
+// RawRequest sends an arbitrary request to the language server and returns its
+// response as indented JSON. paramsJSON is sent verbatim, or no params if it's empty.
+// If the params name a file with textDocument.uri, the file is opened first.
+// Meant for debugging servers and trying server specific extensions.
+func RawRequest(ctx context.Context, client *lsp.Client, method string, paramsJSON string) (string, error) {
+	if method == "" {
+		return "", fmt.Errorf("method is required")
+	}
+	if rawRequestBlocked[method] {
+		return "", fmt.Errorf("%s can't be sent as a raw request", method)
+	}
+
+	var params any
+	if paramsJSON != "" {
+		if !json.Valid([]byte(paramsJSON)) {
+			return "", fmt.Errorf("params is not valid JSON")
+		}
+		params = json.RawMessage(paramsJSON)
+
+		if uri := rawRequestDocument(paramsJSON); isFileURI(uri) {
+			if err := client.OpenFile(ctx, uriToPath(uri)); err != nil {
+				return "", fmt.Errorf("could not open file: %v", err)
+			}
+			defer client.CloseFile(ctx, uriToPath(uri))
+		}
+	}
+
+	var result json.RawMessage
+	if err := client.Call(ctx, method, params, &result); err != nil {
+		return "", err
+	}
+	return formatRawResponse(result), nil
+}
+
+// rawRequestDocument returns the textDocument.uri of request params, if any
+func rawRequestDocument(paramsJSON string) protocol.DocumentUri {
+	var params struct {
+		TextDocument struct {
+			URI protocol.DocumentUri `json:"uri"`
+		} `json:"textDocument"`
+	}
+	if err := json.Unmarshal([]byte(paramsJSON), &params); err != nil {
+		return ""
+	}
+	return params.TextDocument.URI
+}
+
+// formatRawResponse indents a JSON response. An empty response is shown as null.
+func formatRawResponse(result json.RawMessage) string {
+	if len(result) == 0 {
+		return "null"
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, result, "", "  "); err != nil {
+		return string(result)
+	}
+	return indented.String()
+}
@@ -0,0 +1,22 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRawRequestDocument(t *testing.T) {
+	assert.Equal(t, protocol.DocumentUri("file:///src/main.cpp"), rawRequestDocument(`{"textDocument": {"uri": "file:///src/main.cpp"}, "position": {"line": 1, "character": 2}}`))
+	assert.Empty(t, rawRequestDocument(`{"uri": "file:///src/main.cpp"}`))
+	assert.Empty(t, rawRequestDocument(`{"query": "Foo"}`))
+	assert.Empty(t, rawRequestDocument(`[1, 2]`))
+}
+
+func TestFormatRawResponse(t *testing.T) {
+	assert.Equal(t, "null", formatRawResponse(nil))
+	assert.Equal(t, "null", formatRawResponse(json.RawMessage("null")))
+	assert.Equal(t, "{\n  \"uri\": \"file:///src/main.h\"\n}", formatRawResponse(json.RawMessage(`{"uri":"file:///src/main.h"}`)))
+}
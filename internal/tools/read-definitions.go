@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// ReadDefinitions reads the definitions of several symbols in one call, with one
+// section per symbol in the order given and a final section listing the symbols that
+// were not found. Each file is opened at most once and workspace/symbol results are
+// shared through the client's cache. opts applies to every symbol.
+func ReadDefinitions(ctx context.Context, client *lsp.Client, symbolNames []string, opts DefinitionOptions) (string, error) {
+	if len(symbolNames) == 0 {
+		return "", fmt.Errorf("no symbol names given")
+	}
+	if err := validateScope(opts.Scope); err != nil {
+		return "", err
+	}
+
+	opened := make(map[protocol.DocumentUri]bool)
+	defer closeURIs(ctx, client, opened)
+	seen := make(map[string]bool)
+	var sections []string
+	var found []json.RawMessage
+	notFound := []string{}
+	for _, symbolName := range symbolNames {
+		symbolName, err := NormalizeSymbolName(symbolName)
+		if err != nil {
+			return "", err
+		}
+		if seen[symbolName] {
+			continue
+		}
+		seen[symbolName] = true
+
+		text, ok, err := readDefinition(ctx, client, symbolName, opts, opened)
+		if err != nil {
+			return "", fmt.Errorf("failed to read definition of %s: %v", symbolName, err)
+		}
+		if !ok {
+			notFound = append(notFound, symbolName)
+			continue
+		}
+
+		if outputFormat() == OutputFormatJSON {
+			found = append(found, json.RawMessage(text))
+			continue
+		}
+		sections = append(sections, fmt.Sprintf("=== %s ===\n\n%s", symbolName, text))
+	}
+
+	if outputFormat() == OutputFormatJSON {
+		if found == nil {
+			found = []json.RawMessage{}
+		}
+		return formatJSON(DefinitionsBatchResult{Symbols: found, NotFound: notFound})
+	}
+
+	if len(notFound) > 0 {
+		sections = append(sections, "=== Not found ===\n\n"+strings.Join(notFound, "\n")+"\n")
+	}
+	return strings.Join(sections, "\n"), nil
+}
@@ -0,0 +1,36 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadDefinitionsValidatesArguments(t *testing.T) {
+	testCases := []struct {
+		name        string
+		symbolNames []string
+		opts        DefinitionOptions
+		expected    string
+	}{
+		{
+			name:        "No symbols",
+			symbolNames: nil,
+			expected:    "no symbol names given",
+		},
+		{
+			name:        "Invalid scope",
+			symbolNames: []string{"main"},
+			opts:        DefinitionOptions{Scope: "header"},
+			expected:    `invalid scope "header"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ReadDefinitions(context.Background(), nil, tc.symbolNames, tc.opts)
+			assert.ErrorContains(t, err, tc.expected)
+		})
+	}
+}
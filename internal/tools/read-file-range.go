@@ -0,0 +1,43 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ReadFileRange shows the lines startLine to endLine (1-indexed, inclusive) of a
+// file with line numbers, without going through the language server. The range is
+// clamped to the file, and an endLine before startLine reads to the end of the file.
+func ReadFileRange(ctx context.Context, filePath string, startLine, endLine int) (string, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	return formatFileRange(filePath, splitLines(content), startLine, endLine)
+}
+
+// formatFileRange numbers the lines startLine to endLine of a file, clamping the
+// range to the lines it has
+func formatFileRange(filePath string, lines []string, startLine, endLine int) (string, error) {
+	// A trailing newline ends the last line rather than starting an empty one
+	if len(lines) > 1 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	if startLine < 1 {
+		startLine = 1
+	}
+	if startLine > len(lines) {
+		return "", fmt.Errorf("line %d is past the end of %s, which has %d lines", startLine, filePath, len(lines))
+	}
+	if endLine < startLine || endLine > len(lines) {
+		endLine = len(lines)
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("%s (L%d-L%d of %d):\n\n", filePath, startLine, endLine, len(lines)))
+	output.WriteString(addLineNumbers(strings.Join(lines[startLine-1:endLine], "\n"), startLine))
+	return output.String(), nil
+}
@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatFileRange(t *testing.T) {
+	lines := splitLines([]byte("package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n"))
+
+	testCases := []struct {
+		name      string
+		startLine int
+		endLine   int
+		expected  string
+	}{
+		{
+			name:      "Middle of file",
+			startLine: 3,
+			endLine:   4,
+			expected:  "main.go (L3-L4 of 5):\n\n3|func main() {\n4|\tprintln(\"hi\")\n",
+		},
+		{
+			name:      "Clamped to file",
+			startLine: 0,
+			endLine:   100,
+			expected:  "main.go (L1-L5 of 5):\n\n1|package main\n2|\n3|func main() {\n4|\tprintln(\"hi\")\n5|}\n",
+		},
+		{
+			name:      "End before start reads to the end",
+			startLine: 5,
+			endLine:   0,
+			expected:  "main.go (L5-L5 of 5):\n\n5|}\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			text, err := formatFileRange("main.go", lines, tc.startLine, tc.endLine)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, text)
+		})
+	}
+
+	_, err := formatFileRange("main.go", lines, 6, 10)
+	assert.ErrorContains(t, err, "past the end")
+}
@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// filterReferencesByContainer keeps the references made from inside a symbol named
+// container, such as the methods of a class. Each reference is placed in the
+// symbols of its file, which takes one textDocument/documentSymbol request per file.
+func filterReferencesByContainer(ctx context.Context, client *lsp.Client, refs []protocol.Location, container string) ([]protocol.Location, error) {
+	if container == "" {
+		return refs, nil
+	}
+
+	// Group references by file, keeping their order
+	var uris []protocol.DocumentUri
+	refsByFile := make(map[protocol.DocumentUri][]protocol.Location)
+	for _, ref := range refs {
+		if _, ok := refsByFile[ref.URI]; !ok {
+			uris = append(uris, ref.URI)
+		}
+		refsByFile[ref.URI] = append(refsByFile[ref.URI], ref)
+	}
+
+	var filtered []protocol.Location
+	for _, uri := range uris {
+		if err := openURI(ctx, client, uri); err != nil {
+			toolsLogger.Error("Error opening file: %v", err)
+			continue
+		}
+		defer closeURI(ctx, client, uri)
+
+		symResult, err := client.DocumentSymbol(ctx, protocol.DocumentSymbolParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		})
+		if err != nil {
+			if isMethodNotSupported(err) {
+				return nil, fmt.Errorf("the language server does not support document symbols, which are needed to filter references by container")
+			}
+			return nil, fmt.Errorf("failed to get document symbols: %v", err)
+		}
+		symbols, err := symResult.Results()
+		if err != nil {
+			return nil, fmt.Errorf("failed to process document symbols: %v", err)
+		}
+
+		tree := buildSymbolTree(symbols)
+		for _, ref := range refsByFile[uri] {
+			chain := enclosingChain(tree, int(ref.Range.Start.Line)+1, int(ref.Range.Start.Character)+1)
+			if chainInContainer(chain, container) {
+				filtered = append(filtered, ref)
+			}
+		}
+	}
+	return filtered, nil
+}
+
+// chainInContainer reports whether one of the symbols enclosing a reference is
+// named container, or is qualified by it like the Go method "(*Cache).Get" or the
+// C++ definition "Cache::get" outside its class. Qualified containers such as
+// "ns::Cache" must match the end of the name.
+func chainInContainer(chain []*symbolNode, container string) bool {
+	normalize := strings.NewReplacer("::", ".", "(*", "", "(", "", ")", "").Replace
+	container = normalize(container)
+
+	for _, node := range chain {
+		name := normalize(node.Name)
+		qualifier, _ := splitQualifiedName(name)
+		for _, candidate := range []string{name, qualifier} {
+			if candidate == container || strings.HasSuffix(candidate, "."+container) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChainInContainer(t *testing.T) {
+	node := func(name string) *symbolNode {
+		return &symbolNode{Name: name, Kind: protocol.Class}
+	}
+
+	testCases := []struct {
+		name      string
+		chain     []*symbolNode
+		container string
+		expected  bool
+	}{
+		{name: "Class around method", chain: []*symbolNode{node("Cache"), node("size")}, container: "Cache", expected: true},
+		{name: "Other class", chain: []*symbolNode{node("Store"), node("size")}, container: "Cache", expected: false},
+		{name: "Namespace qualified container", chain: []*symbolNode{node("ns"), node("Cache"), node("size")}, container: "Cache", expected: true},
+		{name: "Go pointer method", chain: []*symbolNode{node("(*Cache).Get")}, container: "Cache", expected: true},
+		{name: "C++ out of class definition", chain: []*symbolNode{node("ns::Cache::get")}, container: "ns::Cache", expected: true},
+		{name: "Suffix of another name", chain: []*symbolNode{node("LruCache"), node("size")}, container: "Cache", expected: false},
+		{name: "Top level", chain: nil, container: "Cache", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, chainInContainer(tc.chain, tc.container))
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// Reference kinds for filtering FindReferences results
+const (
+	// ReferenceKindAll keeps every reference
+	ReferenceKindAll = "all"
+	// ReferenceKindRead keeps references that read the symbol
+	ReferenceKindRead = "read"
+	// ReferenceKindWrite keeps references that assign to the symbol
+	ReferenceKindWrite = "write"
+)
+
+// filterReferencesByKind keeps the references of the given kind. textDocument/references
+// doesn't classify locations, so this sends one textDocument/documentHighlight request
+// per file and uses the highlight kinds, which makes filtered searches slower.
+func filterReferencesByKind(ctx context.Context, client *lsp.Client, refs []protocol.Location, kind string) ([]protocol.Location, error) {
+	var want protocol.DocumentHighlightKind
+	switch kind {
+	case "", ReferenceKindAll:
+		return refs, nil
+	case ReferenceKindRead:
+		want = protocol.Read
+	case ReferenceKindWrite:
+		want = protocol.Write
+	default:
+		return nil, fmt.Errorf("invalid reference kind %q, expected one of %s, %s or %s", kind, ReferenceKindAll, ReferenceKindRead, ReferenceKindWrite)
+	}
+
+	// Group references by file, keeping their order
+	var uris []protocol.DocumentUri
+	refsByFile := make(map[protocol.DocumentUri][]protocol.Location)
+	for _, ref := range refs {
+		if _, ok := refsByFile[ref.URI]; !ok {
+			uris = append(uris, ref.URI)
+		}
+		refsByFile[ref.URI] = append(refsByFile[ref.URI], ref)
+	}
+
+	var filtered []protocol.Location
+	for _, uri := range uris {
+		fileRefs := refsByFile[uri]
+		if err := openURI(ctx, client, uri); err != nil {
+			toolsLogger.Error("Error opening file: %v", err)
+			continue
+		}
+		defer closeURI(ctx, client, uri)
+
+		// All occurrences of the symbol in the file are highlighted from any one of them
+		highlights, err := client.DocumentHighlight(ctx, protocol.DocumentHighlightParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+				Position:     fileRefs[0].Range.Start,
+			},
+		})
+		if err != nil {
+			if isMethodNotSupported(err) {
+				return nil, fmt.Errorf("the language server does not support document highlights, which are needed to filter references by kind")
+			}
+			return nil, fmt.Errorf("failed to get document highlights: %v", err)
+		}
+
+		filtered = append(filtered, referencesOfKind(fileRefs, highlights, want)...)
+	}
+	return filtered, nil
+}
+
+// referencesOfKind keeps the references whose highlight at the same position has
+// the wanted kind. References without a matching highlight are dropped.
+func referencesOfKind(refs []protocol.Location, highlights []protocol.DocumentHighlight, want protocol.DocumentHighlightKind) []protocol.Location {
+	kinds := make(map[protocol.Position]protocol.DocumentHighlightKind, len(highlights))
+	for _, highlight := range highlights {
+		kinds[highlight.Range.Start] = highlight.Kind
+	}
+
+	var matching []protocol.Location
+	for _, ref := range refs {
+		if kind, ok := kinds[ref.Range.Start]; ok && kind == want {
+			matching = append(matching, ref)
+		}
+	}
+	return matching
+}
@@ -0,0 +1,32 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReferencesOfKind(t *testing.T) {
+	at := func(line, character uint32) protocol.Range {
+		return protocol.Range{
+			Start: protocol.Position{Line: line, Character: character},
+			End:   protocol.Position{Line: line, Character: character + 1},
+		}
+	}
+	ref := func(line, character uint32) protocol.Location {
+		return protocol.Location{URI: "file:///src/main.go", Range: at(line, character)}
+	}
+
+	refs := []protocol.Location{ref(3, 1), ref(4, 8), ref(6, 1), ref(9, 4)}
+	highlights := []protocol.DocumentHighlight{
+		{Range: at(1, 5), Kind: protocol.Write}, // The declaration, not a reference
+		{Range: at(3, 1), Kind: protocol.Write},
+		{Range: at(4, 8), Kind: protocol.Read},
+		{Range: at(6, 1), Kind: protocol.Write},
+		// No highlight for 9:4
+	}
+
+	assert.Equal(t, []protocol.Location{ref(3, 1), ref(6, 1)}, referencesOfKind(refs, highlights, protocol.Write))
+	assert.Equal(t, []protocol.Location{ref(4, 8)}, referencesOfKind(refs, highlights, protocol.Read))
+}
@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// FindReferencesAtPosition finds the references to the symbol at a position (1-indexed
+// line and column). Unlike FindReferences it doesn't look the symbol up by name, so
+// it is exact when many symbols share a name. contextLines may be DefaultContextLines.
+func FindReferencesAtPosition(ctx context.Context, client *lsp.Client, filePath string, line, character int, contextLines int) (string, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+	defer client.CloseFile(ctx, filePath)
+
+	// Convert 1-indexed line/column to 0-indexed for LSP protocol
+	refs, err := client.References(ctx, protocol.ReferenceParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{
+				URI: protocol.DocumentUri("file://" + filePath),
+			},
+			Position: protocol.Position{
+				Line:      uint32(line - 1),
+				Character: uint32(character - 1),
+			},
+		},
+		Context: protocol.ReferenceContext{
+			IncludeDeclaration: false,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get references: %v", err)
+	}
+
+	refs = normalizeLocationURIs(refs)
+
+	if len(refs) == 0 {
+		return fmt.Sprintf("No references found at %s L%d:C%d", filePath, line, character), nil
+	}
+
+	formatted, unreadable := formatLocationsByFile(ctx, client, refs, nil, snippetOptions{contextLines: contextLines}, "References")
+	output := strings.Join(formatted, "\n")
+	if note := unreadableNote(unreadable); note != "" {
+		output += "\n---\n\n" + note + "\n"
+	}
+	return output, nil
+}
@@ -2,39 +2,141 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
-func FindReferences(ctx context.Context, client *lsp.Client, symbolName string) (string, error) {
-	// Get context lines from environment variable
+// DefaultContextLines can be passed as contextLines to use the count configured for
+// the file's language, or else the LSP_CONTEXT_LINES environment variable, or 5
+// lines if neither is set
+const DefaultContextLines = -1
+
+// defaultContextLines returns the number of context lines configured by the environment
+func defaultContextLines() int {
 	contextLines := 5
 	if envLines := os.Getenv("LSP_CONTEXT_LINES"); envLines != "" {
 		if val, err := strconv.Atoi(envLines); err == nil && val >= 0 {
 			contextLines = val
 		}
 	}
+	return contextLines
+}
 
-	// First get the symbol location like ReadDefinition does
-	symbolResult, err := client.Symbol(ctx, protocol.WorkspaceSymbolParams{
-		Query: symbolName,
-	})
+// languageContextLines are the context line defaults by languageId, see
+// SetLanguageContextLines
+var languageContextLines map[string]int
+
+// SetLanguageContextLines sets how many context lines are shown around references in
+// the files of each language, keyed by languageId such as "go" or "cpp". Languages
+// that aren't listed use LSP_CONTEXT_LINES.
+func SetLanguageContextLines(lines map[string]int) {
+	languageContextLines = lines
+}
+
+// fileContextLines returns the default number of context lines for a file, the one
+// set for its language or else the one configured by the environment
+func fileContextLines(client *lsp.Client, path string) int {
+	if lines, ok := languageContextLines[string(client.LanguageID(path))]; ok {
+		return lines
+	}
+	return defaultContextLines()
+}
+
+// DefaultMaxReferenceFiles is how many files FindReferences shows per page
+const DefaultMaxReferenceFiles = 50
+
+// ReferenceOptions controls what FindReferences returns
+type ReferenceOptions struct {
+	// ContextLines is the number of lines shown around each reference, or
+	// DefaultContextLines
+	ContextLines int
+	// Kind is one of the ReferenceKind constants. Empty means ReferenceKindAll.
+	Kind string
+	// ExcludeGlobs drops references in matching files, see matchesGlob. nil uses
+	// the globs in LSP_EXCLUDE_GLOBS.
+	ExcludeGlobs []string
+	// MaxFiles is the number of files per page. 0 uses DefaultMaxReferenceFiles.
+	MaxFiles int
+	// MaxRefsPerFile caps the references shown in each file. 0 shows all of them.
+	MaxRefsPerFile int
+	// Page is the 1-indexed page of files to return. 0 returns the first page.
+	Page int
+	// IncludeDeclaration lists the declaration of the symbol with its references
+	// and marks it in the output
+	IncludeDeclaration bool
+	// HideLineNumbers leaves the line numbers out of the context lines
+	HideLineNumbers bool
+	// HighlightMatch marks the lines holding a reference with "> "
+	HighlightMatch bool
+	// UnderlineMatch puts a line of carets under each reference, to tell which
+	// token on a line it is
+	UnderlineMatch bool
+	// Container keeps only the references made from inside a symbol of this name,
+	// such as a class and its methods. It costs a documentSymbol request for every
+	// file with references, so it is much slower on widely used symbols.
+	Container string
+	// SummaryOnly returns the number of references in each file and their lines,
+	// without reading the files. Paging and MaxRefsPerFile don't apply.
+	SummaryOnly bool
+}
+
+// snippetOptions controls how the source lines around locations are shown
+type snippetOptions struct {
+	// contextLines may be DefaultContextLines to use the default for each file
+	contextLines    int
+	hideLineNumbers bool
+	highlightMatch  bool
+	underlineMatch  bool
+}
+
+func FindReferences(ctx context.Context, client *lsp.Client, symbolName string, opts ReferenceOptions) (string, error) {
+	symbolName, err := NormalizeSymbolName(symbolName)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch symbol: %v", err)
+		return "", err
 	}
 
-	results, err := symbolResult.Results()
+	results, err := findSymbols(ctx, client, symbolName)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse results: %v", err)
+		return "", err
 	}
+	opened := make(map[protocol.DocumentUri]bool)
+	defer closeURIs(ctx, client, opened)
+	return findSymbolReferences(ctx, client, symbolName, results, opts, opened)
+}
 
-	var allReferences []string
+// findSymbolReferences is FindReferences for symbols already looked up with
+// findSymbols. Files already in opened are not opened again, and files it opens are
+// added.
+func findSymbolReferences(ctx context.Context, client *lsp.Client, symbolName string, results []protocol.WorkspaceSymbolResult, opts ReferenceOptions, opened map[protocol.DocumentUri]bool) (string, error) {
+	excludeGlobs := opts.ExcludeGlobs
+	if excludeGlobs == nil {
+		excludeGlobs = excludeGlobsFromEnv()
+	}
+	maxFiles := opts.MaxFiles
+	if maxFiles <= 0 {
+		maxFiles = DefaultMaxReferenceFiles
+	}
+	page := max(opts.Page, 1)
+	snippet := snippetOptions{
+		contextLines:    opts.ContextLines,
+		hideLineNumbers: opts.HideLineNumbers,
+		highlightMatch:  opts.HighlightMatch,
+		underlineMatch:  opts.UnderlineMatch,
+	}
+
+	var refsBySymbol [][]protocol.Location
+	var declarations []protocol.Location
+	var excludedFiles, omittedRefs int
 	for _, symbol := range results {
 		// Trust clangd's workspace/symbol results - it already handles qualified name matching.
 		// When we query "TestClass::method", clangd returns name="method" with container="TestClass"
@@ -59,89 +161,555 @@ func FindReferences(ctx context.Context, client *lsp.Client, symbolName string)
 				Position: loc.Range.Start,
 			},
 			Context: protocol.ReferenceContext{
-				IncludeDeclaration: false,
+				IncludeDeclaration: opts.IncludeDeclaration,
 			},
 		}
 		// File is likely to be opened already, but may not be.
-		err := client.OpenFile(ctx, loc.URI.Path())
-		if err != nil {
-			toolsLogger.Error("Error opening file: %v", err)
-			continue
+		if !opened[loc.URI] {
+			err := openURI(ctx, client, loc.URI)
+			if err != nil {
+				toolsLogger.Error("Error opening file: %v", err)
+				continue
+			}
+			opened[loc.URI] = true
 		}
 		refs, err := client.References(ctx, refsParams)
 		if err != nil {
 			return "", fmt.Errorf("failed to get references: %v", err)
 		}
+		refs = dedupeLocations(normalizeLocationURIs(refs))
+		if opts.IncludeDeclaration {
+			if declaration, ok := declarationReference(refs, loc); ok {
+				declarations = append(declarations, declaration)
+			}
+		}
 
-		// Group references by file
-		refsByFile := make(map[protocol.DocumentUri][]protocol.Location)
-		for _, ref := range refs {
-			refsByFile[ref.URI] = append(refsByFile[ref.URI], ref)
+		// Exclude files first so they don't cost highlight requests
+		refs, excluded := excludeLocations(refs, excludeGlobs)
+		excludedFiles += excluded
+
+		refs, err = filterReferencesByKind(ctx, client, refs, opts.Kind)
+		if err != nil {
+			return "", err
 		}
 
-		// Get sorted list of URIs
-		uris := make([]string, 0, len(refsByFile))
-		for uri := range refsByFile {
-			uris = append(uris, string(uri))
+		refs, err = filterReferencesByContainer(ctx, client, refs, opts.Container)
+		if err != nil {
+			return "", err
 		}
-		sort.Strings(uris)
 
-		// Process each file's references in sorted order
-		for _, uriStr := range uris {
-			uri := protocol.DocumentUri(uriStr)
-			fileRefs := refsByFile[uri]
-			filePath := strings.TrimPrefix(uriStr, "file://")
+		if !opts.SummaryOnly {
+			var omitted int
+			refs, omitted = capLocationsPerFile(refs, opts.MaxRefsPerFile)
+			omittedRefs += omitted
+		}
 
-			// Format file header
-			fileInfo := fmt.Sprintf("---\n\n%s\nReferences in File: %d\n",
-				filePath,
-				len(fileRefs),
-			)
+		refsBySymbol = append(refsBySymbol, refs)
+	}
 
-			// Format locations with context
-			fileContent, err := os.ReadFile(filePath)
-			if err != nil {
-				// Log error but continue with other files
-				allReferences = append(allReferences, fileInfo+"\nError reading file: "+err.Error())
-				continue
+	if opts.SummaryOnly {
+		var allRefs []protocol.Location
+		for _, refs := range refsBySymbol {
+			allRefs = append(allRefs, refs...)
+		}
+		allRefs = dedupeLocations(allRefs)
+
+		if outputFormat() == OutputFormatJSON {
+			jsonResult := referenceSummaryResult(symbolName, allRefs)
+			jsonResult.ExcludedFiles = excludedFiles
+			return formatJSON(jsonResult)
+		}
+
+		output := formatReferenceSummary(symbolName, allRefs)
+		if excludedFiles > 0 {
+			output += fmt.Sprintf("\nExcluded references in %d files matching: %s\n", excludedFiles, strings.Join(excludeGlobs, ", "))
+		}
+		return output, nil
+	}
+
+	// Pages are cut from the files in output order, by symbol and then by path, so
+	// every page continues where the previous one ended
+	totalFiles := 0
+	for _, refs := range refsBySymbol {
+		totalFiles += len(sortedURIs(refs))
+	}
+	first := (page - 1) * maxFiles
+	last := min(first+maxFiles, totalFiles)
+
+	var allReferences, unreadable []string
+	jsonResult := ReferencesResult{Symbol: symbolName, Files: []FileReferencesResult{}}
+	fileIndex := 0
+	for _, refs := range refsBySymbol {
+		inPage := make(map[protocol.DocumentUri]bool)
+		for _, uri := range sortedURIs(refs) {
+			if fileIndex >= first && fileIndex < last {
+				inPage[uri] = true
 			}
+			fileIndex++
+		}
 
-			lines := strings.Split(string(fileContent), "\n")
+		var pageRefs []protocol.Location
+		for _, ref := range refs {
+			if inPage[ref.URI] {
+				pageRefs = append(pageRefs, ref)
+			}
+		}
+		if len(pageRefs) == 0 {
+			continue
+		}
 
-			// Track reference locations for header display
-			var locStrings []string
-			for _, ref := range fileRefs {
-				locStr := fmt.Sprintf("L%d:C%d",
-					ref.Range.Start.Line+1,
-					ref.Range.Start.Character+1)
-				locStrings = append(locStrings, locStr)
+		if outputFormat() == OutputFormatJSON {
+			jsonResult.Files = append(jsonResult.Files, fileReferencesResults(ctx, client, pageRefs, declarations, snippet)...)
+			continue
+		}
+
+		formatted, unreadableFiles := formatLocationsByFile(ctx, client, pageRefs, declarations, snippet, "References")
+		allReferences = append(allReferences, formatted...)
+		unreadable = append(unreadable, unreadableFiles...)
+	}
+
+	if outputFormat() == OutputFormatJSON {
+		jsonResult.ExcludedFiles = excludedFiles
+		jsonResult.OmittedReferences = omittedRefs
+		if totalFiles > maxFiles {
+			jsonResult.TotalFiles = totalFiles
+			if last < totalFiles {
+				jsonResult.NextPage = page + 1
 			}
+		}
+		return formatJSON(jsonResult)
+	}
 
-			// Collect lines to display using the utility function
-			linesToShow, err := GetLineRangesToDisplay(ctx, client, fileRefs, len(lines), contextLines)
-			if err != nil {
-				// Log error but continue with other files
+	var notes []string
+	if totalFiles > maxFiles && first < totalFiles {
+		note := fmt.Sprintf("Showing files %d-%d of %d", first+1, last, totalFiles)
+		if last < totalFiles {
+			note += fmt.Sprintf("; pass page=%d for more", page+1)
+		}
+		notes = append(notes, note)
+	}
+	if omittedRefs > 0 {
+		notes = append(notes, fmt.Sprintf("Showing at most %d references per file, %d references omitted", opts.MaxRefsPerFile, omittedRefs))
+	}
+	if excludedFiles > 0 {
+		notes = append(notes, fmt.Sprintf("Excluded references in %d files matching: %s", excludedFiles, strings.Join(excludeGlobs, ", ")))
+	}
+	if note := unreadableNote(unreadable); note != "" {
+		notes = append(notes, note)
+	}
+
+	if len(allReferences) == 0 {
+		message := fmt.Sprintf("No references found for symbol: %s", symbolName)
+		if opts.Kind != "" && opts.Kind != ReferenceKindAll {
+			message = fmt.Sprintf("No %s references found for symbol: %s", opts.Kind, symbolName)
+		}
+		if totalFiles > 0 {
+			message = fmt.Sprintf("Page %d is past the end, references to %s are in %d files (%d per page)", page, symbolName, totalFiles, maxFiles)
+		}
+		for _, note := range notes {
+			message += "\n" + note
+		}
+		return message, nil
+	}
+
+	trailer := ""
+	if len(notes) > 0 {
+		trailer = "\n---\n\n" + strings.Join(notes, "\n") + "\n"
+	}
+	return joinSections(allReferences, "\n", trailer), nil
+}
+
+// formatReferenceSummary lists how many references each file has and on which
+// lines, after the totals
+func formatReferenceSummary(symbolName string, refs []protocol.Location) string {
+	uris := sortedURIs(refs)
+	if len(uris) == 0 {
+		return fmt.Sprintf("No references found for symbol: %s\n", symbolName)
+	}
+	refs = sortLocations(refs)
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("References to %s: %d in %d files\n\n", symbolName, len(refs), len(uris)))
+	for _, uri := range uris {
+		var count int
+		var lines []string
+		seen := make(map[uint32]bool)
+		for _, ref := range refs {
+			if ref.URI != uri {
 				continue
 			}
+			count++
+			if !seen[ref.Range.Start.Line] {
+				seen[ref.Range.Start.Line] = true
+				lines = append(lines, fmt.Sprintf("L%d", ref.Range.Start.Line+1))
+			}
+		}
+		output.WriteString(fmt.Sprintf("%s: %d (%s)\n", referencePath(uri), count, strings.Join(lines, ", ")))
+	}
+	return output.String()
+}
+
+// referenceSummaryResult is the structured form of formatReferenceSummary
+func referenceSummaryResult(symbolName string, refs []protocol.Location) ReferencesResult {
+	result := ReferencesResult{
+		Symbol:          symbolName,
+		Files:           []FileReferencesResult{},
+		TotalReferences: len(refs),
+	}
+	refs = sortLocations(refs)
+	for _, uri := range sortedURIs(refs) {
+		file := FileReferencesResult{File: referencePath(uri), References: []ResultRange{}}
+		for _, ref := range refs {
+			if ref.URI == uri {
+				file.References = append(file.References, newResultRange(ref.Range))
+			}
+		}
+		result.Files = append(result.Files, file)
+	}
+	return result
+}
+
+// sortLocations returns a copy of locations ordered by position
+func sortLocations(locations []protocol.Location) []protocol.Location {
+	sorted := slices.Clone(locations)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return positionBefore(sorted[i].Range.Start, sorted[j].Range.Start)
+	})
+	return sorted
+}
+
+// referencePath is the path shown for a file of references, or the URI itself for
+// documents that aren't files
+func referencePath(uri protocol.DocumentUri) string {
+	if !isFileURI(uri) {
+		return string(uri)
+	}
+	return uriToPath(uri)
+}
+
+// declarationReference returns the reference at the declaration of a symbol, the
+// first one that starts within the range workspace/symbol gave for it. Servers
+// report either the name or the whole declaration as that range.
+func declarationReference(refs []protocol.Location, symbolLoc protocol.Location) (protocol.Location, bool) {
+	symbolPath := canonicalPath(uriToPath(symbolLoc.URI))
+
+	var declaration protocol.Location
+	found := false
+	for _, ref := range refs {
+		inSymbol := ref.Range.Start == symbolLoc.Range.Start || containsPosition(symbolLoc.Range, ref.Range.Start)
+		if !inSymbol || canonicalPath(uriToPath(ref.URI)) != symbolPath {
+			continue
+		}
+		if !found || positionBefore(ref.Range.Start, declaration.Range.Start) {
+			declaration = ref
+			found = true
+		}
+	}
+	return declaration, found
+}
+
+// positionBefore reports whether a comes strictly before b
+func positionBefore(a, b protocol.Position) bool {
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Character < b.Character
+}
+
+// dedupeLocations drops locations that start at the same position of the same file
+// as an earlier one, which servers sometimes report twice
+func dedupeLocations(locations []protocol.Location) []protocol.Location {
+	type key struct {
+		uri protocol.DocumentUri
+		pos protocol.Position
+	}
+	seen := make(map[key]bool, len(locations))
+	deduped := make([]protocol.Location, 0, len(locations))
+	for _, loc := range locations {
+		k := key{loc.URI, loc.Range.Start}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		deduped = append(deduped, loc)
+	}
+	return deduped
+}
+
+// sortedURIs returns the distinct URIs of locations in sorted order, the order in
+// which formatLocationsByFile lists files
+func sortedURIs(locations []protocol.Location) []protocol.DocumentUri {
+	seen := make(map[protocol.DocumentUri]bool)
+	var uris []protocol.DocumentUri
+	for _, loc := range locations {
+		if !seen[loc.URI] {
+			seen[loc.URI] = true
+			uris = append(uris, loc.URI)
+		}
+	}
+	sort.Slice(uris, func(i, j int) bool { return uris[i] < uris[j] })
+	return uris
+}
+
+// capLocationsPerFile keeps the first maxPerFile locations of each file by position.
+// It returns the kept locations and how many were dropped. 0 keeps everything.
+func capLocationsPerFile(locations []protocol.Location, maxPerFile int) ([]protocol.Location, int) {
+	if maxPerFile <= 0 {
+		return locations, 0
+	}
+
+	byFile := make(map[protocol.DocumentUri][]protocol.Location)
+	for _, loc := range locations {
+		byFile[loc.URI] = append(byFile[loc.URI], loc)
+	}
+
+	var kept []protocol.Location
+	omitted := 0
+	for _, uri := range sortedURIs(locations) {
+		fileLocs := byFile[uri]
+		if len(fileLocs) > maxPerFile {
+			sort.SliceStable(fileLocs, func(i, j int) bool {
+				a, b := fileLocs[i].Range.Start, fileLocs[j].Range.Start
+				if a.Line != b.Line {
+					return a.Line < b.Line
+				}
+				return a.Character < b.Character
+			})
+			omitted += len(fileLocs) - maxPerFile
+			fileLocs = fileLocs[:maxPerFile]
+		}
+		kept = append(kept, fileLocs...)
+	}
+	return kept, omitted
+}
+
+// fileLocations holds the locations within one file together with the numbered
+// source lines around them
+type fileLocations struct {
+	FilePath  string
+	Locations []protocol.Location
+	Snippet   string
+	ReadErr   error
+}
+
+// maxFileWorkers bounds how many files collectLocationsByFile reads and formats at once
+var maxFileWorkers = 8
+
+// collectLocationsByFile groups locations by file, sorted by path, and extracts
+// each file's locations with surrounding context. Files are processed concurrently
+// and the remaining files are skipped once ctx is done.
+func collectLocationsByFile(ctx context.Context, client *lsp.Client, locations []protocol.Location, snippet snippetOptions) []fileLocations {
+	locations = dedupeLocations(locations)
+
+	// Group locations by file
+	locsByFile := make(map[protocol.DocumentUri][]protocol.Location)
+	for _, loc := range locations {
+		locsByFile[loc.URI] = append(locsByFile[loc.URI], loc)
+	}
+
+	// Get sorted list of URIs
+	uris := make([]string, 0, len(locsByFile))
+	for uri := range locsByFile {
+		uris = append(uris, string(uri))
+	}
+	sort.Strings(uris)
+
+	// Each worker fills in the slots of the files it handles, so the results keep
+	// the sorted order
+	results := make([]fileLocations, len(uris))
+	kept := make([]bool, len(uris))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(maxFileWorkers, len(uris)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				uri := protocol.DocumentUri(uris[i])
+				results[i], kept[i] = collectFileLocations(ctx, client, uri, locsByFile[uri], snippet)
+			}
+		}()
+	}
 
-			// Convert to line ranges using the utility function
-			lineRanges := ConvertLinesToRanges(linesToShow, len(lines))
+feed:
+	for i := range uris {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
 
-			// Format with locations in header
-			formattedOutput := fileInfo
-			if len(locStrings) > 0 {
-				formattedOutput += "At: " + strings.Join(locStrings, ", ") + "\n"
+	var collected []fileLocations
+	for i, file := range results {
+		if kept[i] {
+			collected = append(collected, file)
+		}
+	}
+	return collected
+}
+
+// collectFileLocations extracts one file's locations with surrounding context. It
+// returns false if the file should be left out of the results.
+func collectFileLocations(ctx context.Context, client *lsp.Client, uri protocol.DocumentUri, locations []protocol.Location, snippet snippetOptions) (fileLocations, bool) {
+	file := fileLocations{
+		FilePath:  uriToPath(uri),
+		Locations: locations,
+	}
+	if !isFileURI(uri) {
+		file.FilePath = string(uri)
+	}
+
+	fileContent, err := client.ReadDocument(ctx, uri)
+	if err != nil && !isFileURI(uri) {
+		// Documents the server can't provide are described by the hover of
+		// their first location instead
+		if hover, hoverErr := hoverDefinition(ctx, client, file.Locations[0]); hoverErr == nil {
+			file.Snippet = hover + "\n"
+			return file, true
+		}
+	}
+	if err != nil {
+		// Keep the error but continue with other files
+		file.ReadErr = err
+		return file, true
+	}
+
+	lines := splitLines(fileContent)
+
+	// Collect lines to display using the utility function
+	contextLines := snippet.contextLines
+	if contextLines < 0 {
+		contextLines = fileContextLines(client, file.FilePath)
+	}
+	linesToShow, err := GetLineRangesToDisplay(ctx, client, file.Locations, len(lines), contextLines)
+	if err != nil {
+		// Log error but continue with other files
+		return file, false
+	}
+
+	// Convert to line ranges and format the content
+	lineRanges := ConvertLinesToRanges(linesToShow, len(lines))
+	var marked map[int]bool
+	if snippet.highlightMatch {
+		marked = make(map[int]bool)
+		for _, loc := range file.Locations {
+			marked[int(loc.Range.Start.Line)] = true
+		}
+	}
+	var underlines map[int]string
+	if snippet.underlineMatch {
+		matches := make([]protocol.Range, 0, len(file.Locations))
+		for _, loc := range file.Locations {
+			matches = append(matches, loc.Range)
+		}
+		underlines = matchUnderlines(lines, matches, client.PositionEncoding())
+	}
+	file.Snippet = formatLineRanges(lines, lineRanges, marked, underlines, !snippet.hideLineNumbers)
+	return file, true
+}
+
+// formatLocationsByFile groups locations by file and formats each file's
+// locations with surrounding context. label names the kind of location in
+// the file header, e.g. "References". Locations in declarations are marked.
+// Files that can't be read are left out and returned as "path: reason" entries
+// for unreadableNote.
+func formatLocationsByFile(ctx context.Context, client *lsp.Client, locations []protocol.Location, declarations []protocol.Location, snippet snippetOptions, label string) (formatted []string, unreadable []string) {
+	for _, file := range collectLocationsByFile(ctx, client, locations, snippet) {
+		if file.ReadErr != nil {
+			unreadable = append(unreadable, fmt.Sprintf("%s: %s", file.FilePath, readErrorReason(file.ReadErr)))
+			continue
+		}
+
+		hasDeclaration := false
+		for _, loc := range file.Locations {
+			hasDeclaration = hasDeclaration || slices.Contains(declarations, loc)
+		}
+
+		// Format file header
+		fileInfo := fmt.Sprintf("---\n\n%s\n%s in File: %d",
+			file.FilePath,
+			label,
+			len(file.Locations),
+		)
+		if hasDeclaration {
+			fileInfo += " (including the declaration)"
+		}
+		fileInfo += "\n"
+
+		// Track locations for header display
+		var locStrings []string
+		for _, loc := range file.Locations {
+			locStr := fmt.Sprintf("L%d:C%d",
+				loc.Range.Start.Line+1,
+				loc.Range.Start.Character+1)
+			if slices.Contains(declarations, loc) {
+				locStr += " (declaration)"
 			}
+			locStrings = append(locStrings, locStr)
+		}
 
-			// Format the content with ranges
-			formattedOutput += "\n" + FormatLinesWithRanges(lines, lineRanges)
-			allReferences = append(allReferences, formattedOutput)
+		// Format with locations in header
+		formattedOutput := fileInfo
+		if len(locStrings) > 0 {
+			formattedOutput += "At: " + strings.Join(locStrings, ", ") + "\n"
 		}
+
+		formattedOutput += "\n" + file.Snippet
+		formatted = append(formatted, formattedOutput)
 	}
 
-	if len(allReferences) == 0 {
-		return fmt.Sprintf("No references found for symbol: %s", symbolName), nil
+	return formatted, unreadable
+}
+
+// unreadableNote lists the files formatLocationsByFile could not read, or returns
+// an empty string if there are none
+func unreadableNote(unreadable []string) string {
+	if len(unreadable) == 0 {
+		return ""
+	}
+	files := "files"
+	if len(unreadable) == 1 {
+		files = "file"
+	}
+	return fmt.Sprintf("Could not read %d %s:\n  %s", len(unreadable), files, strings.Join(unreadable, "\n  "))
+}
+
+// readErrorReason describes why a file could not be read, telling missing files
+// and permission problems apart from other errors
+func readErrorReason(err error) string {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return "file not found"
+	case errors.Is(err, fs.ErrPermission):
+		return "permission denied"
 	}
+	return err.Error()
+}
 
-	return strings.Join(allReferences, "\n"), nil
+// fileReferencesResults converts locations to the per-file JSON result type, setting
+// Declaration for a file holding one of declarations
+func fileReferencesResults(ctx context.Context, client *lsp.Client, locations []protocol.Location, declarations []protocol.Location, snippet snippetOptions) []FileReferencesResult {
+	var results []FileReferencesResult
+	for _, file := range collectLocationsByFile(ctx, client, locations, snippet) {
+		result := FileReferencesResult{
+			File:       file.FilePath,
+			References: make([]ResultRange, 0, len(file.Locations)),
+			Snippet:    file.Snippet,
+		}
+		for _, loc := range file.Locations {
+			result.References = append(result.References, newResultRange(loc.Range))
+			if slices.Contains(declarations, loc) {
+				declaration := newResultRange(loc.Range)
+				result.Declaration = &declaration
+			}
+		}
+		if file.ReadErr != nil {
+			result.Error = readErrorReason(file.ReadErr)
+		}
+		results = append(results, result)
+	}
+	return results
 }
@@ -0,0 +1,277 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newNullServerClient connects to a language server that answers every request
+// with null
+func newNullServerClient(t *testing.T) *lsp.Client {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for {
+			msg, err := lsp.ReadMessage(reader)
+			if err != nil {
+				return
+			}
+			if msg.ID == nil {
+				continue
+			}
+			if err := lsp.WriteMessage(conn, &lsp.Message{JSONRPC: "2.0", ID: msg.ID, Result: json.RawMessage("null")}); err != nil {
+				return
+			}
+		}
+	}()
+
+	client, err := lsp.NewClientFromConfig(lsp.ServerConfig{Transport: lsp.TransportTCP, Address: listener.Addr().String()})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestCapLocationsPerFile(t *testing.T) {
+	loc := func(uri string, line uint32) protocol.Location {
+		return protocol.Location{
+			URI:   protocol.DocumentUri(uri),
+			Range: protocol.Range{Start: protocol.Position{Line: line}},
+		}
+	}
+
+	locations := []protocol.Location{
+		loc("file:///b.go", 9),
+		loc("file:///a.go", 30),
+		loc("file:///b.go", 2),
+		loc("file:///a.go", 10),
+		loc("file:///a.go", 20),
+	}
+
+	kept, omitted := capLocationsPerFile(locations, 2)
+	assert.Equal(t, []protocol.Location{
+		loc("file:///a.go", 10),
+		loc("file:///a.go", 20),
+		loc("file:///b.go", 9),
+		loc("file:///b.go", 2),
+	}, kept)
+	assert.Equal(t, 1, omitted)
+
+	kept, omitted = capLocationsPerFile(locations, 0)
+	assert.Equal(t, locations, kept)
+	assert.Zero(t, omitted)
+}
+
+func TestSortedURIs(t *testing.T) {
+	locations := []protocol.Location{
+		{URI: "file:///c.go"},
+		{URI: "file:///a.go"},
+		{URI: "file:///c.go"},
+		{URI: "file:///b.go"},
+	}
+	assert.Equal(t, []protocol.DocumentUri{"file:///a.go", "file:///b.go", "file:///c.go"}, sortedURIs(locations))
+}
+
+func TestDeclarationReference(t *testing.T) {
+	ref := func(uri string, line, character uint32) protocol.Location {
+		start := protocol.Position{Line: line, Character: character}
+		return protocol.Location{
+			URI:   protocol.DocumentUri(uri),
+			Range: protocol.Range{Start: start, End: protocol.Position{Line: line, Character: character + 3}},
+		}
+	}
+	span := func(uri string, startLine, startChar, endLine, endChar uint32) protocol.Location {
+		return protocol.Location{
+			URI: protocol.DocumentUri(uri),
+			Range: protocol.Range{
+				Start: protocol.Position{Line: startLine, Character: startChar},
+				End:   protocol.Position{Line: endLine, Character: endChar},
+			},
+		}
+	}
+
+	refs := []protocol.Location{
+		ref("file:///b.go", 4, 1),
+		ref("file:///a.go", 12, 8),
+		ref("file:///a.go", 10, 5),
+		ref("file:///a.go", 30, 2),
+	}
+
+	testCases := []struct {
+		name      string
+		symbolLoc protocol.Location
+		expected  protocol.Location
+		found     bool
+	}{
+		{
+			name:      "Name range",
+			symbolLoc: span("file:///a.go", 10, 5, 10, 8),
+			expected:  ref("file:///a.go", 10, 5),
+			found:     true,
+		},
+		{
+			name:      "Whole declaration range picks the first reference in it",
+			symbolLoc: span("file:///a.go", 9, 0, 14, 1),
+			expected:  ref("file:///a.go", 10, 5),
+			found:     true,
+		},
+		{
+			name:      "Empty range",
+			symbolLoc: span("file:///a.go", 30, 2, 30, 2),
+			expected:  ref("file:///a.go", 30, 2),
+			found:     true,
+		},
+		{
+			name:      "Other file",
+			symbolLoc: span("file:///c.go", 10, 5, 10, 8),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			declaration, found := declarationReference(refs, tc.symbolLoc)
+			assert.Equal(t, tc.found, found)
+			if tc.found {
+				assert.Equal(t, tc.expected, declaration)
+			}
+		})
+	}
+}
+
+func TestDedupeLocations(t *testing.T) {
+	loc := func(uri string, line, character, endCharacter uint32) protocol.Location {
+		return protocol.Location{
+			URI: protocol.DocumentUri(uri),
+			Range: protocol.Range{
+				Start: protocol.Position{Line: line, Character: character},
+				End:   protocol.Position{Line: line, Character: endCharacter},
+			},
+		}
+	}
+
+	// Clustered references on one line, some reported twice
+	locations := []protocol.Location{
+		loc("file:///a.go", 4, 2, 5),
+		loc("file:///a.go", 4, 10, 13),
+		loc("file:///a.go", 4, 2, 5),
+		loc("file:///a.go", 4, 20, 23),
+		loc("file:///a.go", 4, 10, 13),
+		loc("file:///b.go", 4, 2, 5),
+		loc("file:///a.go", 4, 2, 6),
+	}
+
+	assert.Equal(t, []protocol.Location{
+		loc("file:///a.go", 4, 2, 5),
+		loc("file:///a.go", 4, 10, 13),
+		loc("file:///a.go", 4, 20, 23),
+		loc("file:///b.go", 4, 2, 5),
+	}, dedupeLocations(locations))
+}
+
+func TestFormatReferenceSummary(t *testing.T) {
+	loc := func(uri string, line, character uint32) protocol.Location {
+		return protocol.Location{
+			URI:   protocol.DocumentUri(uri),
+			Range: protocol.Range{Start: protocol.Position{Line: line, Character: character}},
+		}
+	}
+
+	refs := []protocol.Location{
+		loc("file:///src/b.go", 9, 2),
+		loc("file:///src/a.go", 21, 4),
+		loc("file:///src/a.go", 2, 0),
+		loc("file:///src/a.go", 21, 12),
+	}
+
+	assert.Equal(t,
+		"References to Handler: 4 in 2 files\n\n"+
+			"/src/a.go: 3 (L3, L22)\n"+
+			"/src/b.go: 1 (L10)\n",
+		formatReferenceSummary("Handler", refs))
+	assert.Equal(t, "No references found for symbol: Handler\n", formatReferenceSummary("Handler", nil))
+
+	result := referenceSummaryResult("Handler", refs)
+	assert.Equal(t, 4, result.TotalReferences)
+	assert.Len(t, result.Files, 2)
+	assert.Equal(t, "/src/a.go", result.Files[0].File)
+	assert.Equal(t, 3, result.Files[0].References[0].Start.Line)
+	assert.Len(t, result.Files[0].References, 3)
+}
+
+func TestUnreadableNote(t *testing.T) {
+	dir := t.TempDir()
+	_, notFound := os.ReadFile(filepath.Join(dir, "missing.go"))
+	_, isDir := os.ReadFile(dir)
+
+	assert.Equal(t, "file not found", readErrorReason(notFound))
+	assert.Equal(t, "permission denied", readErrorReason(fmt.Errorf("open: %w", fs.ErrPermission)))
+	assert.Contains(t, readErrorReason(isDir), "is a directory")
+
+	assert.Equal(t, "", unreadableNote(nil))
+	assert.Equal(t, "Could not read 1 file:\n  /src/a.go: file not found", unreadableNote([]string{"/src/a.go: file not found"}))
+	assert.Equal(t, "Could not read 2 files:\n  /src/a.go: file not found\n  /src/b.go: permission denied",
+		unreadableNote([]string{"/src/a.go: file not found", "/src/b.go: permission denied"}))
+}
+
+func TestFileContextLines(t *testing.T) {
+	t.Setenv("LSP_CONTEXT_LINES", "3")
+	SetLanguageContextLines(map[string]int{"cpp": 1, "go": 8})
+	defer SetLanguageContextLines(nil)
+
+	client := &lsp.Client{}
+	assert.Equal(t, 8, fileContextLines(client, "/src/main.go"))
+	assert.Equal(t, 1, fileContextLines(client, "/src/widget.cpp"))
+	assert.Equal(t, 3, fileContextLines(client, "/src/app.py"))
+}
+
+func TestCollectLocationsByFileKeepsSequentialOrder(t *testing.T) {
+	client := newNullServerClient(t)
+	dir := t.TempDir()
+
+	var locations []protocol.Location
+	for i := range 20 {
+		path := filepath.Join(dir, fmt.Sprintf("file%02d.go", (i*7)%20))
+		content := strings.Repeat(fmt.Sprintf("line of %s\n", filepath.Base(path)), 10)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		for _, line := range []uint32{6, 2} {
+			locations = append(locations, protocol.Location{
+				URI:   protocol.DocumentUri("file://" + path),
+				Range: protocol.Range{Start: protocol.Position{Line: line}, End: protocol.Position{Line: line, Character: 4}},
+			})
+		}
+	}
+	snippet := snippetOptions{contextLines: 1}
+
+	defer func(workers int) { maxFileWorkers = workers }(maxFileWorkers)
+	maxFileWorkers = 1
+	sequential := collectLocationsByFile(context.Background(), client, locations, snippet)
+	maxFileWorkers = 8
+	parallel := collectLocationsByFile(context.Background(), client, locations, snippet)
+
+	require.Len(t, sequential, 20)
+	assert.Equal(t, sequential, parallel)
+	for i, file := range parallel {
+		assert.Equal(t, filepath.Join(dir, fmt.Sprintf("file%02d.go", i)), file.FilePath)
+		assert.NotEmpty(t, file.Snippet)
+	}
+}
@@ -0,0 +1,267 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// RenameFile moves a file and lets the language server update the imports and
+// includes that refer to it. The server is asked for those edits with
+// workspace/willRenameFiles, they are applied, the file is renamed on disk and the
+// server is told with workspace/didRenameFiles. Each request is only sent if the
+// server registered for it with a filter matching the file, so with other servers
+// the file is just moved. If the file can't be moved the edits are reverted. With
+// dryRun set the edits are returned as a diff and nothing is changed.
+func RenameFile(ctx context.Context, client *lsp.Client, oldPath, newPath string, dryRun bool) (string, error) {
+	oldPath, err := filepath.Abs(oldPath)
+	if err != nil {
+		return "", fmt.Errorf("invalid path %s: %v", oldPath, err)
+	}
+	newPath, err = filepath.Abs(newPath)
+	if err != nil {
+		return "", fmt.Errorf("invalid path %s: %v", newPath, err)
+	}
+
+	info, err := os.Stat(oldPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat %s: %v", oldPath, err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("%s is a directory", oldPath)
+	}
+	if _, err := os.Stat(newPath); err == nil {
+		return "", fmt.Errorf("%s already exists", newPath)
+	}
+
+	params := protocol.RenameFilesParams{
+		Files: []protocol.FileRename{{
+			OldURI: "file://" + oldPath,
+			NewURI: "file://" + newPath,
+		}},
+	}
+	willRename, didRename := fileRenameSupport(client.Capabilities(), oldPath)
+
+	var edit protocol.WorkspaceEdit
+	if willRename {
+		edit, err = client.WillRenameFiles(ctx, params)
+		if err != nil {
+			if !isMethodNotSupported(err) {
+				return "", fmt.Errorf("failed to get edits for the rename: %v", err)
+			}
+			willRename = false
+		}
+	}
+
+	if dryRun {
+		if !willRename {
+			return fmt.Sprintf("Dry run: %s would be renamed to %s. The language server doesn't update references to renamed files, so no other files would change.", oldPath, newPath), nil
+		}
+		diff, err := previewWorkspaceEdit(edit)
+		if err != nil {
+			return "", fmt.Errorf("failed to preview changes: %v", err)
+		}
+		if diff == "" {
+			return fmt.Sprintf("Dry run: %s would be renamed to %s. No other files would change.", oldPath, newPath), nil
+		}
+		return fmt.Sprintf("Dry run: %s would be renamed to %s with these changes. No files were changed.\n\n%s", oldPath, newPath, diff), nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %v", err)
+	}
+
+	// The edits are made to the files at their current paths, including the file
+	// being renamed, so they're applied first and reverted if the rename fails
+	editsByFile := countEditsByFile(edit)
+	snapshot, err := snapshotFiles(editsByFile)
+	if err != nil {
+		return "", err
+	}
+	if err := applyWorkspaceEdit(ctx, client, edit); err != nil {
+		return "", fmt.Errorf("failed to apply changes: %v", err)
+	}
+
+	if err := os.Rename(oldPath, newPath); err != nil {
+		if restoreErr := restoreFiles(ctx, client, snapshot); restoreErr != nil {
+			return "", fmt.Errorf("failed to rename file: %v. Reverting the updated references also failed: %v", err, restoreErr)
+		}
+		return "", fmt.Errorf("failed to rename file: %v. The updated references were reverted", err)
+	}
+	if err := client.CloseRemovedFile(ctx, oldPath); err != nil {
+		toolsLogger.Warn("Failed to close %s: %v", oldPath, err)
+	}
+
+	if didRename {
+		if err := client.DidRenameFiles(ctx, params); err != nil {
+			toolsLogger.Warn("Failed to notify the server of the rename: %v", err)
+		}
+	}
+	client.InvalidateSymbolCache()
+
+	return formatFileRename(oldPath, newPath, editsByFile, willRename) + formatResourceOperations(edit), nil
+}
+
+// fileRenameSupport reports whether the server registered for willRenameFiles
+// requests and didRenameFiles notifications about the file at path
+func fileRenameSupport(capabilities protocol.ServerCapabilities, path string) (willRename, didRename bool) {
+	if capabilities.Workspace == nil || capabilities.Workspace.FileOperations == nil {
+		return false, false
+	}
+	fileOperations := capabilities.Workspace.FileOperations
+	return matchesFileOperationFilters(fileOperations.WillRename, path), matchesFileOperationFilters(fileOperations.DidRename, path)
+}
+
+// matchesFileOperationFilters reports whether a file matches one of the filters of
+// a file operation registration. A nil registration matches nothing.
+func matchesFileOperationFilters(registration *protocol.FileOperationRegistrationOptions, path string) bool {
+	if registration == nil {
+		return false
+	}
+	for _, filter := range registration.Filters {
+		if filter.Scheme != "" && filter.Scheme != "file" {
+			continue
+		}
+		if filter.Pattern.Matches != nil && *filter.Pattern.Matches != protocol.FilePattern {
+			continue
+		}
+		ignoreCase := filter.Pattern.Options != nil && filter.Pattern.Options.IgnoreCase
+		re, err := globToRegexp(filter.Pattern.Glob, ignoreCase)
+		if err != nil {
+			toolsLogger.Warn("Ignoring invalid file operation glob %q: %v", filter.Pattern.Glob, err)
+			continue
+		}
+		if re.MatchString(filepath.ToSlash(path)) {
+			return true
+		}
+	}
+	return false
+}
+
+// globToRegexp compiles an LSP glob pattern, where * and ? match within a path
+// segment, ** matches any number of segments, {a,b} matches either alternative and
+// [a-z] or [!a-z] match a character range
+func globToRegexp(glob string, ignoreCase bool) (*regexp.Regexp, error) {
+	var pattern strings.Builder
+	if ignoreCase {
+		pattern.WriteString("(?i)")
+	}
+	pattern.WriteString("^")
+	// Globs relative to the workspace match at any depth of an absolute path
+	if !strings.HasPrefix(glob, "/") && !strings.HasPrefix(glob, "**") {
+		pattern.WriteString("(?:.*/)?")
+	}
+	braces := 0
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case strings.HasPrefix(glob[i:], "**/"):
+			pattern.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			pattern.WriteString(".*")
+			i++
+		case c == '*':
+			pattern.WriteString("[^/]*")
+		case c == '?':
+			pattern.WriteString("[^/]")
+		case c == '{':
+			pattern.WriteString("(?:")
+			braces++
+		case c == '}' && braces > 0:
+			pattern.WriteString(")")
+			braces--
+		case c == ',' && braces > 0:
+			pattern.WriteString("|")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated character range")
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			pattern.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			pattern.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if braces > 0 {
+		return nil, fmt.Errorf("unterminated alternatives")
+	}
+	pattern.WriteString("$")
+	return regexp.Compile(pattern.String())
+}
+
+// snapshotFiles reads the content of files before a workspace edit changes them,
+// keyed by URI, so they can be restored with restoreFiles
+func snapshotFiles(editsByFile map[string]int) (map[protocol.DocumentUri][]byte, error) {
+	snapshot := make(map[protocol.DocumentUri][]byte, len(editsByFile))
+	for uri := range editsByFile {
+		content, err := os.ReadFile(uriToPath(protocol.DocumentUri(uri)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", uriToPath(protocol.DocumentUri(uri)), err)
+		}
+		snapshot[protocol.DocumentUri(uri)] = content
+	}
+	return snapshot, nil
+}
+
+// restoreFiles writes files back to the content in a snapshot and syncs open
+// documents with the server
+func restoreFiles(ctx context.Context, client *lsp.Client, snapshot map[protocol.DocumentUri][]byte) error {
+	var failed []string
+	for uri, content := range snapshot {
+		if err := os.WriteFile(uriToPath(uri), content, 0644); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", uriToPath(uri), err))
+			continue
+		}
+		if err := client.SyncEdits(ctx, uri); err != nil {
+			toolsLogger.Warn("Failed to sync %s with the language server: %v", uri, err)
+		}
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("%s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// formatFileRename describes a file rename and the edits made to other files for it
+func formatFileRename(oldPath, newPath string, editsByFile map[string]int, serverEdits bool) string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Renamed %s to %s.\n", oldPath, newPath))
+	if !serverEdits {
+		output.WriteString("The language server doesn't update references to renamed files, so imports of it may need updating.\n")
+		return output.String()
+	}
+
+	totalEdits := 0
+	files := make([]string, 0, len(editsByFile))
+	for uri, count := range editsByFile {
+		if count == 0 {
+			continue
+		}
+		totalEdits += count
+		files = append(files, uri)
+	}
+	if totalEdits == 0 {
+		output.WriteString("No references needed updating.\n")
+		return output.String()
+	}
+
+	sort.Strings(files)
+	output.WriteString(fmt.Sprintf("Updated %d references in %d files:\n", totalEdits, len(files)))
+	for _, uri := range files {
+		output.WriteString(fmt.Sprintf("  %s: %d edits\n", uriToPath(protocol.DocumentUri(uri)), editsByFile[uri]))
+	}
+	return output.String()
+}
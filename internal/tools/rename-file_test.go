@@ -0,0 +1,167 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileRenameSupport(t *testing.T) {
+	registration := &protocol.FileOperationRegistrationOptions{
+		Filters: []protocol.FileOperationFilter{{Scheme: "file", Pattern: protocol.FileOperationPattern{Glob: "**/*.ts"}}},
+	}
+
+	folders := protocol.FolderPattern
+	testCases := []struct {
+		name         string
+		capabilities protocol.ServerCapabilities
+		path         string
+		expectedWill bool
+		expectedDid  bool
+	}{
+		{
+			name:         "No workspace capabilities",
+			capabilities: protocol.ServerCapabilities{},
+			path:         "/src/a.ts",
+		},
+		{
+			name:         "No file operations",
+			capabilities: protocol.ServerCapabilities{Workspace: &protocol.WorkspaceOptions{}},
+			path:         "/src/a.ts",
+		},
+		{
+			name: "Both",
+			capabilities: protocol.ServerCapabilities{Workspace: &protocol.WorkspaceOptions{
+				FileOperations: &protocol.FileOperationOptions{WillRename: registration, DidRename: registration},
+			}},
+			path:         "/src/a.ts",
+			expectedWill: true,
+			expectedDid:  true,
+		},
+		{
+			name: "Only didRename",
+			capabilities: protocol.ServerCapabilities{Workspace: &protocol.WorkspaceOptions{
+				FileOperations: &protocol.FileOperationOptions{DidRename: registration},
+			}},
+			path:        "/src/a.ts",
+			expectedDid: true,
+		},
+		{
+			name: "File not matching the filters",
+			capabilities: protocol.ServerCapabilities{Workspace: &protocol.WorkspaceOptions{
+				FileOperations: &protocol.FileOperationOptions{WillRename: registration, DidRename: registration},
+			}},
+			path: "/src/a.go",
+		},
+		{
+			name: "Filter for another scheme",
+			capabilities: protocol.ServerCapabilities{Workspace: &protocol.WorkspaceOptions{
+				FileOperations: &protocol.FileOperationOptions{WillRename: &protocol.FileOperationRegistrationOptions{
+					Filters: []protocol.FileOperationFilter{{Scheme: "untitled", Pattern: protocol.FileOperationPattern{Glob: "**/*.ts"}}},
+				}},
+			}},
+			path: "/src/a.ts",
+		},
+		{
+			name: "Filter for folders",
+			capabilities: protocol.ServerCapabilities{Workspace: &protocol.WorkspaceOptions{
+				FileOperations: &protocol.FileOperationOptions{WillRename: &protocol.FileOperationRegistrationOptions{
+					Filters: []protocol.FileOperationFilter{{Scheme: "file", Pattern: protocol.FileOperationPattern{Glob: "**", Matches: &folders}}},
+				}},
+			}},
+			path: "/src/a.ts",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			willRename, didRename := fileRenameSupport(tc.capabilities, tc.path)
+			assert.Equal(t, tc.expectedWill, willRename)
+			assert.Equal(t, tc.expectedDid, didRename)
+		})
+	}
+}
+
+func TestGlobToRegexp(t *testing.T) {
+	testCases := []struct {
+		name       string
+		glob       string
+		ignoreCase bool
+		path       string
+		expected   bool
+	}{
+		{name: "Any depth", glob: "**/*.ts", path: "/src/lib/a.ts", expected: true},
+		{name: "Other extension", glob: "**/*.ts", path: "/src/lib/a.tsx", expected: false},
+		{name: "Alternatives", glob: "**/*.{ts,js}", path: "/src/a.js", expected: true},
+		{name: "Directory then any depth", glob: "**/src/**/*.ts", path: "/work/src/a.ts", expected: true},
+		{name: "Star within a segment", glob: "/work/*.ts", path: "/work/src/a.ts", expected: false},
+		{name: "Relative glob", glob: "src/*.ts", path: "/work/src/a.ts", expected: true},
+		{name: "Character range", glob: "**/v[0-9].ts", path: "/src/v1.ts", expected: true},
+		{name: "Negated character range", glob: "**/v[!0-9].ts", path: "/src/v1.ts", expected: false},
+		{name: "Case sensitive", glob: "**/*.TS", path: "/src/a.ts", expected: false},
+		{name: "Ignoring case", glob: "**/*.TS", ignoreCase: true, path: "/src/a.ts", expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			re, err := globToRegexp(tc.glob, tc.ignoreCase)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, re.MatchString(tc.path))
+		})
+	}
+}
+
+func TestRestoreFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.ts")
+	require.NoError(t, os.WriteFile(path, []byte("import { a } from './a'\n"), 0644))
+	uri := "file://" + path
+
+	snapshot, err := snapshotFiles(map[string]int{uri: 1})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte("import { a } from './b'\n"), 0644))
+
+	require.NoError(t, restoreFiles(context.Background(), &lsp.Client{}, snapshot))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "import { a } from './a'\n", string(content))
+}
+
+func TestFormatFileRename(t *testing.T) {
+	testCases := []struct {
+		name        string
+		editsByFile map[string]int
+		serverEdits bool
+		expected    string
+	}{
+		{
+			name:        "Server without willRenameFiles",
+			serverEdits: false,
+			expected:    "Renamed /src/a.ts to /src/b.ts.\nThe language server doesn't update references to renamed files, so imports of it may need updating.\n",
+		},
+		{
+			name:        "No references",
+			editsByFile: map[string]int{},
+			serverEdits: true,
+			expected:    "Renamed /src/a.ts to /src/b.ts.\nNo references needed updating.\n",
+		},
+		{
+			name:        "Edits sorted by file",
+			editsByFile: map[string]int{"file:///src/main.ts": 1, "file:///src/index.ts": 2, "file:///src/empty.ts": 0},
+			serverEdits: true,
+			expected:    "Renamed /src/a.ts to /src/b.ts.\nUpdated 3 references in 2 files:\n  /src/index.ts: 2 edits\n  /src/main.ts: 1 edits\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, formatFileRename("/src/a.ts", "/src/b.ts", tc.editsByFile, tc.serverEdits))
+		})
+	}
+}
@@ -2,23 +2,25 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
 
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
-	"github.com/isaacphi/mcp-language-server/internal/utilities"
 )
 
 // RenameSymbol renames a symbol (variable, function, class, etc.) at the specified position
-// It uses the LSP rename functionality to handle all references across files
-func RenameSymbol(ctx context.Context, client *lsp.Client, filePath string, line, column int, newName string) (string, error) {
+// It uses the LSP rename functionality to handle all references across files. With
+// dryRun set, it returns a diff of the changes instead of writing them.
+func RenameSymbol(ctx context.Context, client *lsp.Client, filePath string, line, column int, newName string, dryRun bool) (string, error) {
 	// Open the file if not already open
 	err := client.OpenFile(ctx, filePath)
 	if err != nil {
 		return "", fmt.Errorf("could not open file: %v", err)
 	}
+	defer client.CloseFile(ctx, filePath)
 
 	// Convert 1-indexed line/column to 0-indexed for LSP protocol
 	uri := protocol.DocumentUri("file://" + filePath)
@@ -36,8 +38,11 @@ func RenameSymbol(ctx context.Context, client *lsp.Client, filePath string, line
 		NewName:  newName,
 	}
 
-	// Skip the PrepareRename check as it might not be supported by all language servers
-	// Execute the rename directly
+	// Ask the server whether the position can be renamed before requesting edits
+	oldName, err := prepareRename(ctx, client, uri, position)
+	if err != nil {
+		return "", fmt.Errorf("failed to rename symbol: %v", err)
+	}
 
 	// Execute the rename operation
 	workspaceEdit, err := client.Rename(ctx, params)
@@ -59,8 +64,8 @@ func RenameSymbol(ctx context.Context, client *lsp.Client, filePath string, line
 	}
 	var allChanges []FileChanges
 
-	// Count changes in Changes field
-	if workspaceEdit.Changes != nil {
+	// Count changes in Changes field, which is ignored when there are DocumentChanges
+	if workspaceEdit.Changes != nil && len(workspaceEdit.DocumentChanges) == 0 {
 		fileCount = len(workspaceEdit.Changes)
 		for uri, edits := range workspaceEdit.Changes {
 			changeCount += len(edits)
@@ -112,16 +117,232 @@ func RenameSymbol(ctx context.Context, client *lsp.Client, filePath string, line
 		locationsBuilder.WriteString(fmt.Sprintf("%s: %s\n", change.URI, change.Locations))
 	}
 
+	if (fileCount == 0 || changeCount == 0) && formatResourceOperations(workspaceEdit) == "" {
+		return "Failed to rename symbol. 0 occurrences found.", nil
+	}
+
+	if dryRun {
+		return renamePreview(workspaceEdit, oldName, newName, changeCount, fileCount)
+	}
+
 	// Apply the workspace edit to files:workspaceEdit
-	if err := utilities.ApplyWorkspaceEdit(workspaceEdit); err != nil {
+	if err := applyWorkspaceEdit(ctx, client, workspaceEdit); err != nil {
 		return "", fmt.Errorf("failed to apply changes: %v", err)
 	}
 
-	if fileCount == 0 || changeCount == 0 {
-		return "Failed to rename symbol. 0 occurrences found.", nil
+	// Generate a summary of changes made
+	return fmt.Sprintf("Successfully renamed symbol %sto '%s'.\nUpdated %d occurrences across %d files:\n%s%s",
+		quotedName(oldName), newName, changeCount, fileCount, locationsBuilder.String(), formatResourceOperations(workspaceEdit)), nil
+}
+
+// RenameSymbolByName resolves a symbol by name and renames it across the workspace.
+// With dryRun set, it returns a diff of the changes instead of writing them.
+func RenameSymbolByName(ctx context.Context, client *lsp.Client, symbolName, newName string, dryRun bool) (string, error) {
+	symbolName, err := NormalizeSymbolName(symbolName)
+	if err != nil {
+		return "", err
 	}
 
-	// Generate a summary of changes made
-	return fmt.Sprintf("Successfully renamed symbol to '%s'.\nUpdated %d occurrences across %d files:\n%s",
-		newName, changeCount, fileCount, locationsBuilder.String()), nil
+	symbol, err := resolveSymbol(ctx, client, symbolName, 0)
+	if errors.Is(err, errSymbolNotFound) {
+		return fmt.Sprintf("%s not found", symbolName), nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	loc := symbol.GetLocation()
+	err = openURI(ctx, client, loc.URI)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+	defer closeURI(ctx, client, loc.URI)
+
+	oldName, err := prepareRename(ctx, client, loc.URI, loc.Range.Start)
+	if err != nil {
+		return "", fmt.Errorf("failed to rename %s: %v", symbolName, err)
+	}
+
+	workspaceEdit, err := client.Rename(ctx, protocol.RenameParams{
+		TextDocument: protocol.TextDocumentIdentifier{
+			URI: loc.URI,
+		},
+		Position: loc.Range.Start,
+		NewName:  newName,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to rename symbol: %v", err)
+	}
+
+	// A null result means the server does not support renaming at this position
+	if workspaceEdit.Changes == nil && workspaceEdit.DocumentChanges == nil {
+		return fmt.Sprintf("The language server did not return any edits for renaming %s. The symbol may not be renameable at its location.", symbolName), nil
+	}
+
+	editsByFile := countEditsByFile(workspaceEdit)
+
+	if dryRun {
+		changeCount := 0
+		for _, count := range editsByFile {
+			changeCount += count
+		}
+		return renamePreview(workspaceEdit, oldName, newName, changeCount, len(editsByFile))
+	}
+
+	// Edits within each file are applied from bottom to top so offsets don't shift
+	if err := applyWorkspaceEdit(ctx, client, workspaceEdit); err != nil {
+		return "", fmt.Errorf("failed to apply changes: %v", err)
+	}
+
+	uris := make([]string, 0, len(editsByFile))
+	for uri := range editsByFile {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Successfully renamed %s to '%s' in %d files.\n", symbolName, newName, len(uris)))
+	for _, uri := range uris {
+		output.WriteString(fmt.Sprintf("---\n\n%s\nEdits in File: %d\n",
+			uriToPath(protocol.DocumentUri(uri)),
+			editsByFile[uri],
+		))
+	}
+	output.WriteString(formatResourceOperations(workspaceEdit))
+
+	return output.String(), nil
+}
+
+// renamePreview describes the edits a rename would make without applying them
+func renamePreview(workspaceEdit protocol.WorkspaceEdit, oldName, newName string, changeCount, fileCount int) (string, error) {
+	diff, err := previewWorkspaceEdit(workspaceEdit)
+	if err != nil {
+		return "", fmt.Errorf("failed to preview changes: %v", err)
+	}
+	return fmt.Sprintf("Dry run: renaming %sto '%s' would update %d occurrences across %d files. No files were changed.\n\n%s",
+		quotedName(oldName), newName, changeCount, fileCount, diff), nil
+}
+
+// prepareRename asks the server whether the symbol at a position can be renamed
+// and returns its current text, or an empty string if the server didn't say.
+// Servers that don't advertise prepareProvider aren't asked.
+func prepareRename(ctx context.Context, client *lsp.Client, uri protocol.DocumentUri, position protocol.Position) (string, error) {
+	if !supportsPrepareRename(client.Capabilities()) {
+		return "", nil
+	}
+
+	result, err := client.PrepareRename(ctx, protocol.PrepareRenameParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+			Position:     position,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("the language server rejected the rename: %v", err)
+	}
+	if result.Value == nil {
+		return "", errors.New("the language server reports that nothing at this position can be renamed")
+	}
+
+	var content []byte
+	if _, ok := result.Value.(protocol.Range); ok {
+		content, err = client.ReadDocument(ctx, uri)
+		if err != nil {
+			toolsLogger.Debug("Could not read %s for rename: %v", uri, err)
+		}
+	}
+	return prepareRenameText(result, content, client.PositionEncoding()), nil
+}
+
+// supportsPrepareRename reports whether the server's rename options include prepareProvider
+func supportsPrepareRename(capabilities protocol.ServerCapabilities) bool {
+	options, ok := capabilities.RenameProvider.(map[string]any)
+	if !ok {
+		return false
+	}
+	prepare, _ := options["prepareProvider"].(bool)
+	return prepare
+}
+
+// prepareRenameText returns the identifier a prepareRename result refers to.
+// Placeholders are used as is and ranges are read from content, with characters
+// counted in the given encoding. It returns "" when the server answers with
+// defaultBehavior, which names no identifier, and for ranges that span lines or lie
+// outside content.
+func prepareRenameText(result protocol.PrepareRenameResult, content []byte, encoding protocol.PositionEncodingKind) string {
+	switch v := result.Value.(type) {
+	case protocol.PrepareRenamePlaceholder:
+		return v.Placeholder
+	case protocol.Range:
+		if v.Start.Line != v.End.Line {
+			return ""
+		}
+		lines := splitLines(content)
+		if int(v.Start.Line) >= len(lines) {
+			return ""
+		}
+		line := lines[v.Start.Line]
+		if v.Start.Character > v.End.Character || v.End.Character > lsp.CharacterCount(line, encoding) {
+			return ""
+		}
+		return line[lsp.CharacterOffset(line, v.Start.Character, encoding):lsp.CharacterOffset(line, v.End.Character, encoding)]
+	}
+	return ""
+}
+
+// quotedName formats a symbol name for rename output, followed by a space
+func quotedName(name string) string {
+	if name == "" {
+		return ""
+	}
+	return fmt.Sprintf("'%s' ", name)
+}
+
+// countEditsByFile returns the number of text edits a workspace edit makes to each file URI
+func countEditsByFile(workspaceEdit protocol.WorkspaceEdit) map[string]int {
+	editsByFile := make(map[string]int)
+	if len(workspaceEdit.DocumentChanges) == 0 {
+		for uri, edits := range workspaceEdit.Changes {
+			editsByFile[string(uri)] += len(edits)
+		}
+	}
+	for _, change := range workspaceEdit.DocumentChanges {
+		if change.TextDocumentEdit != nil {
+			editsByFile[string(change.TextDocumentEdit.TextDocument.URI)] += len(change.TextDocumentEdit.Edits)
+		}
+	}
+	return editsByFile
+}
+
+// formatResourceOperations lists the files a workspace edit created, renamed or
+// deleted, such as a Java class file renamed with its class, in the order they
+// were applied. It returns an empty string if there are none.
+func formatResourceOperations(workspaceEdit protocol.WorkspaceEdit) string {
+	var operations []string
+	for _, change := range workspaceEdit.DocumentChanges {
+		switch {
+		case change.CreateFile != nil:
+			operations = append(operations, fmt.Sprintf("Created %s", uriToPath(change.CreateFile.URI)))
+		case change.RenameFile != nil:
+			operations = append(operations, fmt.Sprintf("Renamed %s to %s", uriToPath(change.RenameFile.OldURI), uriToPath(change.RenameFile.NewURI)))
+		case change.DeleteFile != nil:
+			operations = append(operations, fmt.Sprintf("Deleted %s", uriToPath(change.DeleteFile.URI)))
+		}
+	}
+	if len(operations) == 0 {
+		return ""
+	}
+	return "File operations:\n  " + strings.Join(operations, "\n  ") + "\n"
+}
+
+// lastNameComponent returns the unqualified part of a symbol name such as
+// "Type.Method" or "Namespace::Function"
+func lastNameComponent(symbolName string) string {
+	if i := strings.LastIndex(symbolName, "::"); i >= 0 {
+		symbolName = symbolName[i+2:]
+	}
+	if i := strings.LastIndex(symbolName, "."); i >= 0 {
+		symbolName = symbolName[i+1:]
+	}
+	return symbolName
 }
@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupportsPrepareRename(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider interface{}
+		expected bool
+	}{
+		{name: "Not advertised", provider: nil, expected: false},
+		{name: "Boolean provider", provider: true, expected: false},
+		{name: "Options without prepare", provider: map[string]any{}, expected: false},
+		{name: "Options with prepare", provider: map[string]any{"prepareProvider": true}, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			capabilities := protocol.ServerCapabilities{RenameProvider: tt.provider}
+			assert.Equal(t, tt.expected, supportsPrepareRename(capabilities))
+		})
+	}
+}
+
+func TestPrepareRenameText(t *testing.T) {
+	content := []byte("package main\r\n\r\nconst SharedConstant = \"value\"\r\n/* héllo 🌍 */ const Greeting = \"hi\"\r\n")
+	identifier := protocol.Range{
+		Start: protocol.Position{Line: 2, Character: 6},
+		End:   protocol.Position{Line: 2, Character: 20},
+	}
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected string
+	}{
+		{name: "Range", value: identifier, expected: "SharedConstant"},
+		{name: "Placeholder", value: protocol.PrepareRenamePlaceholder{Range: identifier, Placeholder: "Shared"}, expected: "Shared"},
+		{name: "Default behavior", value: protocol.PrepareRenameDefaultBehavior{DefaultBehavior: true}, expected: ""},
+		{
+			name: "Range past end of line",
+			value: protocol.Range{
+				Start: protocol.Position{Line: 2, Character: 6},
+				End:   protocol.Position{Line: 2, Character: 80},
+			},
+			expected: "",
+		},
+		{
+			name: "Range after multibyte characters",
+			value: protocol.Range{
+				Start: protocol.Position{Line: 3, Character: 21},
+				End:   protocol.Position{Line: 3, Character: 29},
+			},
+			expected: "Greeting",
+		},
+		{
+			name: "Multi-line range",
+			value: protocol.Range{
+				Start: protocol.Position{Line: 0, Character: 0},
+				End:   protocol.Position{Line: 2, Character: 5},
+			},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := protocol.PrepareRenameResult{Value: tt.value}
+			assert.Equal(t, tt.expected, prepareRenameText(result, content, protocol.UTF16))
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// ReplaceDefinition replaces the whole definition of a symbol, as returned by
+// ReadDefinition, with newText and returns the new definition with line numbers.
+// If several symbols share the name, matchIndex (1-indexed) picks one. With dryRun
+// set, it returns a diff of the change instead of writing it.
+func ReplaceDefinition(ctx context.Context, client *lsp.Client, symbolName, newText string, matchIndex int, dryRun bool) (string, error) {
+	symbolName, err := NormalizeSymbolName(symbolName)
+	if err != nil {
+		return "", err
+	}
+
+	symbol, err := resolveSymbol(ctx, client, symbolName, matchIndex)
+	if errors.Is(err, errSymbolNotFound) {
+		return fmt.Sprintf("%s not found", symbolName), nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	loc := symbol.GetLocation()
+	filePath := loc.URI.Path()
+	if err := client.OpenFile(ctx, filePath); err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+	defer client.CloseFile(ctx, filePath)
+
+	_, fullLoc, err := GetFullDefinition(ctx, client, loc)
+	if err != nil {
+		return "", fmt.Errorf("failed to get definition: %v", err)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	edit, err := definitionReplacement(splitLines(content), fullLoc.Range, newText)
+	if err != nil {
+		return "", err
+	}
+
+	workspaceEdit := protocol.WorkspaceEdit{
+		Changes: map[protocol.DocumentUri][]protocol.TextEdit{loc.URI: {edit}},
+	}
+	startLine := int(fullLoc.Range.Start.Line) + 1
+	endLine := int(fullLoc.Range.End.Line) + 1
+
+	if dryRun {
+		diff, err := previewWorkspaceEdit(workspaceEdit)
+		if err != nil {
+			return "", fmt.Errorf("failed to preview changes: %v", err)
+		}
+		return fmt.Sprintf("Dry run: would replace the definition of %s at L%d-L%d of %s. No files were changed.\n\n%s",
+			symbolName, startLine, endLine, filePath, diff), nil
+	}
+
+	if err := applyWorkspaceEdit(ctx, client, workspaceEdit); err != nil {
+		return "", fmt.Errorf("failed to apply changes: %v", err)
+	}
+
+	return fmt.Sprintf("Replaced the definition of %s at L%d-L%d of %s:\n\n%s",
+		symbolName, startLine, endLine, filePath, addLineNumbers(edit.NewText, startLine)), nil
+}
+
+// definitionReplacement returns an edit replacing the whole lines of a definition
+// range with newText. A trailing newline in newText is dropped, since the line
+// ending after the definition is kept.
+func definitionReplacement(lines []string, definition protocol.Range, newText string) (protocol.TextEdit, error) {
+	endLine := int(definition.End.Line)
+	if endLine >= len(lines) {
+		return protocol.TextEdit{}, fmt.Errorf("definition ends at line %d, past the end of the file", endLine+1)
+	}
+
+	return protocol.TextEdit{
+		Range: protocol.Range{
+			Start: protocol.Position{Line: definition.Start.Line},
+			End:   protocol.Position{Line: definition.End.Line, Character: uint32(len(lines[endLine]))},
+		},
+		NewText: strings.TrimSuffix(newText, "\n"),
+	}, nil
+}
@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/utilities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefinitionReplacement(t *testing.T) {
+	content := "package main\n\nfunc add(a, b int) int {\n\treturn a + b\n}\n\nfunc main() {}\n"
+	definition := protocol.Range{
+		Start: protocol.Position{Line: 2},
+		End:   protocol.Position{Line: 4, Character: 1},
+	}
+
+	testCases := []struct {
+		name     string
+		content  string
+		newText  string
+		expected string
+	}{
+		{
+			name:     "Replace body",
+			content:  content,
+			newText:  "func add(a, b int) int {\n\treturn b + a\n}\n",
+			expected: "package main\n\nfunc add(a, b int) int {\n\treturn b + a\n}\n\nfunc main() {}\n",
+		},
+		{
+			name:     "Shorter replacement",
+			content:  content,
+			newText:  "func add(a, b int) int { return a + b }",
+			expected: "package main\n\nfunc add(a, b int) int { return a + b }\n\nfunc main() {}\n",
+		},
+		{
+			name:     "CRLF file",
+			content:  "package main\r\n\r\nfunc add(a, b int) int {\r\n\treturn a + b\r\n}\r\n",
+			newText:  "func add(a, b int) int {\n\treturn b + a\n}\n",
+			expected: "package main\r\n\r\nfunc add(a, b int) int {\r\n\treturn b + a\r\n}\r\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			edit, err := definitionReplacement(splitLines([]byte(tc.content)), definition, tc.newText)
+			require.NoError(t, err)
+
+			result, err := utilities.ApplyTextEditsToContent([]byte(tc.content), []protocol.TextEdit{edit})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(result))
+		})
+	}
+}
+
+func TestDefinitionReplacementPastEnd(t *testing.T) {
+	_, err := definitionReplacement([]string{"package main"}, protocol.Range{End: protocol.Position{Line: 3}}, "")
+	assert.Error(t, err)
+}
+
+func TestDescribeCandidates(t *testing.T) {
+	symbols := []protocol.WorkspaceSymbolResult{
+		&protocol.SymbolInformation{Name: "Get", Location: protocol.Location{URI: "file:///src/cache.go", Range: protocol.Range{Start: protocol.Position{Line: 9, Character: 5}}}},
+		&protocol.SymbolInformation{Name: "Get", Location: protocol.Location{URI: "file:///src/http.go", Range: protocol.Range{Start: protocol.Position{Line: 2}}}},
+	}
+	assert.Equal(t, "1. Get (/src/cache.go L10:C6)\n2. Get (/src/http.go L3:C1)", describeCandidates(symbols))
+}
@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// regexSymbolFetchLimit caps the workspace/symbol results SearchSymbolsRegex
+// filters, so that a broad query doesn't pull the server's whole index
+const regexSymbolFetchLimit = 2000
+
+// SearchSymbolsRegex lists the workspace symbols whose name or qualified name, such
+// as "Cache.Get", matches a regular expression. The server has no regex search, so
+// the longest literal in the pattern is sent as a workspace/symbol query, or an empty
+// query if it has none, and the results are filtered here. This is best effort:
+// matches the server's fuzzy query doesn't return, or that fall past the first
+// regexSymbolFetchLimit results, are missed.
+func SearchSymbolsRegex(ctx context.Context, client *lsp.Client, pattern string, maxResults int) (string, error) {
+	if maxResults <= 0 {
+		maxResults = DefaultMaxSymbolMatches
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid pattern: %v", err)
+	}
+
+	query := regexQuery(pattern)
+	toolsLogger.Debug("Searching symbols matching /%s/ with query %q", pattern, query)
+	result, err := client.LimitedSymbol(ctx, query, regexSymbolFetchLimit)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch symbols: %v", err)
+	}
+	symbols, err := result.Results()
+	if err != nil {
+		return "", fmt.Errorf("failed to parse results: %v", err)
+	}
+
+	matches := filterSymbolsByRegex(symbols, re)
+	if len(matches) > maxResults {
+		matches = append(resolveSymbolLocations(ctx, client, matches[:maxResults]), matches[maxResults:]...)
+	} else {
+		matches = resolveSymbolLocations(ctx, client, matches)
+	}
+
+	output := formatSymbolMatches(pattern, matches, maxResults)
+	if len(symbols) >= regexSymbolFetchLimit {
+		output += fmt.Sprintf("\nOnly the first %d symbols returned for the query %q were searched, so some matches may be missing. Include a longer literal in the pattern to narrow the query.\n", regexSymbolFetchLimit, query)
+	}
+	return output, nil
+}
+
+// filterSymbolsByRegex keeps the symbols whose name or qualified name matches re
+func filterSymbolsByRegex(symbols []protocol.WorkspaceSymbolResult, re *regexp.Regexp) []protocol.WorkspaceSymbolResult {
+	var matches []protocol.WorkspaceSymbolResult
+	for _, symbol := range symbols {
+		name := symbol.GetName()
+		_, container := symbolKindNameAndContainer(symbol)
+		if re.MatchString(name) || container != "" && re.MatchString(container+"."+name) {
+			matches = append(matches, symbol)
+		}
+	}
+	return matches
+}
+
+// regexQuery returns the longest literal every match of pattern must contain, such
+// as "_test" for "_test$", to use as the workspace/symbol query. It returns an empty
+// query, which asks for all symbols, when there is no such literal.
+func regexQuery(pattern string) string {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return ""
+	}
+	re = re.Simplify()
+
+	// Only the literals of a top level concatenation are in every match
+	parts := []*syntax.Regexp{re}
+	if re.Op == syntax.OpConcat {
+		parts = re.Sub
+	}
+
+	// Servers match queries against bare names, so a qualified literal such as
+	// "Cache.Get" is queried as "Get"
+	query := ""
+	for _, part := range parts {
+		if part.Op != syntax.OpLiteral || part.Flags&syntax.FoldCase != 0 {
+			continue
+		}
+		if literal := lastNameComponent(string(part.Rune)); len(literal) > len(query) {
+			query = literal
+		}
+	}
+	return query
+}
@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegexQuery(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		expected string
+	}{
+		{pattern: "_test$", expected: "_test"},
+		{pattern: "^Test[A-Z]\\w*Handler$", expected: "Handler"},
+		{pattern: "Cache\\.Get", expected: "Get"},
+		{pattern: "Cache::size", expected: "size"},
+		{pattern: "get|set", expected: ""},
+		{pattern: "(?i)handler", expected: ""},
+		{pattern: ".*", expected: ""},
+		{pattern: "[", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			assert.Equal(t, tc.expected, regexQuery(tc.pattern))
+		})
+	}
+}
+
+func TestFilterSymbolsByRegex(t *testing.T) {
+	symbols := []protocol.WorkspaceSymbolResult{
+		&protocol.SymbolInformation{Name: "parse_test", Kind: protocol.Function},
+		&protocol.SymbolInformation{Name: "parse", Kind: protocol.Function},
+		&protocol.SymbolInformation{Name: "Get", Kind: protocol.Method, ContainerName: "Cache"},
+		&protocol.WorkspaceSymbol{BaseSymbolInformation: protocol.BaseSymbolInformation{Name: "Get", Kind: protocol.Method, ContainerName: "Client"}},
+	}
+
+	names := func(pattern string) []string {
+		var names []string
+		for _, symbol := range filterSymbolsByRegex(symbols, regexp.MustCompile(pattern)) {
+			_, container := symbolKindNameAndContainer(symbol)
+			names = append(names, container+"/"+symbol.GetName())
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"/parse_test"}, names("_test$"))
+	assert.Equal(t, []string{"Cache/Get"}, names("^Cache\\.Get$"))
+	assert.Equal(t, []string{"Cache/Get", "Client/Get"}, names("^Get$"))
+	assert.Empty(t, names("^Server\\."))
+}
@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// DefaultMaxSymbolMatches caps how many matches SearchSymbols lists
+const DefaultMaxSymbolMatches = 100
+
+// SearchSymbols lists the workspace symbols matching query with their kind,
+// container and location. Unlike ReadDefinition it opens no files, so it is a cheap
+// way to pick the right symbol before reading its definition.
+func SearchSymbols(ctx context.Context, client *lsp.Client, query string, maxResults int) (string, error) {
+	if maxResults <= 0 {
+		maxResults = DefaultMaxSymbolMatches
+	}
+
+	symbols, err := findSymbols(ctx, client, query)
+	if err != nil {
+		return "", err
+	}
+
+	return formatSymbolMatches(query, symbols, maxResults), nil
+}
+
+// formatSymbolMatches renders one line per symbol, in the order the language server
+// returned them, up to maxResults
+func formatSymbolMatches(query string, symbols []protocol.WorkspaceSymbolResult, maxResults int) string {
+	if len(symbols) == 0 {
+		return fmt.Sprintf("No symbols found matching %q", query)
+	}
+
+	shown := symbols
+	if len(shown) > maxResults {
+		shown = shown[:maxResults]
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("%d symbols matching %q:\n\n", len(symbols), query))
+	for _, symbol := range shown {
+		kind, container := symbolKindNameAndContainer(symbol)
+		name := symbol.GetName()
+		if container != "" {
+			name = container + "." + name
+		}
+		if kind != "" {
+			name = fmt.Sprintf("%s (%s)", name, kind)
+		}
+
+		loc := symbol.GetLocation()
+		output.WriteString(fmt.Sprintf("%s - %s:L%d\n", name, loc.URI.Path(), loc.Range.Start.Line+1))
+	}
+
+	if len(symbols) > maxResults {
+		output.WriteString(fmt.Sprintf("\nShowing the first %d. Use a more specific query to narrow the results.\n", maxResults))
+	}
+	return output.String()
+}
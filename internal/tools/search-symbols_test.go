@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatSymbolMatches(t *testing.T) {
+	location := func(path string, line uint32) protocol.Location {
+		return protocol.Location{
+			URI:   protocol.DocumentUri("file://" + path),
+			Range: protocol.Range{Start: protocol.Position{Line: line}},
+		}
+	}
+	symbols := []protocol.WorkspaceSymbolResult{
+		&protocol.SymbolInformation{Name: "Get", Kind: protocol.Method, ContainerName: "Cache", Location: location("/src/cache.go", 11)},
+		&protocol.SymbolInformation{Name: "Get", Kind: protocol.Function, Location: location("/src/http.go", 4)},
+	}
+
+	testCases := []struct {
+		name       string
+		symbols    []protocol.WorkspaceSymbolResult
+		maxResults int
+		expected   string
+	}{
+		{
+			name:       "No matches",
+			maxResults: 10,
+			expected:   `No symbols found matching "Get"`,
+		},
+		{
+			name:       "All matches",
+			symbols:    symbols,
+			maxResults: 10,
+			expected: "2 symbols matching \"Get\":\n\n" +
+				"Cache.Get (Method) - /src/cache.go:L12\n" +
+				"Get (Function) - /src/http.go:L5\n",
+		},
+		{
+			name:       "Capped",
+			symbols:    symbols,
+			maxResults: 1,
+			expected: "2 symbols matching \"Get\":\n\n" +
+				"Cache.Get (Method) - /src/cache.go:L12\n" +
+				"\nShowing the first 1. Use a more specific query to narrow the results.\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, formatSymbolMatches("Get", tc.symbols, tc.maxResults))
+		})
+	}
+}
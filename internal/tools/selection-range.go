@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// maxSelectionRangeLines is the longest selection range shown with its text. Outer
+// ranges such as whole classes or files are only listed.
+const maxSelectionRangeLines = 30
+
+// SelectionRange lists the nested ranges around a position (1-indexed line and
+// column) that smart selection would expand through, such as expression, statement,
+// block and function, innermost first with their text
+func SelectionRange(ctx context.Context, client *lsp.Client, filePath string, line, character int) (string, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+	defer client.CloseFile(ctx, filePath)
+
+	// Convert 1-indexed line/column to 0-indexed for LSP protocol
+	ranges, err := client.SelectionRange(ctx, protocol.SelectionRangeParams{
+		TextDocument: protocol.TextDocumentIdentifier{
+			URI: protocol.DocumentUri("file://" + filePath),
+		},
+		Positions: []protocol.Position{{
+			Line:      uint32(line - 1),
+			Character: uint32(character - 1),
+		}},
+	})
+	if err != nil {
+		if isMethodNotSupported(err) {
+			return "The language server does not support selection ranges.", nil
+		}
+		return "", fmt.Errorf("failed to get selection ranges: %v", err)
+	}
+
+	if len(ranges) == 0 {
+		return fmt.Sprintf("No selection ranges found at %s L%d:C%d", filePath, line, character), nil
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+
+	return formatSelectionRanges(&ranges[0], splitLines(content), filePath, line, character), nil
+}
+
+func formatSelectionRanges(selection *protocol.SelectionRange, lines []string, filePath string, line, character int) string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Selection ranges at %s L%d:C%d, innermost first:\n", filePath, line, character))
+
+	var previous *protocol.Range
+	index := 0
+	for ; selection != nil; selection = selection.Parent {
+		r := selection.Range
+		// Some servers repeat a range at several levels
+		if previous != nil && *previous == r {
+			continue
+		}
+		previous = &r
+		index++
+
+		lineCount := int(r.End.Line-r.Start.Line) + 1
+		output.WriteString(fmt.Sprintf("\n%d. L%d:C%d - L%d:C%d", index,
+			r.Start.Line+1, r.Start.Character+1, r.End.Line+1, r.End.Character+1))
+		switch {
+		case int(r.Start.Line) >= len(lines):
+			output.WriteString("\n")
+		case lineCount == 1:
+			output.WriteString(fmt.Sprintf(": %s\n", singleLineText(lines[r.Start.Line], r)))
+		case lineCount > maxSelectionRangeLines:
+			output.WriteString(fmt.Sprintf(" (%d lines, text omitted)\n", lineCount))
+		default:
+			end := min(int(r.End.Line)+1, len(lines))
+			output.WriteString(fmt.Sprintf(" (%d lines)\n", lineCount))
+			output.WriteString(addLineNumbers(strings.Join(lines[r.Start.Line:end], "\n"), int(r.Start.Line)+1))
+		}
+	}
+	return output.String()
+}
+
+// singleLineText returns the text of a range within one line, or the whole line if
+// the range doesn't fit in it
+func singleLineText(line string, r protocol.Range) string {
+	start, end := int(r.Start.Character), int(r.End.Character)
+	if start > end || end > len(line) {
+		return line
+	}
+	return line[start:end]
+}
@@ -0,0 +1,50 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatSelectionRanges(t *testing.T) {
+	lines := splitLines([]byte("func main() {\n\tx := add(1, 2)\n\tprintln(x)\n}\n"))
+	rng := func(startLine, startChar, endLine, endChar uint32) protocol.Range {
+		return protocol.Range{
+			Start: protocol.Position{Line: startLine, Character: startChar},
+			End:   protocol.Position{Line: endLine, Character: endChar},
+		}
+	}
+
+	function := &protocol.SelectionRange{Range: rng(0, 0, 3, 1)}
+	statement := &protocol.SelectionRange{Range: rng(1, 1, 1, 15), Parent: function}
+	call := &protocol.SelectionRange{Range: rng(1, 6, 1, 15), Parent: statement}
+	// Repeated ranges are shown once
+	identifier := &protocol.SelectionRange{Range: rng(1, 6, 1, 9), Parent: &protocol.SelectionRange{Range: rng(1, 6, 1, 9), Parent: call}}
+
+	output := formatSelectionRanges(identifier, lines, "main.go", 2, 7)
+
+	assert.Equal(t, `Selection ranges at main.go L2:C7, innermost first:
+
+1. L2:C7 - L2:C10: add
+
+2. L2:C7 - L2:C16: add(1, 2)
+
+3. L2:C2 - L2:C16: x := add(1, 2)
+
+4. L1:C1 - L4:C2 (4 lines)
+1|func main() {
+2|	x := add(1, 2)
+3|	println(x)
+4|}
+`, output)
+}
+
+func TestFormatSelectionRangesOmitsLongText(t *testing.T) {
+	lines := strings.Split(strings.Repeat("line\n", 100), "\n")
+	selection := &protocol.SelectionRange{Range: protocol.Range{End: protocol.Position{Line: 99, Character: 4}}}
+
+	output := formatSelectionRanges(selection, lines, "big.go", 1, 1)
+	assert.Contains(t, output, "1. L1:C1 - L100:C5 (100 lines, text omitted)\n")
+}
@@ -0,0 +1,154 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// semanticToken is one decoded token. Line and Char are 0-indexed.
+type semanticToken struct {
+	Line      int
+	Char      int
+	Length    int
+	Type      string
+	Modifiers []string
+}
+
+// lexicalTokenTypes are token types that don't describe identifiers. They are left
+// out of the annotations since syntax already makes them obvious.
+var lexicalTokenTypes = map[string]bool{
+	"keyword":  true,
+	"comment":  true,
+	"string":   true,
+	"number":   true,
+	"regexp":   true,
+	"operator": true,
+}
+
+// GetSemanticTokens prints a file with each identifier annotated with its semantic
+// token type, such as type, function or variable, as classified by the language server
+func GetSemanticTokens(ctx context.Context, client *lsp.Client, filePath string) (string, error) {
+	legend, ok := client.SemanticTokensLegend()
+	if !ok {
+		return "The language server does not support semantic tokens.", nil
+	}
+
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+	defer client.CloseFile(ctx, filePath)
+
+	result, err := client.SemanticTokensFull(ctx, protocol.SemanticTokensParams{
+		TextDocument: protocol.TextDocumentIdentifier{
+			URI: protocol.DocumentUri("file://" + filePath),
+		},
+	})
+	if err != nil {
+		if isMethodNotSupported(err) {
+			return "The language server does not support semantic tokens.", nil
+		}
+		return "", fmt.Errorf("failed to get semantic tokens: %v", err)
+	}
+
+	tokens, err := decodeSemanticTokens(result.Data, legend)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode semantic tokens: %v", err)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	lines := splitLines(content)
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("%s with %d semantic tokens:\n\n", filePath, len(tokens)))
+	output.WriteString(annotateSemanticTokens(lines, tokens))
+	return output.String(), nil
+}
+
+// decodeSemanticTokens unpacks the relative encoding of semantic tokens. Each token
+// is five integers: the line delta, the start character (relative to the previous
+// token if on the same line), the length, an index into the legend's token types
+// and a bit set of the legend's token modifiers.
+func decodeSemanticTokens(data []uint32, legend protocol.SemanticTokensLegend) ([]semanticToken, error) {
+	if len(data)%5 != 0 {
+		return nil, fmt.Errorf("token data length %d is not a multiple of 5", len(data))
+	}
+
+	tokens := make([]semanticToken, 0, len(data)/5)
+	line, char := 0, 0
+	for i := 0; i < len(data); i += 5 {
+		deltaLine, deltaStart := int(data[i]), int(data[i+1])
+		if deltaLine == 0 {
+			char += deltaStart
+		} else {
+			line += deltaLine
+			char = deltaStart
+		}
+
+		typeIndex := int(data[i+3])
+		tokenType := fmt.Sprintf("unknown(%d)", typeIndex)
+		if typeIndex < len(legend.TokenTypes) {
+			tokenType = legend.TokenTypes[typeIndex]
+		}
+
+		var modifiers []string
+		for bit, modifier := range legend.TokenModifiers {
+			if data[i+4]&(1<<uint(bit)) != 0 {
+				modifiers = append(modifiers, modifier)
+			}
+		}
+
+		tokens = append(tokens, semanticToken{
+			Line:      line,
+			Char:      char,
+			Length:    int(data[i+2]),
+			Type:      tokenType,
+			Modifiers: modifiers,
+		})
+	}
+	return tokens, nil
+}
+
+// annotateSemanticTokens renders numbered lines, each followed by the identifiers on
+// it and their token types, e.g. "main: function (definition)". tokens must be in
+// document order.
+func annotateSemanticTokens(lines []string, tokens []semanticToken) string {
+	padding := len(strconv.Itoa(len(lines)))
+
+	var output strings.Builder
+	next := 0
+	for i, line := range lines {
+		num := strconv.Itoa(i + 1)
+		output.WriteString(fmt.Sprintf("%s%s|%s\n", strings.Repeat(" ", padding-len(num)), num, line))
+
+		var annotations []string
+		for ; next < len(tokens) && tokens[next].Line <= i; next++ {
+			token := tokens[next]
+			if token.Line < i || lexicalTokenTypes[token.Type] {
+				continue
+			}
+
+			start := min(token.Char, len(line))
+			end := min(token.Char+token.Length, len(line))
+			annotation := fmt.Sprintf("%s: %s", line[start:end], token.Type)
+			if len(token.Modifiers) > 0 {
+				annotation += fmt.Sprintf(" (%s)", strings.Join(token.Modifiers, ", "))
+			}
+			annotations = append(annotations, annotation)
+		}
+
+		if len(annotations) > 0 {
+			output.WriteString(fmt.Sprintf("%s|  %s\n", strings.Repeat(" ", padding), strings.Join(annotations, "; ")))
+		}
+	}
+	return output.String()
+}
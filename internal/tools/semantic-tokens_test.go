@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+// goplsLegend is a subset of the legend gopls reports
+var goplsLegend = protocol.SemanticTokensLegend{
+	TokenTypes:     []string{"namespace", "type", "class", "function", "variable", "keyword"},
+	TokenModifiers: []string{"declaration", "definition", "readonly", "defaultLibrary"},
+}
+
+func TestDecodeSemanticTokens(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     []uint32
+		expected []semanticToken
+	}{
+		{
+			name:     "Empty",
+			data:     []uint32{},
+			expected: []semanticToken{},
+		},
+		{
+			name: "Same line deltas are relative to the previous start",
+			data: []uint32{
+				// func main()
+				0, 0, 4, 5, 0,
+				0, 5, 4, 3, 0b10,
+			},
+			expected: []semanticToken{
+				{Line: 0, Char: 0, Length: 4, Type: "keyword"},
+				{Line: 0, Char: 5, Length: 4, Type: "function", Modifiers: []string{"definition"}},
+			},
+		},
+		{
+			name: "New line resets the start character",
+			data: []uint32{
+				2, 8, 3, 4, 0,
+				0, 4, 2, 4, 0,
+				3, 1, 3, 0, 0,
+				0, 4, 7, 3, 0,
+			},
+			expected: []semanticToken{
+				{Line: 2, Char: 8, Length: 3, Type: "variable"},
+				{Line: 2, Char: 12, Length: 2, Type: "variable"},
+				{Line: 5, Char: 1, Length: 3, Type: "namespace"},
+				{Line: 5, Char: 5, Length: 7, Type: "function"},
+			},
+		},
+		{
+			name: "Multiple modifiers in legend order",
+			data: []uint32{1, 0, 6, 1, 0b1101},
+			expected: []semanticToken{
+				{Line: 1, Char: 0, Length: 6, Type: "type", Modifiers: []string{"declaration", "readonly", "defaultLibrary"}},
+			},
+		},
+		{
+			name: "Unknown type index and modifier bits",
+			data: []uint32{0, 0, 1, 42, 0b10000},
+			expected: []semanticToken{
+				{Line: 0, Char: 0, Length: 1, Type: "unknown(42)"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens, err := decodeSemanticTokens(tc.data, goplsLegend)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, tokens)
+		})
+	}
+}
+
+func TestDecodeSemanticTokensInvalidLength(t *testing.T) {
+	_, err := decodeSemanticTokens([]uint32{0, 0, 4, 5}, goplsLegend)
+	assert.Error(t, err)
+}
+
+func TestAnnotateSemanticTokens(t *testing.T) {
+	lines := []string{
+		"func main() {",
+		"\tfmt.Println(x)",
+		"}",
+	}
+	tokens := []semanticToken{
+		{Line: 0, Char: 0, Length: 4, Type: "keyword"},
+		{Line: 0, Char: 5, Length: 4, Type: "function", Modifiers: []string{"definition"}},
+		{Line: 1, Char: 1, Length: 3, Type: "namespace"},
+		{Line: 1, Char: 5, Length: 7, Type: "function"},
+		{Line: 1, Char: 13, Length: 1, Type: "variable", Modifiers: []string{"readonly"}},
+	}
+
+	expected := "1|func main() {\n" +
+		" |  main: function (definition)\n" +
+		"2|\tfmt.Println(x)\n" +
+		" |  fmt: namespace; Println: function; x: variable (readonly)\n" +
+		"3|}\n"
+	assert.Equal(t, expected, annotateSemanticTokens(lines, tokens))
+}
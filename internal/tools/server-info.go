@@ -0,0 +1,167 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// toolCapabilities maps each tool to the server capability it relies on, in the
+// order they are listed. Capabilities nested in options objects are named by their
+// path, and "clangd" stands for clangd's extensions, which it doesn't advertise.
+var toolCapabilities = []struct {
+	Tool       string
+	Capability string
+}{
+	{"definition", "workspaceSymbolProvider"},
+	{"definitions", "workspaceSymbolProvider"},
+	{"explore_symbol", "workspaceSymbolProvider"},
+	{"replace_definition", "workspaceSymbolProvider"},
+	{"insert_near_symbol", "workspaceSymbolProvider"},
+	{"search_symbols", "workspaceSymbolProvider"},
+	{"search_symbols_regex", "workspaceSymbolProvider"},
+	{"index_status", "workspaceSymbolProvider"},
+	{"definition_at_position", "definitionProvider"},
+	{"type_definition", "typeDefinitionProvider"},
+	{"moniker", "monikerProvider"},
+	{"declaration", "declarationProvider"},
+	{"references", "referencesProvider"},
+	{"references_at_position", "referencesProvider"},
+	{"implementations", "implementationProvider"},
+	{"call_hierarchy", "callHierarchyProvider"},
+	{"type_hierarchy", "typeHierarchyProvider"},
+	{"document_highlight", "documentHighlightProvider"},
+	{"selection_range", "selectionRangeProvider"},
+	{"document_symbols", "documentSymbolProvider"},
+	{"enclosing_symbol", "documentSymbolProvider"},
+	{"hover", "hoverProvider"},
+	{"hover_symbol", "hoverProvider"},
+	{"macro_expansion", "hoverProvider"},
+	{"signature_help", "signatureHelpProvider"},
+	{"completion", "completionProvider"},
+	{"rename_symbol", "renameProvider"},
+	{"rename_symbol_by_name", "renameProvider"},
+	{"rename_file", "workspace.fileOperations.willRename"},
+	{"code_actions", "codeActionProvider"},
+	{"apply_code_action", "codeActionProvider"},
+	{"organize_imports", "codeActionProvider"},
+	{"format_document", "documentFormattingProvider"},
+	{"inlay_hints", "inlayHintProvider"},
+	{"folding_ranges", "foldingRangeProvider"},
+	{"document_links", "documentLinkProvider"},
+	{"semantic_tokens", "semanticTokensProvider"},
+	{"get_codelens", "codeLensProvider"},
+	{"execute_codelens", "codeLensProvider"},
+	{"workspace_diagnostics", "diagnosticProvider"},
+	{"switch_source_header", "clangd"},
+}
+
+// capabilityFreeTools are the tools that work with any server
+var capabilityFreeTools = []string{
+	"diagnostics",
+	"edit_file",
+	"read_file_range",
+	"workspace_files",
+	"workspace_folders",
+	"server_info",
+	"debug_info",
+	"raw_request",
+}
+
+// ServerInfo describes the language server behind client: its name, version and
+// which of the registered tools its reported capabilities support
+func ServerInfo(ctx context.Context, client *lsp.Client, registered map[string]bool) (string, error) {
+	info, _ := client.ServerInfo()
+	command := client.Name()
+	if client.Cmd != nil {
+		command = client.Cmd.Path
+	}
+
+	supported, err := supportedCapabilities(client.Capabilities())
+	if err != nil {
+		return "", fmt.Errorf("failed to read server capabilities: %v", err)
+	}
+	if capabilities := client.Capabilities(); capabilities.Workspace != nil && capabilities.Workspace.FileOperations != nil && capabilities.Workspace.FileOperations.WillRename != nil {
+		supported["workspace.fileOperations.willRename"] = true
+	}
+	if strings.Contains(strings.ToLower(info.Name+" "+command), "clangd") {
+		supported["clangd"] = true
+	}
+
+	return formatServerInfo(info, command, supported, registered), nil
+}
+
+// supportedCapabilities returns the capabilities a server enables. Providers are
+// either booleans or option objects, so any value other than false or null counts.
+func supportedCapabilities(capabilities protocol.ServerCapabilities) (map[string]bool, error) {
+	data, err := json.Marshal(capabilities)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	supported := make(map[string]bool, len(fields))
+	for name, value := range fields {
+		if value == nil || value == false {
+			continue
+		}
+		supported[name] = true
+	}
+	return supported, nil
+}
+
+func formatServerInfo(info protocol.ServerInfo, command string, supported, registered map[string]bool) string {
+	var output strings.Builder
+
+	name := info.Name
+	if name == "" {
+		name = "unknown"
+	}
+	if info.Version != "" {
+		name += " " + info.Version
+	}
+	output.WriteString(fmt.Sprintf("Server: %s\n", name))
+	if command != "" {
+		output.WriteString(fmt.Sprintf("Command: %s\n", command))
+	}
+
+	var available, unavailable []string
+	for _, tc := range toolCapabilities {
+		// Some tools, like get_codelens, are only registered when enabled in tools.go
+		if !registered[tc.Tool] {
+			continue
+		}
+		entry := fmt.Sprintf("%s (%s)", tc.Tool, tc.Capability)
+		if supported[tc.Capability] {
+			available = append(available, entry)
+		} else {
+			unavailable = append(unavailable, entry)
+		}
+	}
+
+	output.WriteString("\nSupported tools:\n")
+	for _, entry := range available {
+		output.WriteString("  " + entry + "\n")
+	}
+	if len(unavailable) > 0 {
+		output.WriteString("\nUnsupported tools:\n")
+		for _, entry := range unavailable {
+			output.WriteString("  " + entry + "\n")
+		}
+	}
+	var free []string
+	for _, tool := range capabilityFreeTools {
+		if registered[tool] {
+			free = append(free, tool)
+		}
+	}
+	output.WriteString("\nTools that work with any server:\n  " + strings.Join(free, ", ") + "\n")
+	return output.String()
+}
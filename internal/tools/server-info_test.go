@@ -0,0 +1,87 @@
+package tools
+
+import (
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupportedCapabilities(t *testing.T) {
+	capabilities := protocol.ServerCapabilities{
+		ReferencesProvider:      &protocol.Or_ServerCapabilities_referencesProvider{Value: true},
+		HoverProvider:           &protocol.Or_ServerCapabilities_hoverProvider{Value: false},
+		RenameProvider:          map[string]any{"prepareProvider": true},
+		WorkspaceSymbolProvider: &protocol.Or_ServerCapabilities_workspaceSymbolProvider{Value: protocol.WorkspaceSymbolOptions{}},
+	}
+
+	supported, err := supportedCapabilities(capabilities)
+	require.NoError(t, err)
+	assert.True(t, supported["referencesProvider"])
+	assert.True(t, supported["renameProvider"])
+	assert.True(t, supported["workspaceSymbolProvider"])
+	assert.False(t, supported["hoverProvider"])
+	assert.False(t, supported["callHierarchyProvider"])
+}
+
+func TestFormatServerInfo(t *testing.T) {
+	supported := map[string]bool{}
+	registered := map[string]bool{}
+	for _, tc := range toolCapabilities {
+		if tc.Capability != "typeHierarchyProvider" && tc.Capability != "inlayHintProvider" {
+			supported[tc.Capability] = true
+		}
+		registered[tc.Tool] = tc.Capability != "codeLensProvider"
+	}
+	for _, tool := range capabilityFreeTools {
+		registered[tool] = tool != "raw_request"
+	}
+
+	output := formatServerInfo(protocol.ServerInfo{Name: "clangd", Version: "17.0.6"}, "/usr/bin/clangd", supported, registered)
+
+	assert.Contains(t, output, "Server: clangd 17.0.6\nCommand: /usr/bin/clangd\n")
+	assert.Contains(t, output, "  references (referencesProvider)\n")
+	assert.Contains(t, output, "\nUnsupported tools:\n  type_hierarchy (typeHierarchyProvider)\n  inlay_hints (inlayHintProvider)\n")
+	assert.Contains(t, output, "\nTools that work with any server:\n  diagnostics, edit_file, ")
+	// Tools that aren't registered are left out
+	assert.NotContains(t, output, "get_codelens")
+	assert.NotContains(t, output, "raw_request")
+}
+
+func TestFormatServerInfoUnknownServer(t *testing.T) {
+	output := formatServerInfo(protocol.ServerInfo{}, "", map[string]bool{}, map[string]bool{})
+	assert.Contains(t, output, "Server: unknown\n\nSupported tools:\n")
+}
+
+func TestToolCapabilitiesCoverRegisteredTools(t *testing.T) {
+	source, err := os.ReadFile("../../tools.go")
+	require.NoError(t, err)
+
+	// Tools that are commented out, like get_codelens, count as registered so they
+	// are covered once enabled
+	registered := map[string]bool{}
+	for _, match := range regexp.MustCompile(`mcp\.NewTool\("([a-z_]+)"`).FindAllSubmatch(source, -1) {
+		registered[string(match[1])] = true
+	}
+	require.NotEmpty(t, registered)
+
+	listed := map[string]bool{}
+	for _, tc := range toolCapabilities {
+		assert.False(t, listed[tc.Tool], "%s is listed more than once", tc.Tool)
+		listed[tc.Tool] = true
+	}
+	for _, tool := range capabilityFreeTools {
+		assert.False(t, listed[tool], "%s is listed more than once", tool)
+		listed[tool] = true
+	}
+
+	for tool := range registered {
+		assert.True(t, listed[tool], "%s is registered but missing from server_info", tool)
+	}
+	for tool := range listed {
+		assert.True(t, registered[tool], "%s is listed in server_info but not registered", tool)
+	}
+}
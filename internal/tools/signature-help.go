@@ -0,0 +1,121 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// SignatureHelp shows the signatures of the function being called at a position,
+// with the parameter under the cursor highlighted
+func SignatureHelp(ctx context.Context, client *lsp.Client, filePath string, line, character int) (string, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+	defer client.CloseFile(ctx, filePath)
+
+	// Convert 1-indexed line/column to 0-indexed for LSP protocol
+	help, err := client.SignatureHelp(ctx, protocol.SignatureHelpParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{
+				URI: protocol.DocumentUri("file://" + filePath),
+			},
+			Position: protocol.Position{
+				Line:      uint32(line - 1),
+				Character: uint32(character - 1),
+			},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get signature help: %v", err)
+	}
+
+	if len(help.Signatures) == 0 {
+		return fmt.Sprintf("No signature help available at %s L%d:C%d. The position may not be inside a call.", filePath, line, character), nil
+	}
+
+	return formatSignatureHelp(help), nil
+}
+
+// formatSignatureHelp renders each signature with the active one marked and its
+// active parameter wrapped in ** **. Missing active indexes default to zero.
+func formatSignatureHelp(help protocol.SignatureHelp) string {
+	activeSignature := int(help.ActiveSignature)
+	if activeSignature >= len(help.Signatures) {
+		activeSignature = 0
+	}
+
+	var output strings.Builder
+	for i, sig := range help.Signatures {
+		output.WriteString("---\n\n")
+
+		label := sig.Label
+		if i == activeSignature {
+			output.WriteString("Active Signature\n")
+
+			// A parameter index on the signature takes precedence over the top level one
+			activeParameter := int(help.ActiveParameter)
+			if sig.ActiveParameter != 0 {
+				activeParameter = int(sig.ActiveParameter)
+			}
+			if activeParameter < len(sig.Parameters) {
+				param := sig.Parameters[activeParameter]
+				label = highlightParameter(label, param.Label)
+				if param.Documentation != nil {
+					if doc := documentationText(param.Documentation.Value); doc != "" {
+						output.WriteString(fmt.Sprintf("Parameter: %s\n", doc))
+					}
+				}
+			}
+		}
+
+		output.WriteString(fmt.Sprintf("Signature: %s\n", label))
+		if sig.Documentation != nil {
+			if doc := documentationText(sig.Documentation.Value); doc != "" {
+				output.WriteString(fmt.Sprintf("\n%s\n", doc))
+			}
+		}
+		output.WriteString("\n")
+	}
+
+	return output.String()
+}
+
+// highlightParameter wraps the parameter within a signature label in ** **. The
+// parameter label is either a substring of the signature or a pair of offsets.
+func highlightParameter(signature string, label protocol.Or_ParameterInformation_label) string {
+	var start, end int
+	switch v := label.Value.(type) {
+	case string:
+		start = strings.Index(signature, v)
+		if start < 0 {
+			return signature
+		}
+		end = start + len(v)
+	case protocol.Tuple_ParameterInformation_label_Item1:
+		start, end = int(v.Fld0), int(v.Fld1)
+	default:
+		return signature
+	}
+
+	if start < 0 || end > len(signature) || start >= end {
+		return signature
+	}
+	return signature[:start] + "**" + signature[start:end] + "**" + signature[end:]
+}
+
+// documentationText returns the text of a documentation field, which servers send
+// either as a plain string or as MarkupContent
+func documentationText(value any) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case protocol.MarkupContent:
+		return strings.TrimSpace(v.Value)
+	}
+	return ""
+}
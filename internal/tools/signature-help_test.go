@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHighlightParameter(t *testing.T) {
+	signature := "func Add(a int, b int) int"
+
+	testCases := []struct {
+		name     string
+		label    any
+		expected string
+	}{
+		{name: "String label", label: "b int", expected: "func Add(a int, **b int**) int"},
+		{name: "Offset label", label: protocol.Tuple_ParameterInformation_label_Item1{Fld0: 9, Fld1: 14}, expected: "func Add(**a int**, b int) int"},
+		{name: "Label not in signature", label: "c int", expected: signature},
+		{name: "Offsets out of range", label: protocol.Tuple_ParameterInformation_label_Item1{Fld0: 20, Fld1: 40}, expected: signature},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			label := protocol.Or_ParameterInformation_label{Value: tc.label}
+			assert.Equal(t, tc.expected, highlightParameter(signature, label))
+		})
+	}
+}
+
+func TestFormatSignatureHelp_DefaultsToFirst(t *testing.T) {
+	help := protocol.SignatureHelp{
+		Signatures: []protocol.SignatureInformation{
+			{
+				Label:         "greet(name string)",
+				Documentation: &protocol.Or_SignatureInformation_documentation{Value: "Greets someone."},
+				Parameters: []protocol.ParameterInformation{
+					{Label: protocol.Or_ParameterInformation_label{Value: "name string"}},
+				},
+			},
+			{Label: "greet(name string, times int)"},
+		},
+	}
+
+	expected := "---\n\n" +
+		"Active Signature\n" +
+		"Signature: greet(**name string**)\n" +
+		"\nGreets someone.\n\n" +
+		"---\n\n" +
+		"Signature: greet(name string, times int)\n\n"
+	assert.Equal(t, expected, formatSignatureHelp(help))
+}
@@ -0,0 +1,45 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// SwitchSourceHeader returns the path of the header of a C or C++ source file, or of
+// the source of a header, using clangd's textDocument/switchSourceHeader extension.
+// With includeContent set the counterpart's content follows with line numbers.
+func SwitchSourceHeader(ctx context.Context, client *lsp.Client, filePath string, includeContent bool) (string, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+	defer client.CloseFile(ctx, filePath)
+
+	uri, err := client.SwitchSourceHeader(ctx, protocol.TextDocumentIdentifier{
+		URI: protocol.DocumentUri("file://" + filePath),
+	})
+	if err != nil {
+		if isMethodNotSupported(err) {
+			return "The language server does not support switching between source and header files. This is a clangd extension.", nil
+		}
+		return "", fmt.Errorf("failed to switch source/header: %v", err)
+	}
+	if uri == "" {
+		return fmt.Sprintf("No corresponding source or header file found for %s", filePath), nil
+	}
+
+	counterpart := uriToPath(uri)
+	if !includeContent {
+		return counterpart, nil
+	}
+
+	content, err := os.ReadFile(counterpart)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %v", counterpart, err)
+	}
+	return fmt.Sprintf("%s\n\n%s", counterpart, addLineNumbers(string(content), 1)), nil
+}
@@ -0,0 +1,264 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// Match modes for filtering workspace/symbol results
+const (
+	// MatchModeFuzzy keeps every symbol the language server returns
+	MatchModeFuzzy = "fuzzy"
+	// MatchModeExact keeps symbols whose name or qualified name equals the query
+	MatchModeExact = "exact"
+	// MatchModePrefix keeps symbols whose name or qualified name starts with the query
+	MatchModePrefix = "prefix"
+)
+
+// DefaultMaxDefinitions caps how many definitions are returned for one query
+const DefaultMaxDefinitions = 50
+
+// DefinitionOptions controls which symbols ReadDefinition returns. The zero value
+// uses fuzzy matching and DefaultMaxDefinitions, and returns whole definitions.
+type DefinitionOptions struct {
+	MatchMode  string
+	MaxResults int
+	// Scope is one of ScopeFull, ScopeSignature or ScopeBody. Empty means ScopeFull.
+	Scope string
+	// MaxLines truncates longer definitions, keeping the signature and closing
+	// line. 0 uses LSP_MAX_DEFINITION_LINES, and no limit if it is not set.
+	MaxLines int
+	// InFile keeps only the definitions whose file path contains it, to pick one of
+	// several symbols sharing a name, such as "net/http" or "Widget.cpp". Empty keeps
+	// them all.
+	InFile string
+}
+
+// filterSymbolsByFile keeps the symbols whose location path, or URI outside the
+// file system, contains inFile
+func filterSymbolsByFile(symbols []protocol.WorkspaceSymbolResult, inFile string) []protocol.WorkspaceSymbolResult {
+	if inFile == "" {
+		return symbols
+	}
+
+	var filtered []protocol.WorkspaceSymbolResult
+	for _, symbol := range symbols {
+		uri := symbol.GetLocation().URI
+		path := string(uri)
+		if isFileURI(uri) {
+			path = uriToPath(uri)
+		}
+		if strings.Contains(path, inFile) {
+			filtered = append(filtered, symbol)
+		}
+	}
+	return filtered
+}
+
+// filterSymbols keeps the symbols matching symbolName according to matchMode
+func filterSymbols(symbols []protocol.WorkspaceSymbolResult, symbolName string, matchMode string) ([]protocol.WorkspaceSymbolResult, error) {
+	switch matchMode {
+	case "", MatchModeFuzzy:
+		return symbols, nil
+	case MatchModeExact, MatchModePrefix:
+	default:
+		return nil, fmt.Errorf("invalid match mode %q, expected one of %s, %s or %s", matchMode, MatchModeFuzzy, MatchModeExact, MatchModePrefix)
+	}
+
+	var filtered []protocol.WorkspaceSymbolResult
+	for _, symbol := range symbols {
+		_, container := symbolKindNameAndContainer(symbol)
+		names := []string{symbol.GetName()}
+		if container != "" {
+			names = append(names, container+"::"+symbol.GetName(), container+"."+symbol.GetName())
+		}
+
+		for _, name := range names {
+			if name == symbolName || (matchMode == MatchModePrefix && strings.HasPrefix(name, symbolName)) {
+				filtered = append(filtered, symbol)
+				break
+			}
+		}
+	}
+	return filtered, nil
+}
+
+// sortSymbolsByLocation returns a copy of symbols sorted by file and start position
+func sortSymbolsByLocation(symbols []protocol.WorkspaceSymbolResult) []protocol.WorkspaceSymbolResult {
+	sorted := append([]protocol.WorkspaceSymbolResult(nil), symbols...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].GetLocation(), sorted[j].GetLocation()
+		return definitionBefore(string(a.URI), newResultRange(a.Range).Start, string(b.URI), newResultRange(b.Range).Start)
+	})
+	return sorted
+}
+
+// NormalizeSymbolName cleans up a symbol name given by a client before it is
+// looked up. It trims whitespace, strips a trailing argument list such as
+// "foo(int)" and drops redundant "::" separators, so "  ::ns::::Foo::bar() "
+// becomes "ns::Foo::bar". It fails when nothing is left of the name.
+func NormalizeSymbolName(symbolName string) (string, error) {
+	name := stripArgumentList(strings.TrimSpace(symbolName))
+	for strings.Contains(name, "::::") {
+		name = strings.ReplaceAll(name, "::::", "::")
+	}
+	for strings.HasPrefix(name, "::") {
+		name = strings.TrimPrefix(name, "::")
+	}
+	for strings.HasSuffix(name, "::") {
+		name = strings.TrimSuffix(name, "::")
+	}
+	name = strings.TrimSpace(name)
+
+	if name == "" {
+		return "", fmt.Errorf("invalid symbol name %q, expected a name such as 'MyFunction' or 'MyClass::method'", symbolName)
+	}
+	if name != symbolName {
+		toolsLogger.Debug("Normalized symbol name %q to %q", symbolName, name)
+	}
+	return name, nil
+}
+
+// stripArgumentList removes one balanced argument list from the end of name, so
+// "foo(std::function<void(int)>)" becomes "foo". The parentheses of a call operator
+// are part of its name: "Foo::operator()" is kept and "operator()(int)" becomes
+// "operator()".
+func stripArgumentList(name string) string {
+	if !strings.HasSuffix(name, ")") {
+		return name
+	}
+	depth := 0
+	for i := len(name) - 1; i >= 0; i-- {
+		switch name[i] {
+		case ')':
+			depth++
+		case '(':
+			depth--
+		}
+		if depth > 0 {
+			continue
+		}
+		prefix := strings.TrimSpace(name[:i])
+		if name[i:] == "()" && isOperatorKeyword(prefix) {
+			return name
+		}
+		return prefix
+	}
+	// The parentheses are unbalanced, so this is not an argument list
+	return name
+}
+
+// isOperatorKeyword reports whether name ends with the keyword operator, as in
+// "Foo::operator", rather than an identifier such as "my_operator"
+func isOperatorKeyword(name string) bool {
+	rest, ok := strings.CutSuffix(name, "operator")
+	if !ok {
+		return false
+	}
+	if rest == "" {
+		return true
+	}
+	last := rest[len(rest)-1]
+	return !(last == '_' || last >= '0' && last <= '9' || last >= 'a' && last <= 'z' || last >= 'A' && last <= 'Z')
+}
+
+// splitQualifiedName splits a name such as "Type.Method" or "ns::Class::method" into
+// its qualifier and last component. The qualifier is empty for unqualified names.
+func splitQualifiedName(symbolName string) (qualifier, name string) {
+	name = lastNameComponent(symbolName)
+	qualifier = strings.TrimSuffix(strings.TrimSuffix(symbolName[:len(symbolName)-len(name)], "::"), ".")
+	return qualifier, name
+}
+
+// filterByQualifier keeps the symbols qualified by qualifier, either through their
+// container name or their own name, as gopls reports methods as "Type.Method".
+// Containers match when they equal the qualifier or end with it, so "Class" matches
+// a container "ns::Class" and "pkg" matches a Go package path "example.com/pkg".
+func filterByQualifier(symbols []protocol.WorkspaceSymbolResult, qualifier string) []protocol.WorkspaceSymbolResult {
+	normalize := func(name string) string {
+		return strings.ReplaceAll(name, "::", ".")
+	}
+	qualifier = normalize(qualifier)
+
+	var filtered []protocol.WorkspaceSymbolResult
+	for _, symbol := range symbols {
+		_, container := symbolKindNameAndContainer(symbol)
+		container = normalize(container)
+		nameQualifier, _ := splitQualifiedName(normalize(symbol.GetName()))
+		for _, candidate := range []string{container, nameQualifier} {
+			if candidate == qualifier ||
+				strings.HasSuffix(candidate, "."+qualifier) ||
+				strings.HasSuffix(candidate, "/"+qualifier) {
+				filtered = append(filtered, symbol)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// errSymbolNotFound is returned by resolveSymbol when no symbol has the name
+var errSymbolNotFound = errors.New("symbol not found")
+
+// resolveSymbol returns the one symbol named symbolName, for tools that edit code.
+// workspace/symbol may return fuzzy matches and edits are destructive, so only
+// symbols whose name matches exactly are considered. When several match,
+// matchIndex (1-indexed) picks one, and 0 fails with a numbered list of candidates.
+func resolveSymbol(ctx context.Context, client *lsp.Client, symbolName string, matchIndex int) (protocol.WorkspaceSymbolResult, error) {
+	results, err := findSymbols(ctx, client, symbolName)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := exactMatches(results, symbolName)
+	switch {
+	case len(matches) == 0:
+		return nil, errSymbolNotFound
+	case matchIndex > len(matches):
+		return nil, fmt.Errorf("matchIndex %d is out of range, %d symbols match %s:\n%s", matchIndex, len(matches), symbolName, describeCandidates(matches))
+	case matchIndex > 0:
+		return matches[matchIndex-1], nil
+	case len(matches) > 1:
+		return nil, fmt.Errorf("symbol name %s is ambiguous, %d symbols match:\n%s", symbolName, len(matches), describeCandidates(matches))
+	}
+	return matches[0], nil
+}
+
+// exactMatches keeps the symbols named exactly symbolName, sorted by location so a
+// matchIndex picks the same symbol however the server ordered them. Symbols named by
+// the last component of a qualified name, such as bar for "A::bar", must also be in
+// the qualifying container.
+func exactMatches(symbols []protocol.WorkspaceSymbolResult, symbolName string) []protocol.WorkspaceSymbolResult {
+	qualifier, name := splitQualifiedName(symbolName)
+	var matches []protocol.WorkspaceSymbolResult
+	for _, symbol := range symbols {
+		switch {
+		case symbol.GetName() == symbolName:
+			matches = append(matches, symbol)
+		case symbol.GetName() == name && (qualifier == "" || len(filterByQualifier([]protocol.WorkspaceSymbolResult{symbol}, qualifier)) > 0):
+			matches = append(matches, symbol)
+		}
+	}
+	return sortSymbolsByLocation(matches)
+}
+
+// describeCandidates lists symbols with their 1-indexed position and location
+func describeCandidates(symbols []protocol.WorkspaceSymbolResult) string {
+	var candidates []string
+	for i, symbol := range symbols {
+		loc := symbol.GetLocation()
+		candidates = append(candidates, fmt.Sprintf("%d. %s (%s L%d:C%d)",
+			i+1,
+			symbol.GetName(),
+			uriToPath(loc.URI),
+			loc.Range.Start.Line+1,
+			loc.Range.Start.Character+1))
+	}
+	return strings.Join(candidates, "\n")
+}
@@ -0,0 +1,215 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterSymbols(t *testing.T) {
+	symbols := []protocol.WorkspaceSymbolResult{
+		&protocol.SymbolInformation{Name: "get", ContainerName: "Cache"},
+		&protocol.SymbolInformation{Name: "getValue", ContainerName: "Cache"},
+		&protocol.SymbolInformation{Name: "get", ContainerName: "Store"},
+		&protocol.SymbolInformation{Name: "budget"},
+	}
+
+	testCases := []struct {
+		name      string
+		query     string
+		matchMode string
+		expected  []string
+	}{
+		{name: "Fuzzy keeps everything", query: "get", matchMode: MatchModeFuzzy, expected: []string{"Cache::get", "Cache::getValue", "Store::get", "budget"}},
+		{name: "Default is fuzzy", query: "get", matchMode: "", expected: []string{"Cache::get", "Cache::getValue", "Store::get", "budget"}},
+		{name: "Exact name", query: "get", matchMode: MatchModeExact, expected: []string{"Cache::get", "Store::get"}},
+		{name: "Exact qualified name", query: "Store::get", matchMode: MatchModeExact, expected: []string{"Store::get"}},
+		{name: "Exact dotted name", query: "Cache.get", matchMode: MatchModeExact, expected: []string{"Cache::get"}},
+		{name: "Prefix", query: "get", matchMode: MatchModePrefix, expected: []string{"Cache::get", "Cache::getValue", "Store::get"}},
+		{name: "Qualified prefix", query: "Cache::get", matchMode: MatchModePrefix, expected: []string{"Cache::get", "Cache::getValue"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filtered, err := filterSymbols(symbols, tc.query, tc.matchMode)
+			assert.NoError(t, err)
+
+			var names []string
+			for _, symbol := range filtered {
+				name := symbol.GetName()
+				if container := symbol.(*protocol.SymbolInformation).ContainerName; container != "" {
+					name = container + "::" + name
+				}
+				names = append(names, name)
+			}
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+
+	_, err := filterSymbols(symbols, "get", "regex")
+	assert.Error(t, err)
+}
+
+func TestSortSymbolsByLocation(t *testing.T) {
+	symbol := func(uri string, line, character uint32) protocol.WorkspaceSymbolResult {
+		return &protocol.SymbolInformation{Name: "Handler", Location: protocol.Location{
+			URI:   protocol.DocumentUri(uri),
+			Range: protocol.Range{Start: protocol.Position{Line: line, Character: character}},
+		}}
+	}
+	symbols := []protocol.WorkspaceSymbolResult{
+		symbol("file:///work/b.go", 3, 0),
+		symbol("file:///work/a.go", 10, 0),
+		symbol("file:///work/b.go", 1, 4),
+		symbol("file:///work/a.go", 10, 2),
+	}
+
+	sorted := sortSymbolsByLocation(symbols)
+	assert.Equal(t, []protocol.WorkspaceSymbolResult{symbols[1], symbols[3], symbols[2], symbols[0]}, sorted)
+	// The server's results, which may be cached, are left in their order
+	assert.Equal(t, "file:///work/b.go", string(symbols[0].GetLocation().URI))
+}
+
+func TestExactMatches(t *testing.T) {
+	symbol := func(name, container, uri string, line uint32) protocol.WorkspaceSymbolResult {
+		return &protocol.SymbolInformation{
+			Name:          name,
+			ContainerName: container,
+			Location: protocol.Location{
+				URI:   protocol.DocumentUri(uri),
+				Range: protocol.Range{Start: protocol.Position{Line: line}},
+			},
+		}
+	}
+	// In the unsorted order a server may return them
+	symbols := []protocol.WorkspaceSymbolResult{
+		symbol("bar", "B", "file:///src/b.cpp", 4),
+		symbol("bar", "A", "file:///src/a.cpp", 9),
+		symbol("barrier", "A", "file:///src/a.cpp", 2),
+		symbol("bar", "A", "file:///src/a.cpp", 1),
+	}
+
+	testCases := []struct {
+		name     string
+		query    string
+		expected []protocol.WorkspaceSymbolResult
+	}{
+		{name: "Sorted by location", query: "bar", expected: []protocol.WorkspaceSymbolResult{symbols[3], symbols[1], symbols[0]}},
+		{name: "Qualified name checks the container", query: "A::bar", expected: []protocol.WorkspaceSymbolResult{symbols[3], symbols[1]}},
+		{name: "Other container", query: "C::bar", expected: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, exactMatches(symbols, tc.query))
+		})
+	}
+}
+
+func TestFilterSymbolsByFile(t *testing.T) {
+	symbol := func(uri string) protocol.WorkspaceSymbolResult {
+		return &protocol.SymbolInformation{Name: "Handler", Location: protocol.Location{URI: protocol.DocumentUri(uri)}}
+	}
+	symbols := []protocol.WorkspaceSymbolResult{
+		symbol("file:///go/src/net/http/server.go"),
+		symbol("file:///work/app/handler.go"),
+		symbol("jdt://contents/rt.jar/net/Handler.class"),
+	}
+
+	assert.Equal(t, symbols, filterSymbolsByFile(symbols, ""))
+	assert.Equal(t, symbols[:1], filterSymbolsByFile(symbols, "net/http"))
+	assert.Equal(t, symbols[1:2], filterSymbolsByFile(symbols, "app/"))
+	assert.Equal(t, symbols[2:], filterSymbolsByFile(symbols, "rt.jar"))
+	assert.Empty(t, filterSymbolsByFile(symbols, "Widget.cpp"))
+}
+
+func TestSplitQualifiedName(t *testing.T) {
+	testCases := []struct {
+		symbolName        string
+		expectedQualifier string
+		expectedName      string
+	}{
+		{symbolName: "bar", expectedQualifier: "", expectedName: "bar"},
+		{symbolName: "Foo::bar", expectedQualifier: "Foo", expectedName: "bar"},
+		{symbolName: "ns::Foo::bar", expectedQualifier: "ns::Foo", expectedName: "bar"},
+		{symbolName: "Type.Method", expectedQualifier: "Type", expectedName: "Method"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.symbolName, func(t *testing.T) {
+			qualifier, name := splitQualifiedName(tc.symbolName)
+			assert.Equal(t, tc.expectedQualifier, qualifier)
+			assert.Equal(t, tc.expectedName, name)
+		})
+	}
+}
+
+func TestFilterByQualifier(t *testing.T) {
+	symbols := []protocol.WorkspaceSymbolResult{
+		&protocol.SymbolInformation{Name: "bar", ContainerName: "Foo"},
+		&protocol.SymbolInformation{Name: "bar", ContainerName: "ns::Foo"},
+		&protocol.SymbolInformation{Name: "bar", ContainerName: "Baz"},
+		&protocol.SymbolInformation{Name: "bar", ContainerName: "example.com/mod/foo"},
+		&protocol.SymbolInformation{Name: "Foo.bar", ContainerName: "example.com/mod/pkg"},
+		&protocol.WorkspaceSymbol{BaseSymbolInformation: protocol.BaseSymbolInformation{Name: "bar", ContainerName: "MyFoo"}},
+	}
+
+	testCases := []struct {
+		name      string
+		qualifier string
+		expected  []int
+	}{
+		{name: "Container", qualifier: "Foo", expected: []int{0, 1, 4}},
+		{name: "Nested container", qualifier: "ns::Foo", expected: []int{1}},
+		{name: "Dotted qualifier", qualifier: "ns.Foo", expected: []int{1}},
+		{name: "Package path", qualifier: "foo", expected: []int{3}},
+		{name: "No match", qualifier: "Other", expected: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var expected []protocol.WorkspaceSymbolResult
+			for _, i := range tc.expected {
+				expected = append(expected, symbols[i])
+			}
+			assert.Equal(t, expected, filterByQualifier(symbols, tc.qualifier))
+		})
+	}
+}
+
+func TestNormalizeSymbolName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{name: "Unchanged", input: "Foo.Bar", expected: "Foo.Bar"},
+		{name: "Whitespace", input: "  Foo\n", expected: "Foo"},
+		{name: "Empty argument list", input: "foo()", expected: "foo"},
+		{name: "Argument list", input: "ns::foo(int, const char *)", expected: "ns::foo"},
+		{name: "Nested parentheses", input: "foo(std::function<void(int)>)", expected: "foo"},
+		{name: "Call operator", input: "Foo::operator()", expected: "Foo::operator()"},
+		{name: "Call operator with arguments", input: "operator()(int)", expected: "operator()"},
+		{name: "Identifier ending in operator", input: "my_operator()", expected: "my_operator"},
+		{name: "Unbalanced parentheses", input: "foo)", expected: "foo)"},
+		{name: "Leading separator", input: "::ns::Foo", expected: "ns::Foo"},
+		{name: "Trailing separator", input: "ns::Foo::", expected: "ns::Foo"},
+		{name: "Repeated separators", input: "ns::::Foo::::::::bar", expected: "ns::Foo::bar"},
+		{name: "Empty", input: "   ", wantErr: true},
+		{name: "Only separators and arguments", input: ":: ()", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			name, err := NormalizeSymbolName(tc.input)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, name)
+		})
+	}
+}
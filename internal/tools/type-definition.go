@@ -0,0 +1,52 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// ReadTypeDefinition returns the full source of the definition of the type of the
+// expression at a position (1-indexed line and column), such as the struct or class
+// of a variable.
+func ReadTypeDefinition(ctx context.Context, client *lsp.Client, filePath string, line, character int) (string, error) {
+	err := client.OpenFile(ctx, filePath)
+	if err != nil {
+		return "", fmt.Errorf("could not open file: %v", err)
+	}
+	defer client.CloseFile(ctx, filePath)
+
+	// Convert 1-indexed line/column to 0-indexed for LSP protocol
+	result, err := client.TypeDefinition(ctx, protocol.TypeDefinitionParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{
+				URI: protocol.DocumentUri("file://" + filePath),
+			},
+			Position: protocol.Position{
+				Line:      uint32(line - 1),
+				Character: uint32(character - 1),
+			},
+		},
+	})
+	if err != nil {
+		if isMethodNotSupported(err) {
+			return "The language server does not support finding type definitions.", nil
+		}
+		return "", fmt.Errorf("failed to get type definition: %v", err)
+	}
+
+	locations, err := result.Locations()
+	if err != nil {
+		return "", fmt.Errorf("failed to process type definition result: %v", err)
+	}
+
+	definitions := formatDefinitionLocations(ctx, client, locations)
+	if len(definitions) == 0 {
+		return fmt.Sprintf("No type definition found at %s L%d:C%d", filePath, line, character), nil
+	}
+
+	return strings.Join(definitions, ""), nil
+}
@@ -0,0 +1,121 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// TypeHierarchy shows the base types ("supertypes") or derived types ("subtypes") of
+// a type as a tree, following the hierarchy up to depth levels
+func TypeHierarchy(ctx context.Context, client *lsp.Client, symbolName string, direction string, depth int) (string, error) {
+	symbolName, err := NormalizeSymbolName(symbolName)
+	if err != nil {
+		return "", err
+	}
+
+	if direction != "supertypes" && direction != "subtypes" {
+		return "", fmt.Errorf("direction must be \"supertypes\" or \"subtypes\", got %q", direction)
+	}
+	if depth < 1 {
+		depth = 1
+	}
+	if depth > maxHierarchyDepth {
+		depth = maxHierarchyDepth
+	}
+
+	results, err := findSymbols(ctx, client, symbolName)
+	if err != nil {
+		return "", err
+	}
+
+	var trees []string
+	for _, symbol := range results {
+		loc := symbol.GetLocation()
+
+		err := openURI(ctx, client, loc.URI)
+		if err != nil {
+			toolsLogger.Error("Error opening file: %v", err)
+			continue
+		}
+		defer closeURI(ctx, client, loc.URI)
+
+		items, err := client.PrepareTypeHierarchy(ctx, protocol.TypeHierarchyPrepareParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{
+					URI: loc.URI,
+				},
+				Position: loc.Range.Start,
+			},
+		})
+		if err != nil {
+			if isMethodNotSupported(err) {
+				return "The language server does not support type hierarchy.", nil
+			}
+			return "", fmt.Errorf("failed to prepare type hierarchy: %v", err)
+		}
+
+		for _, item := range items {
+			var output strings.Builder
+			output.WriteString(fmt.Sprintf("---\n\n%s of %s\n\n", strings.ToUpper(direction[:1])+direction[1:], formatTypeHierarchyItem(item)))
+			visited := map[string]bool{typeHierarchyItemKey(item): true}
+			writeTypeHierarchy(ctx, client, &output, item, direction, 1, depth, visited)
+			trees = append(trees, output.String())
+		}
+	}
+
+	if len(trees) == 0 {
+		return fmt.Sprintf("No type hierarchy found for symbol: %s", symbolName), nil
+	}
+
+	return strings.Join(trees, "\n"), nil
+}
+
+// writeTypeHierarchy writes the related types of item at the given level and recurses
+// until maxDepth. Types reachable along several paths are only expanded once.
+func writeTypeHierarchy(ctx context.Context, client *lsp.Client, output *strings.Builder, item protocol.TypeHierarchyItem, direction string, level, maxDepth int, visited map[string]bool) {
+	var related []protocol.TypeHierarchyItem
+	var err error
+	if direction == "supertypes" {
+		related, err = client.Supertypes(ctx, protocol.TypeHierarchySupertypesParams{Item: item})
+	} else {
+		related, err = client.Subtypes(ctx, protocol.TypeHierarchySubtypesParams{Item: item})
+	}
+	if err != nil {
+		toolsLogger.Error("Error getting %s: %v", direction, err)
+		return
+	}
+
+	indent := strings.Repeat("  ", level)
+	if len(related) == 0 && level == 1 {
+		output.WriteString(indent + "(none)\n")
+		return
+	}
+
+	for _, r := range related {
+		line := indent + formatTypeHierarchyItem(r)
+
+		key := typeHierarchyItemKey(r)
+		if visited[key] {
+			output.WriteString(line + " (already shown)\n")
+			continue
+		}
+		output.WriteString(line + "\n")
+
+		if level < maxDepth {
+			visited[key] = true
+			writeTypeHierarchy(ctx, client, output, r, direction, level+1, maxDepth, visited)
+		}
+	}
+}
+
+func formatTypeHierarchyItem(item protocol.TypeHierarchyItem) string {
+	return formatHierarchyItem(item.Name, item.Kind, item.URI, item.SelectionRange)
+}
+
+func typeHierarchyItemKey(item protocol.TypeHierarchyItem) string {
+	return hierarchyItemKey(item.URI, item.SelectionRange)
+}
@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+)
+
+// caseInsensitivePaths is set on platforms whose default file systems ignore case,
+// so paths differing only in case name the same file
+var caseInsensitivePaths = runtime.GOOS == "darwin" || runtime.GOOS == "windows"
+
+// uriToPath converts a file URI to a path. Percent escapes such as %20 are decoded,
+// and both the file:///path and the nonstandard file://path forms are accepted.
+// URIs that can't be parsed fall back to stripping the scheme.
+func uriToPath(uri protocol.DocumentUri) string {
+	parsed, err := protocol.ParseDocumentUri(string(uri))
+	if err != nil {
+		return strings.TrimPrefix(string(uri), "file://")
+	}
+	return parsed.Path()
+}
+
+// canonicalPath resolves symlinks, so a file reached through a linked directory
+// has the same path as when it is reached directly. Paths that don't exist are
+// only cleaned.
+func canonicalPath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
+
+// normalizeLocationURIs rewrites the URIs of locations so that each file has a
+// single URI, the first one the language server used for it. Locations are grouped
+// by file through their URIs, so without this a file reached through a symlink or
+// spelled with different case or escaping is listed several times.
+func normalizeLocationURIs(locations []protocol.Location) []protocol.Location {
+	byURI := make(map[protocol.DocumentUri]protocol.DocumentUri)
+	byPath := make(map[string]protocol.DocumentUri)
+
+	normalized := make([]protocol.Location, len(locations))
+	for i, loc := range locations {
+		uri, ok := byURI[loc.URI]
+		if !ok {
+			path := canonicalPath(uriToPath(loc.URI))
+			key := path
+			if caseInsensitivePaths {
+				key = strings.ToLower(path)
+			}
+			if uri, ok = byPath[key]; !ok {
+				uri = loc.URI
+				byPath[key] = uri
+			}
+			byURI[loc.URI] = uri
+		}
+
+		loc.URI = uri
+		normalized[i] = loc
+	}
+	return normalized
+}
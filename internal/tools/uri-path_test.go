@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURIToPath(t *testing.T) {
+	testCases := []struct {
+		name     string
+		uri      protocol.DocumentUri
+		expected string
+	}{
+		{name: "Plain path", uri: "file:///src/main.go", expected: "/src/main.go"},
+		{name: "Two slashes", uri: "file://src/main.go", expected: "/src/main.go"},
+		{name: "Encoded space", uri: "file:///my%20project/main.go", expected: "/my project/main.go"},
+		{name: "Unencoded space", uri: "file:///my project/main.go", expected: "/my project/main.go"},
+		{name: "Encoded non-ASCII", uri: "file:///src/caf%C3%A9/%E6%97%A5%E6%9C%AC.go", expected: "/src/café/日本.go"},
+		{name: "Raw non-ASCII", uri: "file:///src/café/日本.go", expected: "/src/café/日本.go"},
+		{name: "Encoded reserved characters", uri: "file:///src/a%2Bb%40c.go", expected: "/src/a+b@c.go"},
+		{name: "Not a file URI", uri: "untitled:Untitled-1", expected: "untitled:Untitled-1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, uriToPath(tc.uri))
+		})
+	}
+}
+
+func TestNormalizeLocationURIs(t *testing.T) {
+	dir := t.TempDir()
+	realDir := filepath.Join(dir, "real dir")
+	require.NoError(t, os.Mkdir(realDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(realDir, "main.go"), []byte("package main\n"), 0644))
+	require.NoError(t, os.Symlink(realDir, filepath.Join(dir, "link")))
+
+	realURI := protocol.URIFromPath(filepath.Join(realDir, "main.go"))
+	location := func(uri protocol.DocumentUri, line uint32) protocol.Location {
+		return protocol.Location{URI: uri, Range: protocol.Range{Start: protocol.Position{Line: line}}}
+	}
+
+	locations := []protocol.Location{
+		location(realURI, 1),
+		// Same file through a symlinked directory
+		location(protocol.URIFromPath(filepath.Join(dir, "link", "main.go")), 2),
+		// Same file with the space left unescaped
+		location(protocol.DocumentUri("file://"+filepath.Join(realDir, "main.go")), 3),
+		location("file:///elsewhere/other.go", 4),
+	}
+
+	normalized := normalizeLocationURIs(locations)
+	require.Len(t, normalized, 4)
+	for i := range 3 {
+		assert.Equal(t, realURI, normalized[i].URI)
+		assert.Equal(t, locations[i].Range, normalized[i].Range)
+	}
+	assert.Equal(t, protocol.DocumentUri("file:///elsewhere/other.go"), normalized[3].URI)
+	assert.Len(t, sortedURIs(normalized), 2)
+}
+
+func TestNormalizeLocationURIsCaseInsensitive(t *testing.T) {
+	original := caseInsensitivePaths
+	caseInsensitivePaths = true
+	defer func() { caseInsensitivePaths = original }()
+
+	normalized := normalizeLocationURIs([]protocol.Location{
+		{URI: "file:///Users/me/Project/main.go"},
+		{URI: "file:///users/me/project/main.go"},
+	})
+	assert.Equal(t, protocol.DocumentUri("file:///Users/me/Project/main.go"), normalized[1].URI)
+}
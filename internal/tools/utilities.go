@@ -7,18 +7,19 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 )
 
 func ExtractTextFromLocation(loc protocol.Location) (string, error) {
-	path := strings.TrimPrefix(string(loc.URI), "file://")
+	path := uriToPath(loc.URI)
 
 	content, err := os.ReadFile(path)
 	if err != nil {
 		return "", fmt.Errorf("failed to read file: %w", err)
 	}
 
-	lines := strings.Split(string(content), "\n")
+	lines := splitLines(content)
 
 	startLine := int(loc.Range.Start.Line)
 	endLine := int(loc.Range.End.Line)
@@ -68,6 +69,17 @@ func ExtractTextFromLocation(loc protocol.Location) (string, error) {
 	return result.String(), nil
 }
 
+// splitLines splits file content into lines, removing the "\r" of "\r\n" line
+// endings so files with Windows or mixed line endings display cleanly. Line i is
+// still the line a language server calls line i.
+func splitLines(content []byte) []string {
+	lines := strings.Split(string(content), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
 func containsPosition(r protocol.Range, p protocol.Position) bool {
 	if r.Start.Line > p.Line || r.End.Line < p.Line {
 		return false
@@ -141,6 +153,72 @@ func ConvertLinesToRanges(linesToShow map[int]bool, totalLines int) []LineRange
 
 // FormatLinesWithRanges formats file content using line ranges
 func FormatLinesWithRanges(lines []string, ranges []LineRange) string {
+	return formatLineRanges(lines, ranges, nil, nil, true)
+}
+
+// FormatLinesWithMatches formats file content using line ranges like
+// FormatLinesWithRanges, with a line of carets under the characters each match
+// spans, to show which token on a busy line a location points at. Characters are
+// counted in the server's position encoding.
+func FormatLinesWithMatches(lines []string, ranges []LineRange, matches []protocol.Range, encoding protocol.PositionEncodingKind) string {
+	return formatLineRanges(lines, ranges, nil, matchUnderlines(lines, matches, encoding), true)
+}
+
+// matchUnderlines returns the caret line shown under each line (0-indexed) holding
+// a match, such as "    ^^^", with one caret per character. Match characters are
+// counted in the given encoding. Tabs before a match are kept so that the carets
+// line up, and a match spanning lines is underlined to the end of its first line.
+func matchUnderlines(lines []string, matches []protocol.Range, encoding protocol.PositionEncodingKind) map[int]string {
+	marks := make(map[int][]bool)
+	for _, match := range matches {
+		lineIndex := int(match.Start.Line)
+		if lineIndex >= len(lines) {
+			continue
+		}
+		line := lines[lineIndex]
+		start := lsp.CharacterOffset(line, match.Start.Character, encoding)
+		end := len(line)
+		if match.End.Line == match.Start.Line {
+			end = lsp.CharacterOffset(line, match.End.Character, encoding)
+		}
+		// Empty matches, such as insertion points, still get one caret
+		end = max(end, start+1)
+
+		if marks[lineIndex] == nil {
+			marks[lineIndex] = make([]bool, len(line)+1)
+		}
+		for i := start; i < end && i < len(marks[lineIndex]); i++ {
+			marks[lineIndex][i] = true
+		}
+	}
+
+	underlines := make(map[int]string, len(marks))
+	for lineIndex, marked := range marks {
+		line := lines[lineIndex]
+		var underline strings.Builder
+		for i, r := range line {
+			switch {
+			case marked[i]:
+				underline.WriteByte('^')
+			case r == '\t':
+				underline.WriteByte('\t')
+			default:
+				underline.WriteByte(' ')
+			}
+		}
+		if marked[len(line)] {
+			underline.WriteByte('^')
+		}
+		underlines[lineIndex] = strings.TrimRight(underline.String(), " \t")
+	}
+	return underlines
+}
+
+// formatLineRanges formats file content using line ranges, with line numbers if
+// showLineNumbers is set. If marked is not nil, its lines (0-indexed) are prefixed
+// with "> " and the other lines with "  " to keep them aligned. Lines in underlines
+// are followed by their underline, see matchUnderlines.
+func formatLineRanges(lines []string, ranges []LineRange, marked map[int]bool, underlines map[int]string, showLineNumbers bool) string {
 	if len(ranges) == 0 {
 		return ""
 	}
@@ -151,16 +229,37 @@ func FormatLinesWithRanges(lines []string, ranges []LineRange) string {
 	for _, r := range ranges {
 		// Add skipped lines indicator
 		if lastEnd != -1 && r.Start > lastEnd+1 {
-			// skipped := r.Start - lastEnd - 1
 			result.WriteString("...\n")
 		}
 
 		// Extract lines for this range
 		rangeLines := lines[r.Start : r.End+1]
+		if showLineNumbers {
+			// Add line numbers using the existing function
+			numbered := addLineNumbers(strings.Join(rangeLines, "\n"), r.Start+1)
+			rangeLines = strings.Split(strings.TrimSuffix(numbered, "\n"), "\n")
+		}
+
+		for i, line := range rangeLines {
+			prefix := ""
+			if marked != nil {
+				if marked[r.Start+i] {
+					result.WriteString("> ")
+				} else {
+					result.WriteString("  ")
+				}
+				prefix = "  "
+			}
+			result.WriteString(line + "\n")
 
-		// Add line numbers using the existing function
-		numberedText := addLineNumbers(strings.Join(rangeLines, "\n"), r.Start+1)
-		result.WriteString(numberedText)
+			if underline, ok := underlines[r.Start+i]; ok {
+				if showLineNumbers {
+					// Keep the line number column, blank, so the carets line up
+					prefix += strings.Repeat(" ", strings.Index(line, "|")) + "|"
+				}
+				result.WriteString(prefix + underline + "\n")
+			}
+		}
 
 		lastEnd = r.End
 	}
@@ -3,11 +3,13 @@ package tools
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/isaacphi/mcp-language-server/internal/protocol"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Save original ReadFile function
@@ -353,6 +355,13 @@ func TestConvertLinesToRanges(t *testing.T) {
 			totalLines:  10,
 			expected:    nil, // The function returns nil for empty input
 		},
+		{
+			name: "Overlapping context windows merge",
+			// Windows of 2 lines around references on lines 4, 5 and 7
+			linesToShow: map[int]bool{2: true, 3: true, 4: true, 5: true, 6: true, 7: true, 8: true, 9: true},
+			totalLines:  20,
+			expected:    []LineRange{{Start: 2, End: 9}},
+		},
 		{
 			name:        "Single line",
 			linesToShow: map[int]bool{5: true},
@@ -454,3 +463,168 @@ func TestFormatLinesWithRanges(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatLineRanges(t *testing.T) {
+	lines := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"}
+	ranges := []LineRange{{Start: 1, End: 2}, {Start: 8, End: 10}}
+	marked := map[int]bool{2: true, 9: true}
+
+	testCases := []struct {
+		name            string
+		marked          map[int]bool
+		showLineNumbers bool
+		expected        string
+	}{
+		{
+			name:            "Line numbers",
+			showLineNumbers: true,
+			expected:        "2|b\n3|c\n...\n 9|i\n10|j\n11|k\n",
+		},
+		{
+			name:            "Highlighted with line numbers",
+			marked:          marked,
+			showLineNumbers: true,
+			expected:        "  2|b\n> 3|c\n...\n   9|i\n> 10|j\n  11|k\n",
+		},
+		{
+			name:     "Highlighted without line numbers",
+			marked:   marked,
+			expected: "  b\n> c\n...\n  i\n> j\n  k\n",
+		},
+		{
+			name:     "Plain",
+			expected: "b\nc\n...\ni\nj\nk\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, formatLineRanges(lines, ranges, tc.marked, nil, tc.showLineNumbers))
+		})
+	}
+}
+
+func TestMatchUnderlines(t *testing.T) {
+	lines := []string{"\tx := add(x, y)", "return x", "}"}
+	match := func(line, start, endLine, end uint32) protocol.Range {
+		return protocol.Range{
+			Start: protocol.Position{Line: line, Character: start},
+			End:   protocol.Position{Line: endLine, Character: end},
+		}
+	}
+
+	underlines := matchUnderlines(lines, []protocol.Range{
+		match(0, 1, 0, 2),
+		match(0, 10, 0, 11),
+		match(1, 7, 2, 1),
+		match(2, 1, 2, 1),
+		match(7, 0, 7, 1),
+	}, protocol.UTF16)
+	assert.Equal(t, map[int]string{
+		0: "\t^        ^",
+		1: "       ^",
+		2: " ^",
+	}, underlines)
+}
+
+func TestMatchUnderlinesMultibyte(t *testing.T) {
+	// é is two bytes and one UTF-16 unit, 🌍 four bytes and two UTF-16 units
+	lines := []string{`s := "héllo 🌍" + name`}
+	name := protocol.Range{
+		Start: protocol.Position{Line: 0, Character: 18},
+		End:   protocol.Position{Line: 0, Character: 22},
+	}
+	expected := map[int]string{0: "                 ^^^^"}
+	assert.Equal(t, expected, matchUnderlines(lines, []protocol.Range{name}, protocol.UTF16))
+
+	// The same match counted in UTF-32 and UTF-8
+	name.Start.Character, name.End.Character = 17, 21
+	assert.Equal(t, expected, matchUnderlines(lines, []protocol.Range{name}, protocol.UTF32))
+	name.Start.Character, name.End.Character = 21, 25
+	assert.Equal(t, expected, matchUnderlines(lines, []protocol.Range{name}, protocol.UTF8))
+}
+
+func TestFormatLinesWithMatches(t *testing.T) {
+	lines := []string{"a", "b", "call(x, x)", "d"}
+	ranges := []LineRange{{Start: 1, End: 3}}
+	matches := []protocol.Range{{
+		Start: protocol.Position{Line: 2, Character: 8},
+		End:   protocol.Position{Line: 2, Character: 9},
+	}}
+
+	assert.Equal(t, "2|b\n3|call(x, x)\n |        ^\n4|d\n", FormatLinesWithMatches(lines, ranges, matches, protocol.UTF16))
+
+	marked := map[int]bool{2: true}
+	assert.Equal(t, "  b\n> call(x, x)\n          ^\n  d\n", formatLineRanges(lines, ranges, marked, matchUnderlines(lines, matches, protocol.UTF16), false))
+}
+
+func TestLastNameComponent(t *testing.T) {
+	testCases := []struct {
+		name       string
+		symbolName string
+		expected   string
+	}{
+		{name: "Unqualified", symbolName: "FooBar", expected: "FooBar"},
+		{name: "Go method", symbolName: "TestStruct.Method", expected: "Method"},
+		{name: "C++ method", symbolName: "TestClass::method", expected: "method"},
+		{name: "Nested namespace", symbolName: "ns::inner::func", expected: "func"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, lastNameComponent(tc.symbolName))
+		})
+	}
+}
+
+func TestSplitLines(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{name: "LF", content: "a\nb\n", expected: []string{"a", "b", ""}},
+		{name: "CRLF", content: "a\r\nb\r\n", expected: []string{"a", "b", ""}},
+		{name: "Mixed", content: "a\r\nb\nc\r\n", expected: []string{"a", "b", "c", ""}},
+		{name: "No trailing newline", content: "a\r\nb", expected: []string{"a", "b"}},
+		{name: "Carriage return inside a line", content: "a\rb\r\n", expected: []string{"a\rb", ""}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, splitLines([]byte(tc.content)))
+		})
+	}
+}
+
+func TestCRLFPositionsLineUp(t *testing.T) {
+	// A CRLF fixture with a reference to count at L3:C10
+	path := filepath.Join(t.TempDir(), "crlf.go")
+	content := "package main\r\n\r\nfunc inc(count int) int {\r\n\treturn count + 1\r\n}\r\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	loc := protocol.Location{
+		URI: protocol.URIFromPath(path),
+		Range: protocol.Range{
+			Start: protocol.Position{Line: 2, Character: 9},
+			End:   protocol.Position{Line: 2, Character: 14},
+		},
+	}
+
+	text, err := ExtractTextFromLocation(loc)
+	require.NoError(t, err)
+	assert.Equal(t, "count", text)
+
+	fileContent, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := splitLines(fileContent)
+	ranges := ConvertLinesToRanges(map[int]bool{1: true, 2: true, 3: true}, len(lines))
+	formatted := FormatLinesWithRanges(lines, ranges)
+
+	assert.NotContains(t, formatted, "\r")
+	assert.Equal(t, "2|\n3|func inc(count int) int {\n4|\treturn count + 1\n", formatted)
+
+	// The displayed line 3, after its "3|" prefix, has the reference at column 10
+	line3 := strings.Split(formatted, "\n")[1]
+	assert.Equal(t, "count", strings.TrimPrefix(line3, "3|")[9:14])
+}
@@ -0,0 +1,285 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/workspace"
+)
+
+// DefaultMaxDiagnosticEntries is how many of the most severe diagnostics
+// GetWorkspaceDiagnostics lists
+const DefaultMaxDiagnosticEntries = 20
+
+// workspaceDiagnosticsMaxFiles caps how many files are opened to collect diagnostics
+// from servers without workspace/diagnostic
+const workspaceDiagnosticsMaxFiles = 100
+
+// GetWorkspaceDiagnostics summarizes the diagnostics of the whole workspace: counts
+// by severity for each file, followed by the maxEntries most severe diagnostics.
+// Servers that support workspace/diagnostic are asked for them directly. For the
+// others the source files of every workspace folder are opened, up to
+// workspaceDiagnosticsMaxFiles, and closed again once the diagnostics they publish
+// are collected.
+func GetWorkspaceDiagnostics(ctx context.Context, client *lsp.Client, maxEntries int) (string, error) {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxDiagnosticEntries
+	}
+	folders := client.WorkspaceFolders()
+	if len(folders) == 0 {
+		return "", fmt.Errorf("language server has no workspace")
+	}
+	root := folders[0]
+
+	var diagnostics map[protocol.DocumentUri][]protocol.Diagnostic
+	var source string
+	if supportsWorkspaceDiagnostics(client.Capabilities()) {
+		report, err := client.DiagnosticWorkspace(ctx, protocol.WorkspaceDiagnosticParams{
+			PreviousResultIds: []protocol.PreviousResultId{},
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to get workspace diagnostics: %v", err)
+		}
+		diagnostics = workspaceReportDiagnostics(report)
+		source = "workspace/diagnostic"
+	} else {
+		opened, total, err := openWorkspaceFiles(ctx, client, folders)
+		diagnostics = client.AllDiagnostics()
+		// The files were only opened for their diagnostics, so the server needn't keep them
+		for _, path := range opened {
+			if err := client.CloseFile(ctx, path); err != nil {
+				toolsLogger.Warn("Failed to close %s: %v", path, err)
+			}
+		}
+		if err != nil {
+			return "", err
+		}
+		source = fmt.Sprintf("published diagnostics, %d files opened to collect them", len(opened))
+		if total > workspaceDiagnosticsMaxFiles {
+			source += fmt.Sprintf(", only the first %d of %d workspace files were opened", workspaceDiagnosticsMaxFiles, total)
+		}
+	}
+
+	byFile := make(map[string][]protocol.Diagnostic)
+	for uri, fileDiagnostics := range diagnostics {
+		if len(fileDiagnostics) == 0 {
+			continue
+		}
+		path := string(uri)
+		if isFileURI(uri) {
+			path = uriToPath(uri)
+			if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+				path = filepath.ToSlash(rel)
+			}
+		}
+		byFile[path] = fileDiagnostics
+	}
+
+	return formatWorkspaceDiagnostics(byFile, source, maxEntries), nil
+}
+
+// supportsWorkspaceDiagnostics reports whether the server answers workspace/diagnostic
+func supportsWorkspaceDiagnostics(capabilities protocol.ServerCapabilities) bool {
+	if capabilities.DiagnosticProvider == nil {
+		return false
+	}
+	switch v := capabilities.DiagnosticProvider.Value.(type) {
+	case protocol.DiagnosticOptions:
+		return v.WorkspaceDiagnostics
+	case protocol.DiagnosticRegistrationOptions:
+		return v.WorkspaceDiagnostics
+	}
+	return false
+}
+
+// workspaceReportDiagnostics returns the diagnostics of the full reports in a
+// workspace/diagnostic response. Unchanged reports only happen when result ids were
+// sent, which they never are.
+func workspaceReportDiagnostics(report protocol.WorkspaceDiagnosticReport) map[protocol.DocumentUri][]protocol.Diagnostic {
+	diagnostics := make(map[protocol.DocumentUri][]protocol.Diagnostic)
+	for _, item := range report.Items {
+		if full, ok := item.Value.(protocol.WorkspaceFullDocumentDiagnosticReport); ok {
+			diagnostics[full.URI] = append(diagnostics[full.URI], full.Items...)
+		}
+	}
+	return diagnostics
+}
+
+// openWorkspaceFiles opens the first workspaceDiagnosticsMaxFiles source files of the
+// workspace folders and waits, up to diagnosticsTimeout in all, for the server to
+// publish their diagnostics. It returns the files it opened, which were not open
+// before, and how many source files the folders have.
+func openWorkspaceFiles(ctx context.Context, client *lsp.Client, folders []string) ([]string, int, error) {
+	matches := workspaceFileMatcher(client)
+	var files []string
+	total := 0
+	// Folders may be nested, so a file is only counted once
+	seen := make(map[string]bool)
+	for _, folder := range folders {
+		walker := workspace.NewWalker(folder, workspace.IgnorePatternsFromEnv()...)
+		err := walker.Walk(func(path string, d fs.DirEntry) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if !matches(path) || seen[path] {
+				return nil
+			}
+			seen[path] = true
+			total++
+			if len(files) < workspaceDiagnosticsMaxFiles {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to walk workspace: %v", err)
+		}
+	}
+
+	// Diagnostics are published asynchronously after didOpen, so each file is
+	// listened for before it is opened
+	var opened []string
+	var waiters []*lsp.DiagnosticsWaiter
+	for _, path := range files {
+		if client.IsFileOpen(path) {
+			continue
+		}
+		waiter := client.ExpectDiagnostics(protocol.DocumentUri("file://" + path))
+		if err := client.OpenFile(ctx, path); err != nil {
+			waiter.Cancel()
+			toolsLogger.Warn("Failed to open %s for diagnostics: %v", path, err)
+			continue
+		}
+		opened = append(opened, path)
+		waiters = append(waiters, waiter)
+	}
+
+	deadline := time.Now().Add(diagnosticsTimeout)
+	var wg sync.WaitGroup
+	for _, waiter := range waiters {
+		wg.Add(1)
+		go func(waiter *lsp.DiagnosticsWaiter) {
+			defer wg.Done()
+			waiter.Wait(ctx, time.Until(deadline))
+		}(waiter)
+	}
+	wg.Wait()
+
+	return opened, total, nil
+}
+
+// severityRank orders severities from most to least severe, with a missing severity
+// last
+func severityRank(severity protocol.DiagnosticSeverity) int {
+	if severity < protocol.SeverityError || severity > protocol.SeverityHint {
+		return int(protocol.SeverityHint) + 1
+	}
+	return int(severity)
+}
+
+// formatSeverityCounts describes how many diagnostics of each severity there are,
+// such as "3 errors, 1 warning"
+func formatSeverityCounts(diagnostics []protocol.Diagnostic) string {
+	counts := make(map[int]int)
+	for _, diag := range diagnostics {
+		counts[severityRank(diag.Severity)]++
+	}
+
+	names := []struct {
+		rank             int
+		singular, plural string
+	}{
+		{int(protocol.SeverityError), "error", "errors"},
+		{int(protocol.SeverityWarning), "warning", "warnings"},
+		{int(protocol.SeverityInformation), "info", "infos"},
+		{int(protocol.SeverityHint), "hint", "hints"},
+		{int(protocol.SeverityHint) + 1, "other", "others"},
+	}
+	var parts []string
+	for _, name := range names {
+		switch n := counts[name.rank]; n {
+		case 0:
+		case 1:
+			parts = append(parts, "1 "+name.singular)
+		default:
+			parts = append(parts, fmt.Sprintf("%d %s", n, name.plural))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatWorkspaceDiagnostics lists the files with diagnostics, those with the most
+// errors first, then the maxEntries most severe diagnostics
+func formatWorkspaceDiagnostics(byFile map[string][]protocol.Diagnostic, source string, maxEntries int) string {
+	if len(byFile) == 0 {
+		return fmt.Sprintf("No diagnostics found in the workspace (%s)\n", source)
+	}
+
+	type entry struct {
+		file string
+		diag protocol.Diagnostic
+	}
+	var all []protocol.Diagnostic
+	var entries []entry
+	files := make([]string, 0, len(byFile))
+	for file, diagnostics := range byFile {
+		files = append(files, file)
+		all = append(all, diagnostics...)
+		for _, diag := range diagnostics {
+			entries = append(entries, entry{file, diag})
+		}
+	}
+
+	// Files with more errors come first, then more warnings and so on
+	counts := make(map[string][]int, len(byFile))
+	for file, diagnostics := range byFile {
+		counts[file] = make([]int, int(protocol.SeverityHint)+2)
+		for _, diag := range diagnostics {
+			counts[file][severityRank(diag.Severity)]++
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		a, b := counts[files[i]], counts[files[j]]
+		for rank := range a {
+			if a[rank] != b[rank] {
+				return a[rank] > b[rank]
+			}
+		}
+		return files[i] < files[j]
+	})
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if rankA, rankB := severityRank(a.diag.Severity), severityRank(b.diag.Severity); rankA != rankB {
+			return rankA < rankB
+		}
+		if a.file != b.file {
+			return a.file < b.file
+		}
+		return positionBefore(a.diag.Range.Start, b.diag.Range.Start)
+	})
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Workspace diagnostics: %s in %d files (%s)\n\n", formatSeverityCounts(all), len(files), source))
+	for _, file := range files {
+		output.WriteString(fmt.Sprintf("%s: %s\n", file, formatSeverityCounts(byFile[file])))
+	}
+
+	shown := min(maxEntries, len(entries))
+	output.WriteString(fmt.Sprintf("\nMost severe (%d of %d):\n", shown, len(entries)))
+	for _, e := range entries[:shown] {
+		summary := fmt.Sprintf("%s %s L%d:C%d: %s", getSeverityString(e.diag.Severity), e.file, e.diag.Range.Start.Line+1, e.diag.Range.Start.Character+1, strings.ReplaceAll(e.diag.Message, "\n", " "))
+		if e.diag.Source != "" {
+			summary += fmt.Sprintf(" (Source: %s)", e.diag.Source)
+		}
+		output.WriteString(summary + "\n")
+	}
+	return output.String()
+}
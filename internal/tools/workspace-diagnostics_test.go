@@ -0,0 +1,52 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatWorkspaceDiagnostics(t *testing.T) {
+	diag := func(severity protocol.DiagnosticSeverity, line uint32, message string) protocol.Diagnostic {
+		return protocol.Diagnostic{
+			Range:    protocol.Range{Start: protocol.Position{Line: line, Character: 4}},
+			Severity: severity,
+			Message:  message,
+		}
+	}
+
+	byFile := map[string][]protocol.Diagnostic{
+		"b.go": {
+			diag(protocol.SeverityWarning, 2, "unused variable"),
+			diag(protocol.SeverityError, 9, "undefined: x"),
+		},
+		"a.go": {
+			diag(protocol.SeverityHint, 0, "could be simplified"),
+			diag(protocol.SeverityWarning, 5, "unreachable code"),
+			diag(protocol.SeverityWarning, 1, "shadowed\nvariable"),
+		},
+	}
+
+	expected := "Workspace diagnostics: 1 error, 3 warnings, 1 hint in 2 files (workspace/diagnostic)\n\n" +
+		"b.go: 1 error, 1 warning\n" +
+		"a.go: 2 warnings, 1 hint\n" +
+		"\nMost severe (3 of 5):\n" +
+		"ERROR b.go L10:C5: undefined: x\n" +
+		"WARNING a.go L2:C5: shadowed variable\n" +
+		"WARNING a.go L6:C5: unreachable code\n"
+
+	assert.Equal(t, expected, formatWorkspaceDiagnostics(byFile, "workspace/diagnostic", 3))
+
+	assert.Equal(t, "No diagnostics found in the workspace (workspace/diagnostic)\n", formatWorkspaceDiagnostics(nil, "workspace/diagnostic", 3))
+}
+
+func TestSupportsWorkspaceDiagnostics(t *testing.T) {
+	assert.False(t, supportsWorkspaceDiagnostics(protocol.ServerCapabilities{}))
+	assert.False(t, supportsWorkspaceDiagnostics(protocol.ServerCapabilities{
+		DiagnosticProvider: &protocol.Or_ServerCapabilities_diagnosticProvider{Value: protocol.DiagnosticOptions{}},
+	}))
+	assert.True(t, supportsWorkspaceDiagnostics(protocol.ServerCapabilities{
+		DiagnosticProvider: &protocol.Or_ServerCapabilities_diagnosticProvider{Value: protocol.DiagnosticOptions{WorkspaceDiagnostics: true}},
+	}))
+}
@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/workspace"
+)
+
+// DefaultMaxWorkspaceFiles caps how many files ListWorkspaceFiles lists
+const DefaultMaxWorkspaceFiles = 1000
+
+// ListWorkspaceFiles lists the source files of the workspace that the language server
+// handles, by the extensions of its config or of well known servers. Files ignored
+// by .gitignore or LSP_IGNORE_PATTERNS and hidden directories are left out. For an
+// unknown server every file with a recognized language is listed.
+func ListWorkspaceFiles(ctx context.Context, client *lsp.Client) (string, error) {
+	root := client.WorkspaceDir()
+	if root == "" {
+		return "", fmt.Errorf("language server has no workspace")
+	}
+
+	extensions := client.Extensions()
+	matches := workspaceFileMatcher(client)
+
+	var files []string
+	total := 0
+	walker := workspace.NewWalker(root, workspace.IgnorePatternsFromEnv()...)
+	err := walker.Walk(func(path string, d fs.DirEntry) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !matches(path) {
+			return nil
+		}
+		total++
+		if len(files) < DefaultMaxWorkspaceFiles {
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				rel = path
+			}
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to walk workspace: %v", err)
+	}
+
+	return formatWorkspaceFiles(root, extensions, files, total), nil
+}
+
+// workspaceFileMatcher returns a filter for the files the language server handles,
+// by the extensions of its config or of well known servers, or else by whether the
+// file has a recognized language
+func workspaceFileMatcher(client *lsp.Client) func(path string) bool {
+	extensions := client.Extensions()
+	return func(path string) bool {
+		if extensions == nil {
+			return client.LanguageID(path) != ""
+		}
+		return slices.Contains(extensions, strings.ToLower(filepath.Ext(path)))
+	}
+}
+
+// formatWorkspaceFiles lists files, relative to root. total counts the files before
+// the DefaultMaxWorkspaceFiles cap.
+func formatWorkspaceFiles(root string, extensions []string, files []string, total int) string {
+	var output strings.Builder
+	filter := "files with a recognized language"
+	if extensions != nil {
+		filter = strings.Join(extensions, ", ")
+	}
+	output.WriteString(fmt.Sprintf("Workspace: %s\nFiles: %d (%s)\n\n", root, total, filter))
+
+	for _, file := range files {
+		output.WriteString(file + "\n")
+	}
+	if total > len(files) {
+		output.WriteString(fmt.Sprintf("\nShowing the first %d files.\n", len(files)))
+	}
+	return output.String()
+}
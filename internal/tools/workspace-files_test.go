@@ -0,0 +1,37 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatWorkspaceFiles(t *testing.T) {
+	testCases := []struct {
+		name       string
+		extensions []string
+		files      []string
+		total      int
+		expected   string
+	}{
+		{
+			name:       "Known extensions",
+			extensions: []string{".go"},
+			files:      []string{"main.go", "internal/tools/tools.go"},
+			total:      2,
+			expected:   "Workspace: /src\nFiles: 2 (.go)\n\nmain.go\ninternal/tools/tools.go\n",
+		},
+		{
+			name:     "Unknown server, capped",
+			files:    []string{"a.py"},
+			total:    3,
+			expected: "Workspace: /src\nFiles: 3 (files with a recognized language)\n\na.py\n\nShowing the first 1 files.\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, formatWorkspaceFiles("/src", tc.extensions, tc.files, tc.total))
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/isaacphi/mcp-language-server/internal/lsp"
+)
+
+// ChangeWorkspaceFolders adds and removes workspace folders on every language
+// server, then lists the folders. Relative paths are resolved against the current
+// directory, which is the workspace. With nothing to add or remove it only lists
+// the folders.
+func ChangeWorkspaceFolders(ctx context.Context, router *lsp.Router, add, remove []string) (string, error) {
+	added, err := absoluteFolders(add, true)
+	if err != nil {
+		return "", err
+	}
+	removed, err := absoluteFolders(remove, false)
+	if err != nil {
+		return "", err
+	}
+
+	if err := router.ChangeWorkspaceFolders(ctx, added, removed); err != nil {
+		return "", err
+	}
+	return formatWorkspaceFolders(router.WorkspaceFolders()), nil
+}
+
+// absoluteFolders makes folders absolute, checking that they are directories if
+// they must exist
+func absoluteFolders(folders []string, mustExist bool) ([]string, error) {
+	var dirs []string
+	for _, folder := range folders {
+		dir, err := filepath.Abs(folder)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get absolute path of %s: %v", folder, err)
+		}
+		if mustExist {
+			info, err := os.Stat(dir)
+			if err != nil {
+				return nil, fmt.Errorf("invalid workspace folder: %v", err)
+			}
+			if !info.IsDir() {
+				return nil, fmt.Errorf("workspace folder %s is not a directory", dir)
+			}
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs, nil
+}
+
+// formatWorkspaceFolders lists folders, the first being the workspace
+func formatWorkspaceFolders(folders []string) string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Workspace folders: %d\n\n", len(folders)))
+	for i, folder := range folders {
+		output.WriteString(folder)
+		if i == 0 {
+			output.WriteString(" (workspace)")
+		}
+		output.WriteString("\n")
+	}
+	return output.String()
+}
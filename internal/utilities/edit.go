@@ -2,8 +2,10 @@ package utilities
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
@@ -18,6 +20,7 @@ var (
 	osRemove    = os.Remove
 	osRemoveAll = os.RemoveAll
 	osRename    = os.Rename
+	osMkdirAll  = os.MkdirAll
 )
 
 // ApplyTextEdits applies a sequence of text edits to a file specified by URI
@@ -30,6 +33,21 @@ func ApplyTextEdits(uri protocol.DocumentUri, edits []protocol.TextEdit) error {
 		return fmt.Errorf("failed to read file: %w", err)
 	}
 
+	newContent, err := ApplyTextEditsToContent(content, edits)
+	if err != nil {
+		return err
+	}
+
+	if err := osWriteFile(path, newContent, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+// ApplyTextEditsToContent applies a sequence of text edits to file content in memory
+// and returns the result
+func ApplyTextEditsToContent(content []byte, edits []protocol.TextEdit) ([]byte, error) {
 	// Detect line ending style
 	var lineEnding string
 	if bytes.Contains(content, []byte("\r\n")) {
@@ -48,7 +66,7 @@ func ApplyTextEdits(uri protocol.DocumentUri, edits []protocol.TextEdit) error {
 	for i, edit1 := range edits {
 		for j := i + 1; j < len(edits); j++ {
 			if RangesOverlap(edit1.Range, edits[j].Range) {
-				return fmt.Errorf("overlapping edits detected between edit %d and %d", i, j)
+				return nil, fmt.Errorf("overlapping edits detected between edit %d and %d", i, j)
 			}
 		}
 	}
@@ -67,7 +85,7 @@ func ApplyTextEdits(uri protocol.DocumentUri, edits []protocol.TextEdit) error {
 	for _, edit := range sortedEdits {
 		newLines, err := ApplyTextEdit(lines, edit, lineEnding)
 		if err != nil {
-			return fmt.Errorf("failed to apply edit: %w", err)
+			return nil, fmt.Errorf("failed to apply edit: %w", err)
 		}
 		lines = newLines
 	}
@@ -86,11 +104,7 @@ func ApplyTextEdits(uri protocol.DocumentUri, edits []protocol.TextEdit) error {
 		newContent.WriteString(lineEnding)
 	}
 
-	if err := osWriteFile(path, []byte(newContent.String()), 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
-
-	return nil
+	return []byte(newContent.String()), nil
 }
 
 // ApplyTextEdit applies a single text edit to a set of lines
@@ -170,18 +184,25 @@ func ApplyTextEdit(lines []string, edit protocol.TextEdit, lineEnding string) ([
 	return result, nil
 }
 
-// ApplyDocumentChange applies a DocumentChange (create/rename/delete operations)
+// ApplyDocumentChange applies a DocumentChange (create/rename/delete operations).
+// Resource operations follow the LSP defaults: existing files are not overwritten
+// unless Overwrite is set, and missing files are an error unless the operation
+// ignores them. Parent directories of created and renamed files are created.
 func ApplyDocumentChange(change protocol.DocumentChange) error {
 	if change.CreateFile != nil {
 		path := strings.TrimPrefix(string(change.CreateFile.URI), "file://")
-		if change.CreateFile.Options != nil {
-			if change.CreateFile.Options.Overwrite {
-				// Proceed with overwrite
-			} else if change.CreateFile.Options.IgnoreIfExists {
-				if _, err := osStat(path); err == nil {
-					return nil // File exists and we're ignoring it
-				}
+		options := change.CreateFile.Options
+		if options == nil {
+			options = &protocol.CreateFileOptions{}
+		}
+		if _, err := osStat(path); err == nil && !options.Overwrite {
+			if options.IgnoreIfExists {
+				return nil // File exists and we're ignoring it
 			}
+			return fmt.Errorf("file already exists and overwrite is not allowed: %s", path)
+		}
+		if err := osMkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
 		}
 		if err := osWriteFile(path, []byte(""), 0644); err != nil {
 			return fmt.Errorf("failed to create file: %w", err)
@@ -190,7 +211,14 @@ func ApplyDocumentChange(change protocol.DocumentChange) error {
 
 	if change.DeleteFile != nil {
 		path := strings.TrimPrefix(string(change.DeleteFile.URI), "file://")
-		if change.DeleteFile.Options != nil && change.DeleteFile.Options.Recursive {
+		options := change.DeleteFile.Options
+		if options == nil {
+			options = &protocol.DeleteFileOptions{}
+		}
+		if _, err := osStat(path); errors.Is(err, os.ErrNotExist) && options.IgnoreIfNotExists {
+			return nil
+		}
+		if options.Recursive {
 			if err := osRemoveAll(path); err != nil {
 				return fmt.Errorf("failed to delete directory recursively: %w", err)
 			}
@@ -204,12 +232,18 @@ func ApplyDocumentChange(change protocol.DocumentChange) error {
 	if change.RenameFile != nil {
 		oldPath := strings.TrimPrefix(string(change.RenameFile.OldURI), "file://")
 		newPath := strings.TrimPrefix(string(change.RenameFile.NewURI), "file://")
-		if change.RenameFile.Options != nil {
-			if !change.RenameFile.Options.Overwrite {
-				if _, err := osStat(newPath); err == nil {
-					return fmt.Errorf("target file already exists and overwrite is not allowed: %s", newPath)
-				}
+		options := change.RenameFile.Options
+		if options == nil {
+			options = &protocol.RenameFileOptions{}
+		}
+		if _, err := osStat(newPath); err == nil && !options.Overwrite {
+			if options.IgnoreIfExists {
+				return nil
 			}
+			return fmt.Errorf("target file already exists and overwrite is not allowed: %s", newPath)
+		}
+		if err := osMkdirAll(filepath.Dir(newPath), 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
 		}
 		if err := osRename(oldPath, newPath); err != nil {
 			return fmt.Errorf("failed to rename file: %w", err)
@@ -231,8 +265,22 @@ func ApplyDocumentChange(change protocol.DocumentChange) error {
 	return nil
 }
 
-// ApplyWorkspaceEdit applies the given WorkspaceEdit to the filesystem
+// ApplyWorkspaceEdit applies the given WorkspaceEdit to the filesystem. When the
+// edit has DocumentChanges they are applied in order, so text edits can target a
+// file created or renamed by an earlier operation, and Changes is ignored as the
+// LSP specification requires.
 func ApplyWorkspaceEdit(edit protocol.WorkspaceEdit) error {
+	// Handle DocumentChanges field
+	if len(edit.DocumentChanges) > 0 {
+		for _, change := range edit.DocumentChanges {
+			coreLogger.Debug("Document change: %v", spew.Sdump(change))
+			if err := ApplyDocumentChange(change); err != nil {
+				return fmt.Errorf("failed to apply document change: %w", err)
+			}
+		}
+		return nil
+	}
+
 	// Handle Changes field
 	for uri, textEdits := range edit.Changes {
 		if err := ApplyTextEdits(uri, textEdits); err != nil {
@@ -240,14 +288,6 @@ func ApplyWorkspaceEdit(edit protocol.WorkspaceEdit) error {
 		}
 	}
 
-	// Handle DocumentChanges field
-	for _, change := range edit.DocumentChanges {
-		coreLogger.Warn("Document change: %v", spew.Sdump(change))
-		if err := ApplyDocumentChange(change); err != nil {
-			return fmt.Errorf("failed to apply document change: %w", err)
-		}
-	}
-
 	return nil
 }
 
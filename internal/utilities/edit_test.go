@@ -27,6 +27,7 @@ func setupMockFileSystem(_ *testing.T, mfs *mockFileSystem) func() {
 	originalRemove := osRemove
 	originalRemoveAll := osRemoveAll
 	originalRename := osRename
+	originalMkdirAll := osMkdirAll
 
 	// Replace with mocks
 	osReadFile = func(filename string) ([]byte, error) {
@@ -96,6 +97,13 @@ func setupMockFileSystem(_ *testing.T, mfs *mockFileSystem) func() {
 		return os.ErrNotExist
 	}
 
+	osMkdirAll = func(path string, perm os.FileMode) error {
+		if err, ok := mfs.errors[path+"_mkdirall"]; ok {
+			return err
+		}
+		return nil
+	}
+
 	// Return cleanup function
 	return func() {
 		osReadFile = originalReadFile
@@ -104,6 +112,7 @@ func setupMockFileSystem(_ *testing.T, mfs *mockFileSystem) func() {
 		osRemove = originalRemove
 		osRemoveAll = originalRemoveAll
 		osRename = originalRename
+		osMkdirAll = originalMkdirAll
 	}
 }
 
@@ -1028,6 +1037,90 @@ func TestApplyWorkspaceEdit(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "Rename file then edit it",
+			edit: protocol.WorkspaceEdit{
+				// Ignored, since documentChanges are given
+				Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+					"file:///test/Old.java": {
+						{
+							Range:   protocol.Range{End: protocol.Position{Line: 0, Character: 5}},
+							NewText: "stale",
+						},
+					},
+				},
+				DocumentChanges: []protocol.DocumentChange{
+					{
+						RenameFile: &protocol.RenameFile{
+							OldURI: "file:///test/Old.java",
+							NewURI: "file:///test/renamed/New.java",
+						},
+					},
+					{
+						TextDocumentEdit: &protocol.TextDocumentEdit{
+							TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
+								TextDocumentIdentifier: protocol.TextDocumentIdentifier{
+									URI: "file:///test/renamed/New.java",
+								},
+							},
+							Edits: []protocol.Or_TextDocumentEdit_edits_Elem{
+								{
+									Value: protocol.TextEdit{
+										Range: protocol.Range{
+											Start: protocol.Position{Line: 0, Character: 13},
+											End:   protocol.Position{Line: 0, Character: 16},
+										},
+										NewText: "New",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectErr: false,
+			setupMocks: func(mfs *mockFileSystem) {
+				mfs.files = map[string][]byte{
+					"/test/Old.java": []byte("public class Old {}"),
+				}
+			},
+			checkState: func(t *testing.T, mfs *mockFileSystem) {
+				if _, ok := mfs.files["/test/Old.java"]; ok {
+					t.Errorf("Old file still exists")
+				}
+				if content, ok := mfs.files["/test/renamed/New.java"]; !ok {
+					t.Errorf("Renamed file not found")
+				} else if string(content) != "public class New {}" {
+					t.Errorf("Edit to renamed file not applied correctly, content: %s", string(content))
+				}
+			},
+		},
+		{
+			name: "Create file that exists",
+			edit: protocol.WorkspaceEdit{
+				DocumentChanges: []protocol.DocumentChange{
+					{
+						CreateFile: &protocol.CreateFile{
+							URI: "file:///test/existing.txt",
+						},
+					},
+				},
+			},
+			expectErr: true,
+			setupMocks: func(mfs *mockFileSystem) {
+				mfs.files = map[string][]byte{
+					"/test/existing.txt": []byte("existing content"),
+				}
+				mfs.fileStats = map[string]os.FileInfo{
+					"/test/existing.txt": mockFileInfo{name: "existing.txt"},
+				}
+			},
+			checkState: func(t *testing.T, mfs *mockFileSystem) {
+				if content := mfs.files["/test/existing.txt"]; string(content) != "existing content" {
+					t.Errorf("Existing file was overwritten, content: %s", string(content))
+				}
+			},
+		},
 		{
 			name: "Error in Changes field",
 			edit: protocol.WorkspaceEdit{
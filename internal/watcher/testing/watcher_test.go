@@ -138,6 +138,15 @@ func TestWatcherBasicFunctionality(t *testing.T) {
 		if count > 1 {
 			t.Errorf("Multiple change events received for %s: %d", filePath, count)
 		}
+
+		// The symbol cache is dropped once the server has been told
+		deadline := time.Now().Add(time.Second)
+		for mockClient.CountInvalidations() == 0 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if mockClient.CountInvalidations() == 0 {
+			t.Errorf("Symbol cache not invalidated after %s changed", filePath)
+		}
 	})
 
 	t.Run("FileDeletion", func(t *testing.T) {
@@ -0,0 +1,106 @@
+// Package workspace walks the files of a workspace the way git sees them
+package workspace
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gitignore "github.com/sabhiram/go-gitignore"
+)
+
+// IgnorePatternsFromEnv returns the comma separated gitignore patterns in
+// LSP_IGNORE_PATTERNS, which are ignored on top of the workspace's .gitignore files
+func IgnorePatternsFromEnv() []string {
+	var patterns []string
+	for _, pattern := range strings.Split(os.Getenv("LSP_IGNORE_PATTERNS"), ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+// Walker walks the files under a workspace directory. It skips hidden directories,
+// paths ignored by the .gitignore files in the tree, and paths matching its extra
+// gitignore style patterns.
+type Walker struct {
+	root  string
+	extra *gitignore.GitIgnore
+}
+
+// NewWalker creates a walker for root. extraPatterns use .gitignore syntax and are
+// matched against paths relative to root.
+func NewWalker(root string, extraPatterns ...string) *Walker {
+	return &Walker{
+		root:  root,
+		extra: gitignore.CompileIgnoreLines(extraPatterns...),
+	}
+}
+
+// Walk calls fn for every file that is not ignored, in lexical order. Directories
+// that can't be read are skipped. fn may return filepath.SkipAll to stop early.
+func (w *Walker) Walk(fn func(path string, d fs.DirEntry) error) error {
+	// .gitignore files by the directory they apply to
+	ignores := make(map[string]*gitignore.GitIgnore)
+
+	return filepath.WalkDir(w.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == w.root {
+				return err
+			}
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if path != w.root && w.ignored(ignores, path, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() {
+			if ignore, err := gitignore.CompileIgnoreFile(filepath.Join(path, ".gitignore")); err == nil {
+				ignores[path] = ignore
+			}
+			return nil
+		}
+
+		return fn(path, d)
+	})
+}
+
+// ignored reports whether path is hidden or matched by the extra patterns or the
+// .gitignore of any directory above it
+func (w *Walker) ignored(ignores map[string]*gitignore.GitIgnore, path string, isDir bool) bool {
+	if isDir && strings.HasPrefix(filepath.Base(path), ".") {
+		return true
+	}
+
+	if matches(w.extra, w.root, path, isDir) {
+		return true
+	}
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		if ignore, ok := ignores[dir]; ok && matches(ignore, dir, path, isDir) {
+			return true
+		}
+		if dir == w.root || dir == filepath.Dir(dir) {
+			return false
+		}
+	}
+}
+
+// matches reports whether ignore matches path relative to dir. Directories are also
+// matched with a trailing slash so patterns like "build/" apply to them.
+func matches(ignore *gitignore.GitIgnore, dir, path string, isDir bool) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	return ignore.MatchesPath(rel) || (isDir && ignore.MatchesPath(rel+"/"))
+}
@@ -0,0 +1,87 @@
+package workspace
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// walkedFiles returns the paths, relative to root, of the files a walker visits
+func walkedFiles(t *testing.T, root string, extraPatterns ...string) []string {
+	var files []string
+	err := NewWalker(root, extraPatterns...).Walk(func(path string, d fs.DirEntry) error {
+		rel, err := filepath.Rel(root, path)
+		require.NoError(t, err)
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	require.NoError(t, err)
+	return files
+}
+
+func TestWalker(t *testing.T) {
+	root := t.TempDir()
+	for path, content := range map[string]string{
+		".gitignore":                  "third_party/\n*.o\n",
+		"main.cpp":                    "",
+		"main.o":                      "",
+		"third_party/lib/lib.cpp":     "",
+		".git/config":                 "",
+		"src/.gitignore":              "generated.cpp\n",
+		"src/util.cpp":                "",
+		"src/generated.cpp":           "",
+		"generated.cpp":               "",
+		"build/out.cpp":               "",
+		"vendor/dep/dep.cpp":          "",
+		"docs/notes/third_party/x.md": "",
+	} {
+		full := filepath.Join(root, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+	}
+
+	testCases := []struct {
+		name          string
+		extraPatterns []string
+		expected      []string
+	}{
+		{
+			name: "Gitignore files",
+			expected: []string{
+				".gitignore",
+				"build/out.cpp",
+				"generated.cpp",
+				"main.cpp",
+				"src/.gitignore",
+				"src/util.cpp",
+				"vendor/dep/dep.cpp",
+			},
+		},
+		{
+			name:          "Extra patterns",
+			extraPatterns: []string{"build/", "vendor"},
+			expected: []string{
+				".gitignore",
+				"generated.cpp",
+				"main.cpp",
+				"src/.gitignore",
+				"src/util.cpp",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, walkedFiles(t, root, tc.extraPatterns...))
+		})
+	}
+}
+
+func TestIgnorePatternsFromEnv(t *testing.T) {
+	t.Setenv("LSP_IGNORE_PATTERNS", " vendor/, ,*.pb.cc")
+	assert.Equal(t, []string{"vendor/", "*.pb.cc"}, IgnorePatternsFromEnv())
+}
@@ -2,18 +2,23 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
-	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"strconv"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/isaacphi/mcp-language-server/internal/logging"
 	"github.com/isaacphi/mcp-language-server/internal/lsp"
+	"github.com/isaacphi/mcp-language-server/internal/protocol"
+	"github.com/isaacphi/mcp-language-server/internal/tools"
 	"github.com/isaacphi/mcp-language-server/internal/watcher"
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
@@ -24,23 +29,65 @@ type config struct {
 	workspaceDir string
 	lspCommand   string
 	lspArgs      []string
+	// primary is the server started at launch, from --lsp or the config file
+	primary lsp.ServerConfig
+	servers []lsp.ServerConfig
+	// workspaceFolders are additional roots, sent to the servers with workspaceDir
+	workspaceFolders []string
+	// contextLines are the config file's context line defaults by languageId
+	contextLines map[string]int
+}
+
+// serverFlag collects repeated --server flags
+type serverFlag []lsp.ServerConfig
+
+func (f *serverFlag) String() string { return "" }
+
+func (f *serverFlag) Set(value string) error {
+	server, err := lsp.ParseServerConfig(value)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, server)
+	return nil
+}
+
+// folderFlag collects repeated --workspace-folder flags
+type folderFlag []string
+
+func (f *folderFlag) String() string { return "" }
+
+func (f *folderFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
 }
 
 type mcpServer struct {
 	config           config
 	lspClient        *lsp.Client
+	router           *lsp.Router
 	mcpServer        *server.MCPServer
 	ctx              context.Context
 	cancelFunc       context.CancelFunc
 	workspaceWatcher *watcher.WorkspaceWatcher
+	cleanupOnce      sync.Once
+	toolNames        map[string]bool
 }
 
 func parseConfig() (*config, error) {
 	cfg := &config{}
 	flag.StringVar(&cfg.workspaceDir, "workspace", "", "Path to workspace directory")
 	flag.StringVar(&cfg.lspCommand, "lsp", "", "LSP command to run (args should be passed after --)")
+	var initOptions string
+	flag.StringVar(&initOptions, "init-options", "", "JSON object sent as initializationOptions to the --lsp server, merged over the built in defaults")
+	var servers serverFlag
+	flag.Var(&servers, "server", "Additional language server for some file extensions, as \"ext1,ext2=command [args...]\". May be repeated.")
+	var folders folderFlag
+	flag.Var(&folders, "workspace-folder", "Additional workspace root, such as a dependency checked out next to the workspace. May be repeated.")
 	flag.Parse()
 
+	cfg.servers = servers
+
 	// Get remaining args after -- as LSP arguments
 	cfg.lspArgs = flag.Args()
 
@@ -59,13 +106,68 @@ func parseConfig() (*config, error) {
 		return nil, fmt.Errorf("workspace directory does not exist: %s", cfg.workspaceDir)
 	}
 
+	for _, folder := range folders {
+		dir, err := filepath.Abs(folder)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get absolute path for workspace folder: %v", err)
+		}
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			return nil, fmt.Errorf("workspace folder does not exist: %s", dir)
+		}
+		cfg.workspaceFolders = append(cfg.workspaceFolders, dir)
+	}
+
+	if _, _, err := lsp.InitializeRoot(cfg.workspaceDir); err != nil {
+		return nil, err
+	}
+
+	fileConfig, err := lsp.LoadConfig(cfg.workspaceDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// --lsp takes precedence over the config file. Without it the first configured
+	// server is the primary one and the rest are routed by extension.
+	var configServers []lsp.ServerConfig
+	if fileConfig != nil {
+		configServers = fileConfig.Servers
+		cfg.contextLines = fileConfig.ContextLines
+	}
+	if cfg.lspCommand != "" {
+		cfg.primary = lsp.ServerConfig{Command: cfg.lspCommand, Args: cfg.lspArgs}
+		if initOptions != "" {
+			if err := json.Unmarshal([]byte(initOptions), &cfg.primary.InitializationOptions); err != nil {
+				return nil, fmt.Errorf("invalid --init-options, expected a JSON object: %v", err)
+			}
+		}
+	} else if initOptions != "" {
+		return nil, fmt.Errorf("--init-options requires --lsp, set initializationOptions in %s instead", lsp.ConfigFileName)
+	} else if len(configServers) > 0 {
+		cfg.primary = configServers[0]
+		configServers = configServers[1:]
+	}
+
 	// Validate LSP command
-	if cfg.lspCommand == "" {
-		return nil, fmt.Errorf("LSP command is required")
+	if cfg.primary.Command == "" && cfg.primary.Transport != lsp.TransportTCP {
+		return nil, fmt.Errorf("LSP command is required, pass --lsp or add a server to %s", lsp.ConfigFileName)
+	}
+
+	for _, server := range configServers {
+		if len(server.Extensions) == 0 {
+			return nil, fmt.Errorf("configured server %s needs extensions unless it is the primary server", server.Name())
+		}
+	}
+	// Servers from --server flags are matched before those from the config file
+	cfg.servers = append(cfg.servers, configServers...)
+
+	if err := cfg.primary.Check(); err != nil {
+		return nil, err
 	}
 
-	if _, err := exec.LookPath(cfg.lspCommand); err != nil {
-		return nil, fmt.Errorf("LSP command not found: %s", cfg.lspCommand)
+	for _, server := range cfg.servers {
+		if err := server.Check(); err != nil {
+			return nil, err
+		}
 	}
 
 	return cfg, nil
@@ -77,6 +179,7 @@ func newServer(config *config) (*mcpServer, error) {
 		config:     *config,
 		ctx:        ctx,
 		cancelFunc: cancel,
+		toolNames:  make(map[string]bool),
 	}, nil
 }
 
@@ -85,13 +188,19 @@ func (s *mcpServer) initializeLSP() error {
 		return fmt.Errorf("failed to change to workspace directory: %v", err)
 	}
 
-	client, err := lsp.NewClient(s.config.lspCommand, s.config.lspArgs...)
+	tools.SetLanguageContextLines(s.config.contextLines)
+
+	client, err := lsp.NewClientFromConfig(s.config.primary)
 	if err != nil {
 		return fmt.Errorf("failed to create LSP client: %v", err)
 	}
 	s.lspClient = client
 	s.workspaceWatcher = watcher.NewWorkspaceWatcher(client)
 
+	if err := client.ChangeWorkspaceFolders(s.ctx, s.config.workspaceFolders, nil); err != nil {
+		return fmt.Errorf("failed to set workspace folders: %v", err)
+	}
+
 	initResult, err := client.InitializeLSPClient(s.ctx, s.config.workspaceDir)
 	if err != nil {
 		return fmt.Errorf("initialize failed: %v", err)
@@ -100,9 +209,32 @@ func (s *mcpServer) initializeLSP() error {
 	coreLogger.Debug("Server capabilities: %+v", initResult.Capabilities)
 
 	go s.workspaceWatcher.WatchWorkspace(s.ctx, s.config.workspaceDir)
+
+	// Additional servers get their own watcher once they are started
+	s.router = lsp.NewRouter(s.ctx, s.config.workspaceDir, client, s.config.servers, func(c *lsp.Client) {
+		go watcher.NewWorkspaceWatcher(c).WatchWorkspace(s.ctx, s.config.workspaceDir)
+		if diagnosticsNotificationsEnabled() {
+			c.OnDiagnostics(s.notifyDiagnostics)
+		}
+	})
+
 	return client.WaitForServerReady(s.ctx)
 }
 
+// clientForFile returns the language server client responsible for a file
+func (s *mcpServer) clientForFile(filePath string) (*lsp.Client, error) {
+	return s.router.ClientForFile(filePath)
+}
+
+// clientForSymbol returns the language server client that knows about a symbol
+func (s *mcpServer) clientForSymbol(symbolName string) (*lsp.Client, error) {
+	// Invalid names are left as they are, the tool reports the error
+	if name, err := tools.NormalizeSymbolName(symbolName); err == nil {
+		symbolName = name
+	}
+	return s.router.ClientForSymbol(s.ctx, symbolName)
+}
+
 func (s *mcpServer) start() error {
 	if err := s.initializeLSP(); err != nil {
 		return err
@@ -120,9 +252,43 @@ func (s *mcpServer) start() error {
 		return fmt.Errorf("tool registration failed: %v", err)
 	}
 
+	if diagnosticsNotificationsEnabled() {
+		s.lspClient.OnDiagnostics(s.notifyDiagnostics)
+	}
+
 	return server.ServeStdio(s.mcpServer)
 }
 
+// diagnosticsNotificationsEnabled reports whether LSP_DIAGNOSTICS_NOTIFICATIONS asks
+// for diagnostics to be streamed to the MCP client
+func diagnosticsNotificationsEnabled() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("LSP_DIAGNOSTICS_NOTIFICATIONS"))
+	return err == nil && enabled
+}
+
+// rawRequestsEnabled reports whether LSP_ENABLE_RAW asks for the raw_request tool
+func rawRequestsEnabled() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("LSP_ENABLE_RAW"))
+	return err == nil && enabled
+}
+
+// notifyDiagnostics forwards the diagnostics a language server publishes for a file
+// to the MCP client as a logging notification, keyed by the file path. An empty list
+// means the file's diagnostics were cleared.
+func (s *mcpServer) notifyDiagnostics(uri protocol.DocumentUri, diagnostics []protocol.Diagnostic) {
+	if diagnostics == nil {
+		diagnostics = []protocol.Diagnostic{}
+	}
+	s.mcpServer.SendNotificationToAllClients("notifications/message", map[string]any{
+		"level":  mcp.LoggingLevelInfo,
+		"logger": "diagnostics",
+		"data": map[string]any{
+			"file":        uri.Path(),
+			"diagnostics": diagnostics,
+		},
+	})
+}
+
 func main() {
 	coreLogger.Info("MCP Language Server starting")
 
@@ -185,61 +351,40 @@ func main() {
 		os.Exit(1)
 	}
 
+	// The MCP client closed stdin, so nothing else will stop the language servers
+	coreLogger.Info("MCP client disconnected, initiating shutdown")
+	cleanup(server, done)
+
 	<-done
 	coreLogger.Info("Server shutdown complete for PID: %d", os.Getpid())
 	os.Exit(0)
 }
 
+// cleanup stops the language servers and closes done. It runs once, however many
+// shutdown triggers fire.
 func cleanup(s *mcpServer, done chan struct{}) {
-	coreLogger.Info("Cleanup initiated for PID: %d", os.Getpid())
-
-	// Create a context with timeout for shutdown operations
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if s.lspClient != nil {
-		coreLogger.Info("Closing open files")
-		s.lspClient.CloseAllFiles(ctx)
-
-		// Create a shorter timeout context for the shutdown request
-		shutdownCtx, shutdownCancel := context.WithTimeout(ctx, 500*time.Millisecond)
-		defer shutdownCancel()
-
-		// Run shutdown in a goroutine with timeout to avoid blocking if LSP doesn't respond
-		shutdownDone := make(chan struct{})
-		go func() {
-			coreLogger.Info("Sending shutdown request")
-			if err := s.lspClient.Shutdown(shutdownCtx); err != nil {
-				coreLogger.Error("Shutdown request failed: %v", err)
-			}
-			close(shutdownDone)
-		}()
-
-		// Wait for shutdown with timeout
-		select {
-		case <-shutdownDone:
-			coreLogger.Info("Shutdown request completed")
-		case <-time.After(1 * time.Second):
-			coreLogger.Warn("Shutdown request timed out, proceeding with exit")
+	s.cleanupOnce.Do(func() {
+		coreLogger.Info("Cleanup initiated for PID: %d", os.Getpid())
+
+		// Create a context with timeout for shutdown operations
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		var clients []*lsp.Client
+		if s.router != nil {
+			clients = s.router.Clients()
+		} else if s.lspClient != nil {
+			clients = append(clients, s.lspClient)
 		}
 
-		coreLogger.Info("Sending exit notification")
-		if err := s.lspClient.Exit(ctx); err != nil {
-			coreLogger.Error("Exit notification failed: %v", err)
+		for _, client := range clients {
+			coreLogger.Info("Shutting down language server %s", client.Name())
+			if err := client.Stop(ctx); err != nil {
+				coreLogger.Error("Failed to stop language server: %v", err)
+			}
 		}
 
-		coreLogger.Info("Closing LSP client")
-		if err := s.lspClient.Close(); err != nil {
-			coreLogger.Error("Failed to close LSP client: %v", err)
-		}
-	}
-
-	// Send signal to the done channel
-	select {
-	case <-done: // Channel already closed
-	default:
 		close(done)
-	}
-
-	coreLogger.Info("Cleanup completed for PID: %d", os.Getpid())
+		coreLogger.Info("Cleanup completed for PID: %d", os.Getpid())
+	})
 }
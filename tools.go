@@ -3,11 +3,32 @@ package main
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/isaacphi/mcp-language-server/internal/tools"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 )
 
+// addTool registers a tool whose text output is cut to LSP_MAX_OUTPUT_BYTES, so the
+// limit holds for every tool and output format
+func (s *mcpServer) addTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	s.toolNames[tool.Name] = true
+	s.mcpServer.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := handler(ctx, request)
+		if result != nil {
+			for i, content := range result.Content {
+				if text, ok := content.(mcp.TextContent); ok {
+					text.Text = tools.LimitOutput(text.Text)
+					result.Content[i] = text
+				}
+			}
+		}
+		return result, err
+	})
+}
+
 func (s *mcpServer) registerTools() error {
 	coreLogger.Debug("Registering MCP tools")
 
@@ -41,7 +62,7 @@ func (s *mcpServer) registerTools() error {
 		),
 	)
 
-	s.mcpServer.AddTool(applyTextEditTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(applyTextEditTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments
 		filePath, ok := request.Params.Arguments["filePath"].(string)
 		if !ok {
@@ -87,7 +108,11 @@ func (s *mcpServer) registerTools() error {
 		}
 
 		coreLogger.Debug("Executing edit_file for file: %s", filePath)
-		response, err := tools.ApplyTextEdits(s.ctx, s.lspClient, filePath, edits)
+		client, err := s.clientForFile(filePath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		response, err := tools.ApplyTextEdits(s.ctx, client, filePath, edits)
 		if err != nil {
 			coreLogger.Error("Failed to apply edits: %v", err)
 			return mcp.NewToolResultError(fmt.Sprintf("failed to apply edits: %v", err)), nil
@@ -101,17 +126,65 @@ func (s *mcpServer) registerTools() error {
 			mcp.Required(),
 			mcp.Description("The name of the symbol whose definition you want to find (e.g. 'mypackage.MyFunction', 'MyType.MyMethod')"),
 		),
+		mcp.WithString("matchMode",
+			mcp.Description("How symbol names must match: 'fuzzy' (default) keeps every match from the language server, 'exact' requires the name or qualified name to equal symbolName, 'prefix' requires it to start with symbolName"),
+			mcp.Enum(tools.MatchModeFuzzy, tools.MatchModeExact, tools.MatchModePrefix),
+		),
+		mcp.WithNumber("maxResults",
+			mcp.Description("Maximum number of definitions to return. Defaults to 50."),
+		),
+		mcp.WithString("scope",
+			mcp.Description("Which part of each definition to return: 'full' (default) for the whole definition, 'signature' for the lines up to the opening of the body, 'body' for the lines inside it"),
+			mcp.Enum(tools.ScopeFull, tools.ScopeSignature, tools.ScopeBody),
+		),
+		mcp.WithNumber("maxDefinitionLines",
+			mcp.Description("Truncate definitions longer than this many lines, keeping the signature and closing line. Defaults to LSP_MAX_DEFINITION_LINES, or no limit."),
+		),
+		mcp.WithString("inFile",
+			mcp.Description("Only return definitions whose file path contains this text (e.g. 'net/http', 'Widget.cpp'), to pick one of several symbols sharing a name"),
+		),
 	)
 
-	s.mcpServer.AddTool(readDefinitionTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(readDefinitionTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments
 		symbolName, ok := request.Params.Arguments["symbolName"].(string)
 		if !ok {
 			return mcp.NewToolResultError("symbolName must be a string"), nil
 		}
 
+		var opts tools.DefinitionOptions
+		if matchMode, ok := request.Params.Arguments["matchMode"].(string); ok {
+			opts.MatchMode = matchMode
+		}
+		if scope, ok := request.Params.Arguments["scope"].(string); ok {
+			opts.Scope = scope
+		}
+		if inFile, ok := request.Params.Arguments["inFile"].(string); ok {
+			opts.InFile = inFile
+		}
+
+		// Handle both float64 and int for maxResults due to JSON parsing
+		switch v := request.Params.Arguments["maxResults"].(type) {
+		case float64:
+			opts.MaxResults = int(v)
+		case int:
+			opts.MaxResults = v
+		}
+
+		// Handle both float64 and int for maxDefinitionLines due to JSON parsing
+		switch v := request.Params.Arguments["maxDefinitionLines"].(type) {
+		case float64:
+			opts.MaxLines = int(v)
+		case int:
+			opts.MaxLines = v
+		}
+
 		coreLogger.Debug("Executing definition for symbol: %s", symbolName)
-		text, err := tools.ReadDefinition(s.ctx, s.lspClient, symbolName)
+		client, err := s.clientForSymbol(symbolName)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.ReadDefinition(s.ctx, client, symbolName, opts)
 		if err != nil {
 			coreLogger.Error("Failed to get definition: %v", err)
 			return mcp.NewToolResultError(fmt.Sprintf("failed to get definition: %v", err)), nil
@@ -119,154 +192,232 @@ func (s *mcpServer) registerTools() error {
 		return mcp.NewToolResultText(text), nil
 	})
 
-	findReferencesTool := mcp.NewTool("references",
-		mcp.WithDescription("Find all usages and references of a symbol throughout the codebase. Returns a list of all files and locations where the symbol appears."),
+	readDefinitionsTool := mcp.NewTool("definitions",
+		mcp.WithDescription("Read the source code definitions of several symbols in one call. The output has one section per symbol and a final section listing the symbols that were not found."),
+		mcp.WithArray("symbolNames",
+			mcp.Required(),
+			mcp.Description("The names of the symbols whose definitions you want to find"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithString("matchMode",
+			mcp.Description("How symbol names must match: 'fuzzy' (default), 'exact' or 'prefix', as for the definition tool"),
+			mcp.Enum(tools.MatchModeFuzzy, tools.MatchModeExact, tools.MatchModePrefix),
+		),
+		mcp.WithString("scope",
+			mcp.Description("Which part of each definition to return: 'full' (default), 'signature' or 'body', as for the definition tool"),
+			mcp.Enum(tools.ScopeFull, tools.ScopeSignature, tools.ScopeBody),
+		),
+		mcp.WithNumber("maxDefinitionLines",
+			mcp.Description("Truncate definitions longer than this many lines, keeping the signature and closing line. Defaults to LSP_MAX_DEFINITION_LINES, or no limit."),
+		),
+	)
+
+	s.addTool(readDefinitionsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		namesArg, ok := request.Params.Arguments["symbolNames"].([]any)
+		if !ok || len(namesArg) == 0 {
+			return mcp.NewToolResultError("symbolNames must be a non-empty array of strings"), nil
+		}
+		var symbolNames []string
+		for _, name := range namesArg {
+			nameStr, ok := name.(string)
+			if !ok {
+				return mcp.NewToolResultError("symbolNames must be an array of strings"), nil
+			}
+			symbolNames = append(symbolNames, nameStr)
+		}
+
+		var opts tools.DefinitionOptions
+		if matchMode, ok := request.Params.Arguments["matchMode"].(string); ok {
+			opts.MatchMode = matchMode
+		}
+		if scope, ok := request.Params.Arguments["scope"].(string); ok {
+			opts.Scope = scope
+		}
+
+		// Handle both float64 and int for maxDefinitionLines due to JSON parsing
+		switch v := request.Params.Arguments["maxDefinitionLines"].(type) {
+		case float64:
+			opts.MaxLines = int(v)
+		case int:
+			opts.MaxLines = v
+		}
+
+		coreLogger.Debug("Executing definitions for symbols: %s", strings.Join(symbolNames, ", "))
+		// All symbols are looked up on the server that knows the first one
+		client, err := s.clientForSymbol(symbolNames[0])
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.ReadDefinitions(s.ctx, client, symbolNames, opts)
+		if err != nil {
+			coreLogger.Error("Failed to get definitions: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get definitions: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	searchSymbolsRegexTool := mcp.NewTool("search_symbols_regex",
+		mcp.WithDescription("List the symbols whose name or qualified name (e.g. 'Cache.Get') matches a regular expression, such as '_test$' for all names ending in _test. Best effort: the language server has no regex search, so its results for the longest literal in the pattern, or for an empty query, are filtered, and matches it doesn't return are missed."),
+		mcp.WithString("pattern",
+			mcp.Required(),
+			mcp.Description("A Go regular expression matched against each symbol's name and qualified name"),
+		),
+		mcp.WithNumber("maxResults",
+			mcp.Description("Maximum number of matches to list. Defaults to 100."),
+		),
+		mcp.WithString("filePath",
+			mcp.Description("A file whose language server to search. Defaults to the primary language server."),
+		),
+	)
+
+	s.addTool(searchSymbolsRegexTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		pattern, ok := request.Params.Arguments["pattern"].(string)
+		if !ok {
+			return mcp.NewToolResultError("pattern must be a string"), nil
+		}
+
+		// Handle both float64 and int for maxResults due to JSON parsing
+		var maxResults int
+		switch v := request.Params.Arguments["maxResults"].(type) {
+		case float64:
+			maxResults = int(v)
+		case int:
+			maxResults = v
+		}
+
+		client := s.lspClient
+		if filePath, ok := request.Params.Arguments["filePath"].(string); ok && filePath != "" {
+			var err error
+			client, err = s.clientForFile(filePath)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+			}
+		}
+
+		coreLogger.Debug("Executing search_symbols_regex for pattern: %s", pattern)
+		text, err := tools.SearchSymbolsRegex(s.ctx, client, pattern, maxResults)
+		if err != nil {
+			coreLogger.Error("Failed to search symbols: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to search symbols: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	exploreSymbolTool := mcp.NewTool("explore_symbol",
+		mcp.WithDescription("Get the definition of a symbol and its references in one call. Cheaper than calling definition and references separately, but capped to 3 definitions of at most 80 lines and references in the first 10 files, 5 per file. Use the references tool to page through the rest."),
 		mcp.WithString("symbolName",
 			mcp.Required(),
-			mcp.Description("The name of the symbol to search for (e.g. 'mypackage.MyFunction', 'MyType')"),
+			mcp.Description("The name of the symbol to explore (e.g. 'mypackage.MyFunction', 'MyType.method')"),
 		),
 	)
 
-	s.mcpServer.AddTool(findReferencesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(exploreSymbolTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments
 		symbolName, ok := request.Params.Arguments["symbolName"].(string)
 		if !ok {
 			return mcp.NewToolResultError("symbolName must be a string"), nil
 		}
 
-		coreLogger.Debug("Executing references for symbol: %s", symbolName)
-		text, err := tools.FindReferences(s.ctx, s.lspClient, symbolName)
+		coreLogger.Debug("Executing explore_symbol for symbol: %s", symbolName)
+		client, err := s.clientForSymbol(symbolName)
 		if err != nil {
-			coreLogger.Error("Failed to find references: %v", err)
-			return mcp.NewToolResultError(fmt.Sprintf("failed to find references: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.ExploreSymbol(s.ctx, client, symbolName)
+		if err != nil {
+			coreLogger.Error("Failed to explore symbol: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to explore symbol: %v", err)), nil
 		}
 		return mcp.NewToolResultText(text), nil
 	})
 
-	getDiagnosticsTool := mcp.NewTool("diagnostics",
-		mcp.WithDescription("Get diagnostic information for a specific file from the language server."),
-		mcp.WithString("filePath",
+	searchSymbolsTool := mcp.NewTool("search_symbols",
+		mcp.WithDescription("List the symbols matching a name with their kind, container, file and line, without reading their source. Cheaper than definition, and useful to pick the right symbol before reading it."),
+		mcp.WithString("query",
 			mcp.Required(),
-			mcp.Description("The path to the file to get diagnostics for"),
+			mcp.Description("The symbol name or fragment to search for"),
 		),
-		mcp.WithBoolean("contextLines",
-			mcp.Description("Lines to include around each diagnostic."),
-			mcp.DefaultBool(false),
-		),
-		mcp.WithBoolean("showLineNumbers",
-			mcp.Description("If true, adds line numbers to the output"),
-			mcp.DefaultBool(true),
+		mcp.WithNumber("maxResults",
+			mcp.Description("Maximum number of matches to list. Defaults to 100."),
 		),
 	)
 
-	s.mcpServer.AddTool(getDiagnosticsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(searchSymbolsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments
-		filePath, ok := request.Params.Arguments["filePath"].(string)
+		query, ok := request.Params.Arguments["query"].(string)
 		if !ok {
-			return mcp.NewToolResultError("filePath must be a string"), nil
+			return mcp.NewToolResultError("query must be a string"), nil
 		}
 
-		contextLines := 5 // default value
-		if contextLinesArg, ok := request.Params.Arguments["contextLines"].(int); ok {
-			contextLines = contextLinesArg
+		// Handle both float64 and int for maxResults due to JSON parsing
+		var maxResults int
+		switch v := request.Params.Arguments["maxResults"].(type) {
+		case float64:
+			maxResults = int(v)
+		case int:
+			maxResults = v
 		}
 
-		showLineNumbers := true // default value
-		if showLineNumbersArg, ok := request.Params.Arguments["showLineNumbers"].(bool); ok {
-			showLineNumbers = showLineNumbersArg
+		coreLogger.Debug("Executing search_symbols for query: %s", query)
+		client, err := s.clientForSymbol(query)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
 		}
-
-		coreLogger.Debug("Executing diagnostics for file: %s", filePath)
-		text, err := tools.GetDiagnosticsForFile(s.ctx, s.lspClient, filePath, contextLines, showLineNumbers)
+		text, err := tools.SearchSymbols(s.ctx, client, query, maxResults)
 		if err != nil {
-			coreLogger.Error("Failed to get diagnostics: %v", err)
-			return mcp.NewToolResultError(fmt.Sprintf("failed to get diagnostics: %v", err)), nil
+			coreLogger.Error("Failed to search symbols: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to search symbols: %v", err)), nil
 		}
 		return mcp.NewToolResultText(text), nil
 	})
 
-	// Uncomment to add codelens tools
-	//
-	// getCodeLensTool := mcp.NewTool("get_codelens",
-	// 	mcp.WithDescription("Get code lens hints for a given file from the language server."),
-	// 	mcp.WithString("filePath",
-	// 		mcp.Required(),
-	// 		mcp.Description("The path to the file to get code lens information for"),
-	// 	),
-	// )
-	//
-	// s.mcpServer.AddTool(getCodeLensTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	// 	// Extract arguments
-	// 	filePath, ok := request.Params.Arguments["filePath"].(string)
-	// 	if !ok {
-	// 		return mcp.NewToolResultError("filePath must be a string"), nil
-	// 	}
-	//
-	// 	coreLogger.Debug("Executing get_codelens for file: %s", filePath)
-	// 	text, err := tools.GetCodeLens(s.ctx, s.lspClient, filePath)
-	// 	if err != nil {
-	// 		coreLogger.Error("Failed to get code lens: %v", err)
-	// 		return mcp.NewToolResultError(fmt.Sprintf("failed to get code lens: %v", err)), nil
-	// 	}
-	// 	return mcp.NewToolResultText(text), nil
-	// })
-	//
-	// executeCodeLensTool := mcp.NewTool("execute_codelens",
-	// 	mcp.WithDescription("Execute a code lens command for a given file and lens index."),
-	// 	mcp.WithString("filePath",
-	// 		mcp.Required(),
-	// 		mcp.Description("The path to the file containing the code lens to execute"),
-	// 	),
-	// 	mcp.WithNumber("index",
-	// 		mcp.Required(),
-	// 		mcp.Description("The index of the code lens to execute (from get_codelens output), 1 indexed"),
-	// 	),
-	// )
-	//
-	// s.mcpServer.AddTool(executeCodeLensTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	// 	// Extract arguments
-	// 	filePath, ok := request.Params.Arguments["filePath"].(string)
-	// 	if !ok {
-	// 		return mcp.NewToolResultError("filePath must be a string"), nil
-	// 	}
-	//
-	// 	// Handle both float64 and int for index due to JSON parsing
-	// 	var index int
-	// 	switch v := request.Params.Arguments["index"].(type) {
-	// 	case float64:
-	// 		index = int(v)
-	// 	case int:
-	// 		index = v
-	// 	default:
-	// 		return mcp.NewToolResultError("index must be a number"), nil
-	// 	}
-	//
-	// 	coreLogger.Debug("Executing execute_codelens for file: %s index: %d", filePath, index)
-	// 	text, err := tools.ExecuteCodeLens(s.ctx, s.lspClient, filePath, index)
-	// 	if err != nil {
-	// 		coreLogger.Error("Failed to execute code lens: %v", err)
-	// 		return mcp.NewToolResultError(fmt.Sprintf("failed to execute code lens: %v", err)), nil
-	// 	}
-	// 	return mcp.NewToolResultText(text), nil
-	// })
+	readDeclarationTool := mcp.NewTool("declaration",
+		mcp.WithDescription("Read the declaration of a symbol, such as a function prototype in a C/C++ header, which can differ from where it is defined."),
+		mcp.WithString("symbolName",
+			mcp.Required(),
+			mcp.Description("The name of the symbol whose declaration you want to find (e.g. 'mypackage.MyFunction', 'MyClass::method')"),
+		),
+	)
 
-	hoverTool := mcp.NewTool("hover",
-		mcp.WithDescription("Get hover information (type, documentation) for a symbol at the specified position."),
+	s.addTool(readDeclarationTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		symbolName, ok := request.Params.Arguments["symbolName"].(string)
+		if !ok {
+			return mcp.NewToolResultError("symbolName must be a string"), nil
+		}
+
+		coreLogger.Debug("Executing declaration for symbol: %s", symbolName)
+		client, err := s.clientForSymbol(symbolName)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.ReadDeclaration(s.ctx, client, symbolName)
+		if err != nil {
+			coreLogger.Error("Failed to get declaration: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get declaration: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	definitionAtPositionTool := mcp.NewTool("definition_at_position",
+		mcp.WithDescription("Read the source code definition of the symbol at a position. Resolves exactly the symbol under the cursor, so prefer it over definition when a name is ambiguous or overloaded."),
 		mcp.WithString("filePath",
 			mcp.Required(),
-			mcp.Description("The path to the file to get hover information for"),
+			mcp.Description("The path to the file containing the symbol usage"),
 		),
 		mcp.WithNumber("line",
 			mcp.Required(),
-			mcp.Description("The line number where the hover is requested (1-indexed)"),
+			mcp.Description("The line number of the symbol (1-indexed)"),
 		),
 		mcp.WithNumber("column",
 			mcp.Required(),
-			mcp.Description("The column number where the hover is requested (1-indexed)"),
+			mcp.Description("The column number of the symbol (1-indexed)"),
 		),
 	)
 
-	s.mcpServer.AddTool(hoverTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(definitionAtPositionTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments
 		filePath, ok := request.Params.Arguments["filePath"].(string)
 		if !ok {
@@ -293,47 +444,42 @@ func (s *mcpServer) registerTools() error {
 			return mcp.NewToolResultError("column must be a number"), nil
 		}
 
-		coreLogger.Debug("Executing hover for file: %s line: %d column: %d", filePath, line, column)
-		text, err := tools.GetHoverInfo(s.ctx, s.lspClient, filePath, line, column)
+		coreLogger.Debug("Executing definition_at_position for file: %s line: %d column: %d", filePath, line, column)
+		client, err := s.clientForFile(filePath)
 		if err != nil {
-			coreLogger.Error("Failed to get hover information: %v", err)
-			return mcp.NewToolResultError(fmt.Sprintf("failed to get hover information: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.ReadDefinitionAtPosition(s.ctx, client, filePath, line, column)
+		if err != nil {
+			coreLogger.Error("Failed to get definition: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get definition: %v", err)), nil
 		}
 		return mcp.NewToolResultText(text), nil
 	})
 
-	renameSymbolTool := mcp.NewTool("rename_symbol",
-		mcp.WithDescription("Rename a symbol (variable, function, class, etc.) at the specified position and update all references throughout the codebase."),
+	typeDefinitionTool := mcp.NewTool("type_definition",
+		mcp.WithDescription("Read the source code definition of the type of the expression at a position, such as the struct or class of a variable. Use it to jump from a usage to its type when the declaration and the type's definition are far apart."),
 		mcp.WithString("filePath",
 			mcp.Required(),
-			mcp.Description("The path to the file containing the symbol to rename"),
+			mcp.Description("The path to the file containing the expression"),
 		),
 		mcp.WithNumber("line",
 			mcp.Required(),
-			mcp.Description("The line number where the symbol is located (1-indexed)"),
+			mcp.Description("The line number of the expression (1-indexed)"),
 		),
 		mcp.WithNumber("column",
 			mcp.Required(),
-			mcp.Description("The column number where the symbol is located (1-indexed)"),
-		),
-		mcp.WithString("newName",
-			mcp.Required(),
-			mcp.Description("The new name for the symbol"),
+			mcp.Description("The column number of the expression (1-indexed)"),
 		),
 	)
 
-	s.mcpServer.AddTool(renameSymbolTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	s.addTool(typeDefinitionTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments
 		filePath, ok := request.Params.Arguments["filePath"].(string)
 		if !ok {
 			return mcp.NewToolResultError("filePath must be a string"), nil
 		}
 
-		newName, ok := request.Params.Arguments["newName"].(string)
-		if !ok {
-			return mcp.NewToolResultError("newName must be a string"), nil
-		}
-
 		// Handle both float64 and int for line and column due to JSON parsing
 		var line, column int
 		switch v := request.Params.Arguments["line"].(type) {
@@ -354,15 +500,1907 @@ func (s *mcpServer) registerTools() error {
 			return mcp.NewToolResultError("column must be a number"), nil
 		}
 
-		coreLogger.Debug("Executing rename_symbol for file: %s line: %d column: %d newName: %s", filePath, line, column, newName)
-		text, err := tools.RenameSymbol(s.ctx, s.lspClient, filePath, line, column, newName)
+		coreLogger.Debug("Executing type_definition for file: %s line: %d column: %d", filePath, line, column)
+		client, err := s.clientForFile(filePath)
 		if err != nil {
-			coreLogger.Error("Failed to rename symbol: %v", err)
-			return mcp.NewToolResultError(fmt.Sprintf("failed to rename symbol: %v", err)), nil
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.ReadTypeDefinition(s.ctx, client, filePath, line, column)
+		if err != nil {
+			coreLogger.Error("Failed to get type definition: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get type definition: %v", err)), nil
 		}
 		return mcp.NewToolResultText(text), nil
 	})
 
+	findReferencesTool := mcp.NewTool("references",
+		mcp.WithDescription("Find all usages and references of a symbol throughout the codebase. Returns a list of all files and locations where the symbol appears."),
+		mcp.WithString("symbolName",
+			mcp.Required(),
+			mcp.Description("The name of the symbol to search for (e.g. 'mypackage.MyFunction', 'MyType')"),
+		),
+		mcp.WithNumber("contextLines",
+			mcp.Description("Lines of context to include around each reference. Defaults to LSP_CONTEXT_LINES or 5."),
+		),
+		mcp.WithString("kind",
+			mcp.Description("Only return references that read or write the symbol. Filtering needs an extra request per file, so it is slower than 'all'. Defaults to 'all'."),
+			mcp.Enum(tools.ReferenceKindAll, tools.ReferenceKindRead, tools.ReferenceKindWrite),
+		),
+		mcp.WithArray("excludeGlobs",
+			mcp.Description("Leave out references in files matching any of these globs, e.g. '*_test.go' or 'third_party'. A glob matches any run of path components. Defaults to LSP_EXCLUDE_GLOBS."),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithNumber("maxFiles",
+			mcp.Description("Maximum number of files per page of results. Defaults to 50."),
+		),
+		mcp.WithNumber("maxRefsPerFile",
+			mcp.Description("Maximum number of references shown in each file. Defaults to no limit."),
+		),
+		mcp.WithNumber("page",
+			mcp.Description("The page of files to return, starting at 1. Defaults to 1."),
+		),
+		mcp.WithBoolean("includeDeclaration",
+			mcp.Description("Also list the symbol's declaration, marked '(declaration)'. Defaults to false."),
+		),
+		mcp.WithBoolean("showLineNumbers",
+			mcp.Description("Prefix context lines with their line numbers. Defaults to true."),
+			mcp.DefaultBool(true),
+		),
+		mcp.WithBoolean("highlightMatch",
+			mcp.Description("Mark the lines holding a reference with '> '. Defaults to false."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithBoolean("underlineMatch",
+			mcp.Description("Put a line of '^' under each reference, showing which token on the line it is. Defaults to false."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithBoolean("summaryOnly",
+			mcp.Description("Only count the references in each file and list their lines, without reading the files. Much faster for deciding whether a symbol is widely used. Defaults to false."),
+		),
+		mcp.WithString("container",
+			mcp.Description("Only return references made from inside this symbol, such as a class or namespace and its methods (e.g. 'Cache' or 'ns::Cache'). Needs an extra request per file with references, so it is slow on widely used symbols."),
+		),
+	)
+
+	s.addTool(findReferencesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		symbolName, ok := request.Params.Arguments["symbolName"].(string)
+		if !ok {
+			return mcp.NewToolResultError("symbolName must be a string"), nil
+		}
+
+		// Handle both float64 and int for contextLines due to JSON parsing
+		contextLines := tools.DefaultContextLines
+		switch v := request.Params.Arguments["contextLines"].(type) {
+		case float64:
+			contextLines = int(v)
+		case int:
+			contextLines = v
+		}
+
+		// Handle both float64 and int for the pagination options due to JSON parsing
+		var maxFiles, maxRefsPerFile, page int
+		for name, target := range map[string]*int{"maxFiles": &maxFiles, "maxRefsPerFile": &maxRefsPerFile, "page": &page} {
+			switch v := request.Params.Arguments[name].(type) {
+			case float64:
+				*target = int(v)
+			case int:
+				*target = v
+			}
+		}
+
+		kind, _ := request.Params.Arguments["kind"].(string)
+		includeDeclaration, _ := request.Params.Arguments["includeDeclaration"].(bool)
+		highlightMatch, _ := request.Params.Arguments["highlightMatch"].(bool)
+		underlineMatch, _ := request.Params.Arguments["underlineMatch"].(bool)
+		summaryOnly, _ := request.Params.Arguments["summaryOnly"].(bool)
+		container, _ := request.Params.Arguments["container"].(string)
+
+		showLineNumbers := true // default value
+		if showLineNumbersArg, ok := request.Params.Arguments["showLineNumbers"].(bool); ok {
+			showLineNumbers = showLineNumbersArg
+		}
+
+		var excludeGlobs []string
+		if globsArg, ok := request.Params.Arguments["excludeGlobs"].([]any); ok {
+			excludeGlobs = []string{}
+			for _, glob := range globsArg {
+				globStr, ok := glob.(string)
+				if !ok {
+					return mcp.NewToolResultError("excludeGlobs must be an array of strings"), nil
+				}
+				excludeGlobs = append(excludeGlobs, globStr)
+			}
+		}
+
+		coreLogger.Debug("Executing references for symbol: %s", symbolName)
+		client, err := s.clientForSymbol(symbolName)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.FindReferences(s.ctx, client, symbolName, tools.ReferenceOptions{
+			ContextLines:       contextLines,
+			Kind:               kind,
+			ExcludeGlobs:       excludeGlobs,
+			MaxFiles:           maxFiles,
+			MaxRefsPerFile:     maxRefsPerFile,
+			Page:               page,
+			IncludeDeclaration: includeDeclaration,
+			HideLineNumbers:    !showLineNumbers,
+			HighlightMatch:     highlightMatch,
+			UnderlineMatch:     underlineMatch,
+			SummaryOnly:        summaryOnly,
+			Container:          container,
+		})
+		if err != nil {
+			coreLogger.Error("Failed to find references: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to find references: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	referencesAtPositionTool := mcp.NewTool("references_at_position",
+		mcp.WithDescription("Find all references to the symbol at a position. Resolves exactly the symbol under the cursor, so prefer it over references when a name is ambiguous."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file containing the symbol"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("The line number of the symbol (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Required(),
+			mcp.Description("The column number of the symbol (1-indexed)"),
+		),
+		mcp.WithNumber("contextLines",
+			mcp.Description("Lines of context to include around each reference. Defaults to LSP_CONTEXT_LINES or 5."),
+		),
+	)
+
+	s.addTool(referencesAtPositionTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		// Handle both float64 and int for line and column due to JSON parsing
+		var line, column int
+		switch v := request.Params.Arguments["line"].(type) {
+		case float64:
+			line = int(v)
+		case int:
+			line = v
+		default:
+			return mcp.NewToolResultError("line must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["column"].(type) {
+		case float64:
+			column = int(v)
+		case int:
+			column = v
+		default:
+			return mcp.NewToolResultError("column must be a number"), nil
+		}
+
+		// Handle both float64 and int for contextLines due to JSON parsing
+		contextLines := tools.DefaultContextLines
+		switch v := request.Params.Arguments["contextLines"].(type) {
+		case float64:
+			contextLines = int(v)
+		case int:
+			contextLines = v
+		}
+
+		coreLogger.Debug("Executing references_at_position for file: %s line: %d column: %d", filePath, line, column)
+		client, err := s.clientForFile(filePath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.FindReferencesAtPosition(s.ctx, client, filePath, line, column, contextLines)
+		if err != nil {
+			coreLogger.Error("Failed to find references: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to find references: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	findImplementationsTool := mcp.NewTool("implementations",
+		mcp.WithDescription("Find the concrete implementations of an interface, abstract class or virtual method. Returns a list of all files and locations of the implementations."),
+		mcp.WithString("symbolName",
+			mcp.Required(),
+			mcp.Description("The name of the interface or method to find implementations of (e.g. 'mypackage.MyInterface', 'Base::method')"),
+		),
+	)
+
+	s.addTool(findImplementationsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		symbolName, ok := request.Params.Arguments["symbolName"].(string)
+		if !ok {
+			return mcp.NewToolResultError("symbolName must be a string"), nil
+		}
+
+		coreLogger.Debug("Executing implementations for symbol: %s", symbolName)
+		client, err := s.clientForSymbol(symbolName)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.FindImplementations(s.ctx, client, symbolName)
+		if err != nil {
+			coreLogger.Error("Failed to find implementations: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to find implementations: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	callHierarchyTool := mcp.NewTool("call_hierarchy",
+		mcp.WithDescription("Show the callers (incoming) or callees (outgoing) of a function or method as a tree, with the file and line of each call."),
+		mcp.WithString("symbolName",
+			mcp.Required(),
+			mcp.Description("The name of the function or method (e.g. 'mypackage.MyFunction', 'MyType.MyMethod')"),
+		),
+		mcp.WithString("direction",
+			mcp.Required(),
+			mcp.Description("Either 'incoming' to list callers or 'outgoing' to list callees"),
+		),
+		mcp.WithNumber("depth",
+			mcp.Description("How many levels of the hierarchy to expand. Defaults to 1, capped at 5."),
+		),
+	)
+
+	s.addTool(callHierarchyTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		symbolName, ok := request.Params.Arguments["symbolName"].(string)
+		if !ok {
+			return mcp.NewToolResultError("symbolName must be a string"), nil
+		}
+
+		direction, ok := request.Params.Arguments["direction"].(string)
+		if !ok {
+			return mcp.NewToolResultError("direction must be a string"), nil
+		}
+
+		// Handle both float64 and int for depth due to JSON parsing
+		depth := 1
+		switch v := request.Params.Arguments["depth"].(type) {
+		case float64:
+			depth = int(v)
+		case int:
+			depth = v
+		}
+
+		coreLogger.Debug("Executing call_hierarchy for symbol: %s (%s)", symbolName, direction)
+		client, err := s.clientForSymbol(symbolName)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.CallHierarchy(s.ctx, client, symbolName, direction, depth)
+		if err != nil {
+			coreLogger.Error("Failed to get call hierarchy: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get call hierarchy: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	typeHierarchyTool := mcp.NewTool("type_hierarchy",
+		mcp.WithDescription("Show the base types (supertypes) or derived types (subtypes) of a class, struct or interface as a tree, with the file and line of each type."),
+		mcp.WithString("symbolName",
+			mcp.Required(),
+			mcp.Description("The name of the type (e.g. 'mypackage.MyType', 'ns::MyClass')"),
+		),
+		mcp.WithString("direction",
+			mcp.Required(),
+			mcp.Description("Either 'supertypes' to list base types or 'subtypes' to list derived types"),
+		),
+		mcp.WithNumber("depth",
+			mcp.Description("How many levels of the hierarchy to expand. Defaults to 1, capped at 5."),
+		),
+	)
+
+	s.addTool(typeHierarchyTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		symbolName, ok := request.Params.Arguments["symbolName"].(string)
+		if !ok {
+			return mcp.NewToolResultError("symbolName must be a string"), nil
+		}
+
+		direction, ok := request.Params.Arguments["direction"].(string)
+		if !ok {
+			return mcp.NewToolResultError("direction must be a string"), nil
+		}
+
+		// Handle both float64 and int for depth due to JSON parsing
+		depth := 1
+		switch v := request.Params.Arguments["depth"].(type) {
+		case float64:
+			depth = int(v)
+		case int:
+			depth = v
+		}
+
+		coreLogger.Debug("Executing type_hierarchy for symbol: %s (%s)", symbolName, direction)
+		client, err := s.clientForSymbol(symbolName)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.TypeHierarchy(s.ctx, client, symbolName, direction, depth)
+		if err != nil {
+			coreLogger.Error("Failed to get type hierarchy: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get type hierarchy: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	getDiagnosticsTool := mcp.NewTool("diagnostics",
+		mcp.WithDescription("Get diagnostic information for a specific file from the language server."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file to get diagnostics for"),
+		),
+		mcp.WithBoolean("contextLines",
+			mcp.Description("Lines to include around each diagnostic."),
+			mcp.DefaultBool(false),
+		),
+		mcp.WithBoolean("showLineNumbers",
+			mcp.Description("If true, adds line numbers to the output"),
+			mcp.DefaultBool(true),
+		),
+	)
+
+	s.addTool(getDiagnosticsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		contextLines := 5 // default value
+		if contextLinesArg, ok := request.Params.Arguments["contextLines"].(int); ok {
+			contextLines = contextLinesArg
+		}
+
+		showLineNumbers := true // default value
+		if showLineNumbersArg, ok := request.Params.Arguments["showLineNumbers"].(bool); ok {
+			showLineNumbers = showLineNumbersArg
+		}
+
+		coreLogger.Debug("Executing diagnostics for file: %s", filePath)
+		client, err := s.clientForFile(filePath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.GetDiagnosticsForFile(s.ctx, client, filePath, contextLines, showLineNumbers)
+		if err != nil {
+			coreLogger.Error("Failed to get diagnostics: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get diagnostics: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	workspaceDiagnosticsTool := mcp.NewTool("workspace_diagnostics",
+		mcp.WithDescription("Get a health snapshot of the whole workspace: the files with diagnostics and their counts by severity, most errors first, followed by the most severe diagnostics. Uses workspace/diagnostic when the server supports it, otherwise opens up to 100 source files and collects what the server publishes, which can take a few seconds."),
+		mcp.WithNumber("maxEntries",
+			mcp.Description("Maximum number of diagnostics to list after the per-file counts. Defaults to 20."),
+		),
+		mcp.WithString("filePath",
+			mcp.Description("A file whose language server to ask. Defaults to the primary language server."),
+		),
+	)
+
+	s.addTool(workspaceDiagnosticsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Handle both float64 and int for maxEntries due to JSON parsing
+		var maxEntries int
+		switch v := request.Params.Arguments["maxEntries"].(type) {
+		case float64:
+			maxEntries = int(v)
+		case int:
+			maxEntries = v
+		}
+
+		client := s.lspClient
+		if filePath, ok := request.Params.Arguments["filePath"].(string); ok && filePath != "" {
+			var err error
+			client, err = s.clientForFile(filePath)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+			}
+		}
+
+		coreLogger.Debug("Executing workspace_diagnostics")
+		text, err := tools.GetWorkspaceDiagnostics(s.ctx, client, maxEntries)
+		if err != nil {
+			coreLogger.Error("Failed to get workspace diagnostics: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get workspace diagnostics: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	documentSymbolsTool := mcp.NewTool("document_symbols",
+		mcp.WithDescription("Get an outline of all symbols (classes, functions, methods, etc.) in a file, indented to show nesting."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file to get the outline for"),
+		),
+		mcp.WithArray("kinds",
+			mcp.Description("Only list symbols of these kinds, such as 'Class', 'Method' or 'Function'. Enclosing symbols are kept to show nesting."),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+	)
+
+	s.addTool(documentSymbolsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		var kinds []string
+		if kindsArg, ok := request.Params.Arguments["kinds"].([]any); ok {
+			for _, kind := range kindsArg {
+				kindStr, ok := kind.(string)
+				if !ok {
+					return mcp.NewToolResultError("kinds must be an array of strings"), nil
+				}
+				kinds = append(kinds, kindStr)
+			}
+		}
+
+		coreLogger.Debug("Executing document_symbols for file: %s", filePath)
+		client, err := s.clientForFile(filePath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.DocumentSymbols(s.ctx, client, filePath, kinds)
+		if err != nil {
+			coreLogger.Error("Failed to get document symbols: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get document symbols: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	enclosingSymbolTool := mcp.NewTool("enclosing_symbol",
+		mcp.WithDescription("Find the function, class or namespace enclosing a position in a file, with the chain of symbols containing it. Useful to map a stack trace line to the function it is in."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("The line number (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Description("The column number (1-indexed). Omit to match any symbol spanning the line."),
+		),
+	)
+
+	s.addTool(enclosingSymbolTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		// Handle both float64 and int for line and column due to JSON parsing
+		var line, column int
+		switch v := request.Params.Arguments["line"].(type) {
+		case float64:
+			line = int(v)
+		case int:
+			line = v
+		default:
+			return mcp.NewToolResultError("line must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["column"].(type) {
+		case float64:
+			column = int(v)
+		case int:
+			column = v
+		}
+
+		coreLogger.Debug("Executing enclosing_symbol for file: %s line: %d column: %d", filePath, line, column)
+		client, err := s.clientForFile(filePath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.EnclosingSymbol(s.ctx, client, filePath, line, column)
+		if err != nil {
+			coreLogger.Error("Failed to get enclosing symbol: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get enclosing symbol: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	// Uncomment to add codelens tools
+	//
+	// getCodeLensTool := mcp.NewTool("get_codelens",
+	// 	mcp.WithDescription("Get code lens hints for a given file from the language server."),
+	// 	mcp.WithString("filePath",
+	// 		mcp.Required(),
+	// 		mcp.Description("The path to the file to get code lens information for"),
+	// 	),
+	// )
+	//
+	// s.addTool(getCodeLensTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// 	// Extract arguments
+	// 	filePath, ok := request.Params.Arguments["filePath"].(string)
+	// 	if !ok {
+	// 		return mcp.NewToolResultError("filePath must be a string"), nil
+	// 	}
+	//
+	// 	coreLogger.Debug("Executing get_codelens for file: %s", filePath)
+	// 	text, err := tools.GetCodeLens(s.ctx, s.lspClient, filePath)
+	// 	if err != nil {
+	// 		coreLogger.Error("Failed to get code lens: %v", err)
+	// 		return mcp.NewToolResultError(fmt.Sprintf("failed to get code lens: %v", err)), nil
+	// 	}
+	// 	return mcp.NewToolResultText(text), nil
+	// })
+	//
+	// executeCodeLensTool := mcp.NewTool("execute_codelens",
+	// 	mcp.WithDescription("Execute a code lens command for a given file and lens index."),
+	// 	mcp.WithString("filePath",
+	// 		mcp.Required(),
+	// 		mcp.Description("The path to the file containing the code lens to execute"),
+	// 	),
+	// 	mcp.WithNumber("index",
+	// 		mcp.Required(),
+	// 		mcp.Description("The index of the code lens to execute (from get_codelens output), 1 indexed"),
+	// 	),
+	// )
+	//
+	// s.addTool(executeCodeLensTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// 	// Extract arguments
+	// 	filePath, ok := request.Params.Arguments["filePath"].(string)
+	// 	if !ok {
+	// 		return mcp.NewToolResultError("filePath must be a string"), nil
+	// 	}
+	//
+	// 	// Handle both float64 and int for index due to JSON parsing
+	// 	var index int
+	// 	switch v := request.Params.Arguments["index"].(type) {
+	// 	case float64:
+	// 		index = int(v)
+	// 	case int:
+	// 		index = v
+	// 	default:
+	// 		return mcp.NewToolResultError("index must be a number"), nil
+	// 	}
+	//
+	// 	coreLogger.Debug("Executing execute_codelens for file: %s index: %d", filePath, index)
+	// 	text, err := tools.ExecuteCodeLens(s.ctx, s.lspClient, filePath, index)
+	// 	if err != nil {
+	// 		coreLogger.Error("Failed to execute code lens: %v", err)
+	// 		return mcp.NewToolResultError(fmt.Sprintf("failed to execute code lens: %v", err)), nil
+	// 	}
+	// 	return mcp.NewToolResultText(text), nil
+	// })
+
+	hoverTool := mcp.NewTool("hover",
+		mcp.WithDescription("Get hover information (type, documentation) for a symbol at the specified position."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file to get hover information for"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("The line number where the hover is requested (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Required(),
+			mcp.Description("The column number where the hover is requested (1-indexed)"),
+		),
+	)
+
+	s.addTool(hoverTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		// Handle both float64 and int for line and column due to JSON parsing
+		var line, column int
+		switch v := request.Params.Arguments["line"].(type) {
+		case float64:
+			line = int(v)
+		case int:
+			line = v
+		default:
+			return mcp.NewToolResultError("line must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["column"].(type) {
+		case float64:
+			column = int(v)
+		case int:
+			column = v
+		default:
+			return mcp.NewToolResultError("column must be a number"), nil
+		}
+
+		coreLogger.Debug("Executing hover for file: %s line: %d column: %d", filePath, line, column)
+		client, err := s.clientForFile(filePath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.GetHoverInfo(s.ctx, client, filePath, line, column)
+		if err != nil {
+			coreLogger.Error("Failed to get hover information: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get hover information: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	hoverSymbolTool := mcp.NewTool("hover_symbol",
+		mcp.WithDescription("Get hover information (type, documentation) for a symbol by name. Returns one hover block per matching symbol."),
+		mcp.WithString("symbolName",
+			mcp.Required(),
+			mcp.Description("The name of the symbol to get hover information for (e.g. 'mypackage.MyFunction', 'MyType')"),
+		),
+	)
+
+	s.addTool(hoverSymbolTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		symbolName, ok := request.Params.Arguments["symbolName"].(string)
+		if !ok {
+			return mcp.NewToolResultError("symbolName must be a string"), nil
+		}
+
+		coreLogger.Debug("Executing hover_symbol for symbol: %s", symbolName)
+		client, err := s.clientForSymbol(symbolName)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.Hover(s.ctx, client, symbolName)
+		if err != nil {
+			coreLogger.Error("Failed to get hover information: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get hover information: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	macroExpansionTool := mcp.NewTool("macro_expansion",
+		mcp.WithDescription("Show what the code at a position expands to. Returns the hover, which for C and C++ macros under clangd includes the macro's expansion, and the clang AST around the position when the language server provides clangd's textDocument/ast extension."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file containing the macro"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("The line number of the macro use (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Required(),
+			mcp.Description("The column number of the macro use (1-indexed)"),
+		),
+	)
+
+	s.addTool(macroExpansionTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		// Handle both float64 and int for line and column due to JSON parsing
+		var line, column int
+		switch v := request.Params.Arguments["line"].(type) {
+		case float64:
+			line = int(v)
+		case int:
+			line = v
+		default:
+			return mcp.NewToolResultError("line must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["column"].(type) {
+		case float64:
+			column = int(v)
+		case int:
+			column = v
+		default:
+			return mcp.NewToolResultError("column must be a number"), nil
+		}
+
+		coreLogger.Debug("Executing macro_expansion for file: %s line: %d column: %d", filePath, line, column)
+		client, err := s.clientForFile(filePath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.MacroExpansion(s.ctx, client, filePath, line, column)
+		if err != nil {
+			coreLogger.Error("Failed to get macro expansion: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get macro expansion: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	renameSymbolTool := mcp.NewTool("rename_symbol",
+		mcp.WithDescription("Rename a symbol (variable, function, class, etc.) at the specified position and update all references throughout the codebase."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file containing the symbol to rename"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("The line number where the symbol is located (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Required(),
+			mcp.Description("The column number where the symbol is located (1-indexed)"),
+		),
+		mcp.WithString("newName",
+			mcp.Required(),
+			mcp.Description("The new name for the symbol"),
+		),
+		mcp.WithBoolean("dryRun",
+			mcp.Description("Show a diff of the changes without writing any files. Defaults to false."),
+		),
+	)
+
+	s.addTool(renameSymbolTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		newName, ok := request.Params.Arguments["newName"].(string)
+		if !ok {
+			return mcp.NewToolResultError("newName must be a string"), nil
+		}
+
+		dryRun, _ := request.Params.Arguments["dryRun"].(bool)
+
+		// Handle both float64 and int for line and column due to JSON parsing
+		var line, column int
+		switch v := request.Params.Arguments["line"].(type) {
+		case float64:
+			line = int(v)
+		case int:
+			line = v
+		default:
+			return mcp.NewToolResultError("line must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["column"].(type) {
+		case float64:
+			column = int(v)
+		case int:
+			column = v
+		default:
+			return mcp.NewToolResultError("column must be a number"), nil
+		}
+
+		coreLogger.Debug("Executing rename_symbol for file: %s line: %d column: %d newName: %s", filePath, line, column, newName)
+		client, err := s.clientForFile(filePath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.RenameSymbol(s.ctx, client, filePath, line, column, newName, dryRun)
+		if err != nil {
+			coreLogger.Error("Failed to rename symbol: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to rename symbol: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	renameSymbolByNameTool := mcp.NewTool("rename_symbol_by_name",
+		mcp.WithDescription("Rename a symbol by name and update all references throughout the codebase. The name must match exactly one symbol."),
+		mcp.WithString("symbolName",
+			mcp.Required(),
+			mcp.Description("The name of the symbol to rename (e.g. 'mypackage.MyFunction', 'MyType.MyMethod')"),
+		),
+		mcp.WithString("newName",
+			mcp.Required(),
+			mcp.Description("The new name for the symbol"),
+		),
+		mcp.WithBoolean("dryRun",
+			mcp.Description("Show a diff of the changes without writing any files. Defaults to false."),
+		),
+	)
+
+	s.addTool(renameSymbolByNameTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		symbolName, ok := request.Params.Arguments["symbolName"].(string)
+		if !ok {
+			return mcp.NewToolResultError("symbolName must be a string"), nil
+		}
+
+		newName, ok := request.Params.Arguments["newName"].(string)
+		if !ok {
+			return mcp.NewToolResultError("newName must be a string"), nil
+		}
+
+		dryRun, _ := request.Params.Arguments["dryRun"].(bool)
+
+		coreLogger.Debug("Executing rename_symbol_by_name for symbol: %s newName: %s", symbolName, newName)
+		client, err := s.clientForSymbol(symbolName)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.RenameSymbolByName(s.ctx, client, symbolName, newName, dryRun)
+		if err != nil {
+			coreLogger.Error("Failed to rename symbol: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to rename symbol: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	replaceDefinitionTool := mcp.NewTool("replace_definition",
+		mcp.WithDescription("Replace the whole definition of a symbol, as returned by the definition tool, with new source code. Returns the new definition with line numbers."),
+		mcp.WithString("symbolName",
+			mcp.Required(),
+			mcp.Description("The name of the symbol whose definition to replace (e.g. 'mypackage.MyFunction', 'MyType.MyMethod')"),
+		),
+		mcp.WithString("newText",
+			mcp.Required(),
+			mcp.Description("The complete new definition, including its signature"),
+		),
+		mcp.WithNumber("matchIndex",
+			mcp.Description("When several symbols have this name, the 1-indexed position of the one to replace in the list of candidates. Without it, ambiguous names are refused."),
+		),
+		mcp.WithBoolean("dryRun",
+			mcp.Description("Show a diff of the change without writing any files. Defaults to false."),
+		),
+	)
+
+	s.addTool(replaceDefinitionTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		symbolName, ok := request.Params.Arguments["symbolName"].(string)
+		if !ok {
+			return mcp.NewToolResultError("symbolName must be a string"), nil
+		}
+
+		newText, ok := request.Params.Arguments["newText"].(string)
+		if !ok {
+			return mcp.NewToolResultError("newText must be a string"), nil
+		}
+
+		// Handle both float64 and int for matchIndex due to JSON parsing
+		var matchIndex int
+		switch v := request.Params.Arguments["matchIndex"].(type) {
+		case float64:
+			matchIndex = int(v)
+		case int:
+			matchIndex = v
+		}
+
+		dryRun, _ := request.Params.Arguments["dryRun"].(bool)
+
+		coreLogger.Debug("Executing replace_definition for symbol: %s", symbolName)
+		client, err := s.clientForSymbol(symbolName)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.ReplaceDefinition(s.ctx, client, symbolName, newText, matchIndex, dryRun)
+		if err != nil {
+			coreLogger.Error("Failed to replace definition: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to replace definition: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	insertNearSymbolTool := mcp.NewTool("insert_near_symbol",
+		mcp.WithDescription("Insert text next to a symbol's definition: before it (e.g. a doc comment), after it, or at the start or end of its body. The text is inserted on its own lines as is, so include indentation. Returns the inserted lines with context."),
+		mcp.WithString("symbolName",
+			mcp.Required(),
+			mcp.Description("The name of the symbol to insert near (e.g. 'mypackage.MyFunction', 'MyType.MyMethod')"),
+		),
+		mcp.WithString("text",
+			mcp.Required(),
+			mcp.Description("The text to insert"),
+		),
+		mcp.WithString("position",
+			mcp.Required(),
+			mcp.Description("Where to insert: 'before' or 'after' the definition, or at the 'body-start' or 'body-end'"),
+			mcp.Enum(tools.InsertBefore, tools.InsertAfter, tools.InsertBodyStart, tools.InsertBodyEnd),
+		),
+		mcp.WithNumber("matchIndex",
+			mcp.Description("When several symbols have this name, the 1-indexed position of the one to insert near in the list of candidates. Without it, ambiguous names are refused."),
+		),
+		mcp.WithBoolean("dryRun",
+			mcp.Description("Show a diff of the change without writing any files. Defaults to false."),
+		),
+	)
+
+	s.addTool(insertNearSymbolTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		symbolName, ok := request.Params.Arguments["symbolName"].(string)
+		if !ok {
+			return mcp.NewToolResultError("symbolName must be a string"), nil
+		}
+
+		text, ok := request.Params.Arguments["text"].(string)
+		if !ok {
+			return mcp.NewToolResultError("text must be a string"), nil
+		}
+
+		position, ok := request.Params.Arguments["position"].(string)
+		if !ok {
+			return mcp.NewToolResultError("position must be a string"), nil
+		}
+
+		// Handle both float64 and int for matchIndex due to JSON parsing
+		var matchIndex int
+		switch v := request.Params.Arguments["matchIndex"].(type) {
+		case float64:
+			matchIndex = int(v)
+		case int:
+			matchIndex = v
+		}
+
+		dryRun, _ := request.Params.Arguments["dryRun"].(bool)
+
+		coreLogger.Debug("Executing insert_near_symbol for symbol: %s position: %s", symbolName, position)
+		client, err := s.clientForSymbol(symbolName)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		result, err := tools.InsertNearSymbol(s.ctx, client, symbolName, text, position, matchIndex, dryRun)
+		if err != nil {
+			coreLogger.Error("Failed to insert text: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to insert text: %v", err)), nil
+		}
+		return mcp.NewToolResultText(result), nil
+	})
+
+	codeActionsTool := mcp.NewTool("code_actions",
+		mcp.WithDescription("List the code actions (quick fixes, refactorings, source actions) available at a position. Use apply_code_action with the index of an action to apply it."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("The line number to get code actions for (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Required(),
+			mcp.Description("The column number to get code actions for (1-indexed)"),
+		),
+	)
+
+	s.addTool(codeActionsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		// Handle both float64 and int for line and column due to JSON parsing
+		var line, column int
+		switch v := request.Params.Arguments["line"].(type) {
+		case float64:
+			line = int(v)
+		case int:
+			line = v
+		default:
+			return mcp.NewToolResultError("line must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["column"].(type) {
+		case float64:
+			column = int(v)
+		case int:
+			column = v
+		default:
+			return mcp.NewToolResultError("column must be a number"), nil
+		}
+
+		coreLogger.Debug("Executing code_actions for file: %s line: %d column: %d", filePath, line, column)
+		client, err := s.clientForFile(filePath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.GetCodeActions(s.ctx, client, filePath, line, column)
+		if err != nil {
+			coreLogger.Error("Failed to get code actions: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get code actions: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	applyCodeActionTool := mcp.NewTool("apply_code_action",
+		mcp.WithDescription("Apply a code action at a position and write the resulting edits to disk. Run code_actions first to see the available actions and their indexes."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("The line number the code actions were listed for (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Required(),
+			mcp.Description("The column number the code actions were listed for (1-indexed)"),
+		),
+		mcp.WithNumber("index",
+			mcp.Required(),
+			mcp.Description("The index of the code action to apply (from code_actions output), 1 indexed"),
+		),
+	)
+
+	s.addTool(applyCodeActionTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		// Handle both float64 and int for line, column and index due to JSON parsing
+		var line, column, index int
+		switch v := request.Params.Arguments["line"].(type) {
+		case float64:
+			line = int(v)
+		case int:
+			line = v
+		default:
+			return mcp.NewToolResultError("line must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["column"].(type) {
+		case float64:
+			column = int(v)
+		case int:
+			column = v
+		default:
+			return mcp.NewToolResultError("column must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["index"].(type) {
+		case float64:
+			index = int(v)
+		case int:
+			index = v
+		default:
+			return mcp.NewToolResultError("index must be a number"), nil
+		}
+
+		coreLogger.Debug("Executing apply_code_action for file: %s line: %d column: %d index: %d", filePath, line, column, index)
+		client, err := s.clientForFile(filePath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.ApplyCodeAction(s.ctx, client, filePath, line, column, index)
+		if err != nil {
+			coreLogger.Error("Failed to apply code action: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to apply code action: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	formatDocumentTool := mcp.NewTool("format_document",
+		mcp.WithDescription("Format a file using the language server's formatter and write the changes to disk."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file to format"),
+		),
+	)
+
+	s.addTool(formatDocumentTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		coreLogger.Debug("Executing format_document for file: %s", filePath)
+		client, err := s.clientForFile(filePath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.FormatDocument(s.ctx, client, filePath)
+		if err != nil {
+			coreLogger.Error("Failed to format document: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to format document: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	signatureHelpTool := mcp.NewTool("signature_help",
+		mcp.WithDescription("Show the signature of the function being called at a position, with the current parameter highlighted. Useful when editing call sites."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("The line number inside the call (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Required(),
+			mcp.Description("The column number inside the call's parentheses (1-indexed)"),
+		),
+	)
+
+	s.addTool(signatureHelpTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		// Handle both float64 and int for line and column due to JSON parsing
+		var line, column int
+		switch v := request.Params.Arguments["line"].(type) {
+		case float64:
+			line = int(v)
+		case int:
+			line = v
+		default:
+			return mcp.NewToolResultError("line must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["column"].(type) {
+		case float64:
+			column = int(v)
+		case int:
+			column = v
+		default:
+			return mcp.NewToolResultError("column must be a number"), nil
+		}
+
+		coreLogger.Debug("Executing signature_help for file: %s line: %d column: %d", filePath, line, column)
+		client, err := s.clientForFile(filePath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.SignatureHelp(s.ctx, client, filePath, line, column)
+		if err != nil {
+			coreLogger.Error("Failed to get signature help: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get signature help: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	completionTool := mcp.NewTool("completion",
+		mcp.WithDescription("List the code completions the language server suggests at a position, such as the methods and fields available after a '.'."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("The line number to complete at (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Required(),
+			mcp.Description("The column number to complete at (1-indexed)"),
+		),
+		mcp.WithNumber("maxResults",
+			mcp.Description("Maximum number of completions to list. Defaults to 25."),
+		),
+		mcp.WithNumber("resolve",
+			mcp.Description("Resolve the first N completions to include their documentation and extra edits, such as added imports. Each costs a request to the language server. Defaults to 0."),
+		),
+	)
+
+	s.addTool(completionTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		// Handle both float64 and int for line, column, maxResults and resolve due to JSON parsing
+		var line, column int
+		switch v := request.Params.Arguments["line"].(type) {
+		case float64:
+			line = int(v)
+		case int:
+			line = v
+		default:
+			return mcp.NewToolResultError("line must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["column"].(type) {
+		case float64:
+			column = int(v)
+		case int:
+			column = v
+		default:
+			return mcp.NewToolResultError("column must be a number"), nil
+		}
+
+		maxResults := tools.DefaultMaxCompletionResults
+		switch v := request.Params.Arguments["maxResults"].(type) {
+		case float64:
+			maxResults = int(v)
+		case int:
+			maxResults = v
+		}
+
+		var resolve int
+		switch v := request.Params.Arguments["resolve"].(type) {
+		case float64:
+			resolve = int(v)
+		case int:
+			resolve = v
+		}
+
+		coreLogger.Debug("Executing completion for file: %s line: %d column: %d", filePath, line, column)
+		client, err := s.clientForFile(filePath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.Complete(s.ctx, client, filePath, line, column, maxResults, resolve)
+		if err != nil {
+			coreLogger.Error("Failed to get completions: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get completions: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	documentHighlightTool := mcp.NewTool("document_highlight",
+		mcp.WithDescription("Find all occurrences of the symbol at a position within the same file, marked as reads, writes or text matches. Cheaper than a workspace-wide references search for local variables."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("The line number of the symbol (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Required(),
+			mcp.Description("The column number of the symbol (1-indexed)"),
+		),
+	)
+
+	s.addTool(documentHighlightTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		// Handle both float64 and int for line and column due to JSON parsing
+		var line, column int
+		switch v := request.Params.Arguments["line"].(type) {
+		case float64:
+			line = int(v)
+		case int:
+			line = v
+		default:
+			return mcp.NewToolResultError("line must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["column"].(type) {
+		case float64:
+			column = int(v)
+		case int:
+			column = v
+		default:
+			return mcp.NewToolResultError("column must be a number"), nil
+		}
+
+		coreLogger.Debug("Executing document_highlight for file: %s line: %d column: %d", filePath, line, column)
+		client, err := s.clientForFile(filePath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.DocumentHighlight(s.ctx, client, filePath, line, column)
+		if err != nil {
+			coreLogger.Error("Failed to get document highlights: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get document highlights: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	selectionRangeTool := mcp.NewTool("selection_range",
+		mcp.WithDescription("List the nested ranges around a position that smart selection expands through, such as expression, statement, block and function, with their text. Use this to pick how much surrounding code to read or edit."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("The line number of the position (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Required(),
+			mcp.Description("The column number of the position (1-indexed)"),
+		),
+	)
+
+	s.addTool(selectionRangeTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		// Handle both float64 and int for line and column due to JSON parsing
+		var line, column int
+		switch v := request.Params.Arguments["line"].(type) {
+		case float64:
+			line = int(v)
+		case int:
+			line = v
+		default:
+			return mcp.NewToolResultError("line must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["column"].(type) {
+		case float64:
+			column = int(v)
+		case int:
+			column = v
+		default:
+			return mcp.NewToolResultError("column must be a number"), nil
+		}
+
+		coreLogger.Debug("Executing selection_range for file: %s line: %d column: %d", filePath, line, column)
+		client, err := s.clientForFile(filePath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.SelectionRange(s.ctx, client, filePath, line, column)
+		if err != nil {
+			coreLogger.Error("Failed to get selection ranges: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get selection ranges: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	inlayHintsTool := mcp.NewTool("inlay_hints",
+		mcp.WithDescription("Show a range of lines of a file with the language server's inlay hints, such as inferred types and parameter names, inserted into the source."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file"),
+		),
+		mcp.WithNumber("startLine",
+			mcp.Required(),
+			mcp.Description("First line to show (1-indexed, inclusive)"),
+		),
+		mcp.WithNumber("endLine",
+			mcp.Required(),
+			mcp.Description("Last line to show (1-indexed, inclusive)"),
+		),
+	)
+
+	s.addTool(inlayHintsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		// Handle both float64 and int for startLine and endLine due to JSON parsing
+		var startLine, endLine int
+		switch v := request.Params.Arguments["startLine"].(type) {
+		case float64:
+			startLine = int(v)
+		case int:
+			startLine = v
+		default:
+			return mcp.NewToolResultError("startLine must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["endLine"].(type) {
+		case float64:
+			endLine = int(v)
+		case int:
+			endLine = v
+		default:
+			return mcp.NewToolResultError("endLine must be a number"), nil
+		}
+
+		coreLogger.Debug("Executing inlay_hints for file: %s lines: %d-%d", filePath, startLine, endLine)
+		client, err := s.clientForFile(filePath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.GetInlayHints(s.ctx, client, filePath, startLine, endLine)
+		if err != nil {
+			coreLogger.Error("Failed to get inlay hints: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get inlay hints: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	documentLinksTool := mcp.NewTool("document_links",
+		mcp.WithDescription("List the links in a file with the file or URI each points to, such as the headers behind C++ #include lines or the targets of imports. Use this to follow a file's dependencies without guessing paths."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file"),
+		),
+	)
+
+	s.addTool(documentLinksTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		coreLogger.Debug("Executing document_links for file: %s", filePath)
+		client, err := s.clientForFile(filePath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.GetDocumentLinks(s.ctx, client, filePath)
+		if err != nil {
+			coreLogger.Error("Failed to get document links: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get document links: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	organizeImportsTool := mcp.NewTool("organize_imports",
+		mcp.WithDescription("Organize the imports of a file using the language server's organize imports action, which adds missing imports, removes unused ones and sorts them, and write the result to disk."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file"),
+		),
+		mcp.WithBoolean("dryRun",
+			mcp.Description("Show a diff of the changes without writing any files. Defaults to false."),
+		),
+	)
+
+	s.addTool(organizeImportsTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+		dryRun, _ := request.Params.Arguments["dryRun"].(bool)
+
+		coreLogger.Debug("Executing organize_imports for file: %s", filePath)
+		client, err := s.clientForFile(filePath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.OrganizeImports(s.ctx, client, filePath, dryRun)
+		if err != nil {
+			coreLogger.Error("Failed to organize imports: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to organize imports: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	renameFileTool := mcp.NewTool("rename_file",
+		mcp.WithDescription("Rename or move a file and let the language server update the imports and includes that refer to it, using workspace/willRenameFiles. Servers that don't support it only get the file moved."),
+		mcp.WithString("oldPath",
+			mcp.Required(),
+			mcp.Description("The path of the file to rename"),
+		),
+		mcp.WithString("newPath",
+			mcp.Required(),
+			mcp.Description("The new path of the file. Missing directories are created."),
+		),
+		mcp.WithBoolean("dryRun",
+			mcp.Description("Show a diff of the changes without renaming or writing any files. Defaults to false."),
+		),
+	)
+
+	s.addTool(renameFileTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		oldPath, ok := request.Params.Arguments["oldPath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("oldPath must be a string"), nil
+		}
+		newPath, ok := request.Params.Arguments["newPath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("newPath must be a string"), nil
+		}
+		dryRun, _ := request.Params.Arguments["dryRun"].(bool)
+
+		coreLogger.Debug("Executing rename_file from %s to %s", oldPath, newPath)
+		client, err := s.clientForFile(oldPath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.RenameFile(s.ctx, client, oldPath, newPath, dryRun)
+		if err != nil {
+			coreLogger.Error("Failed to rename file: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to rename file: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	switchSourceHeaderTool := mcp.NewTool("switch_source_header",
+		mcp.WithDescription("Find the header of a C or C++ source file, or the source file of a header, using clangd's textDocument/switchSourceHeader extension. Returns the counterpart's path, and its content with line numbers when includeContent is set."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the source or header file"),
+		),
+		mcp.WithBoolean("includeContent",
+			mcp.Description("Also return the content of the counterpart file. Defaults to false."),
+		),
+	)
+
+	s.addTool(switchSourceHeaderTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+		includeContent, _ := request.Params.Arguments["includeContent"].(bool)
+
+		coreLogger.Debug("Executing switch_source_header for file: %s", filePath)
+		client, err := s.clientForFile(filePath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.SwitchSourceHeader(s.ctx, client, filePath, includeContent)
+		if err != nil {
+			coreLogger.Error("Failed to switch source/header: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to switch source/header: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	monikerTool := mcp.NewTool("moniker",
+		mcp.WithDescription("Get the monikers of the symbol at a position: stable identifiers, as used by LSIF and SCIP indexes, that name the same symbol across repositories. Useful for building cross-repository navigation."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file"),
+		),
+		mcp.WithNumber("line",
+			mcp.Required(),
+			mcp.Description("The line number of the symbol (1-indexed)"),
+		),
+		mcp.WithNumber("column",
+			mcp.Required(),
+			mcp.Description("The column number of the symbol (1-indexed)"),
+		),
+	)
+
+	s.addTool(monikerTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		// Handle both float64 and int for line and column due to JSON parsing
+		var line, column int
+		switch v := request.Params.Arguments["line"].(type) {
+		case float64:
+			line = int(v)
+		case int:
+			line = v
+		default:
+			return mcp.NewToolResultError("line must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["column"].(type) {
+		case float64:
+			column = int(v)
+		case int:
+			column = v
+		default:
+			return mcp.NewToolResultError("column must be a number"), nil
+		}
+
+		coreLogger.Debug("Executing moniker for file: %s line: %d column: %d", filePath, line, column)
+		client, err := s.clientForFile(filePath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.GetMoniker(s.ctx, client, filePath, line, column)
+		if err != nil {
+			coreLogger.Error("Failed to get monikers: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get monikers: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	foldingRangesTool := mcp.NewTool("folding_ranges",
+		mcp.WithDescription("List the foldable regions (functions, blocks, comments, imports) of a file. With collapse set, shows the file with each top-level region folded into one line, a condensed overview of large files."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file"),
+		),
+		mcp.WithBoolean("collapse",
+			mcp.Description("Print the file with folded regions replaced by a summary line instead of listing the regions. Defaults to false."),
+		),
+	)
+
+	s.addTool(foldingRangesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		collapse, _ := request.Params.Arguments["collapse"].(bool)
+
+		coreLogger.Debug("Executing folding_ranges for file: %s", filePath)
+		client, err := s.clientForFile(filePath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.GetFoldingRanges(s.ctx, client, filePath, collapse)
+		if err != nil {
+			coreLogger.Error("Failed to get folding ranges: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get folding ranges: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	semanticTokensTool := mcp.NewTool("semantic_tokens",
+		mcp.WithDescription("Show a file with each identifier annotated with its semantic classification from the language server (type, function, method, variable, parameter, namespace, ...) and modifiers such as definition or readonly."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file"),
+		),
+	)
+
+	s.addTool(semanticTokensTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		coreLogger.Debug("Executing semantic_tokens for file: %s", filePath)
+		client, err := s.clientForFile(filePath)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+		}
+		text, err := tools.GetSemanticTokens(s.ctx, client, filePath)
+		if err != nil {
+			coreLogger.Error("Failed to get semantic tokens: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get semantic tokens: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	serverInfoTool := mcp.NewTool("server_info",
+		mcp.WithDescription("Show the language server's name and version, and which tools its capabilities support. Call this to check what is available before using a tool the server may not support."),
+		mcp.WithString("filePath",
+			mcp.Description("A file whose language server to describe. Defaults to the primary language server."),
+		),
+	)
+
+	s.addTool(serverInfoTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := s.lspClient
+		if filePath, ok := request.Params.Arguments["filePath"].(string); ok && filePath != "" {
+			var err error
+			client, err = s.clientForFile(filePath)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+			}
+		}
+
+		coreLogger.Debug("Executing server_info")
+		text, err := tools.ServerInfo(s.ctx, client, s.toolNames)
+		if err != nil {
+			coreLogger.Error("Failed to get server info: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get server info: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	debugInfoTool := mcp.NewTool("debug_info",
+		mcp.WithDescription("Show how the language server was launched: its command line, environment, working directory, workspace folders and initialization options, with a summary of its initialize response. Use this to diagnose a server that finds nothing, such as clangd without a compile_commands.json."),
+		mcp.WithString("filePath",
+			mcp.Description("A file whose language server to describe. Defaults to the primary language server."),
+		),
+	)
+
+	s.addTool(debugInfoTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := s.lspClient
+		if filePath, ok := request.Params.Arguments["filePath"].(string); ok && filePath != "" {
+			var err error
+			client, err = s.clientForFile(filePath)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+			}
+		}
+
+		coreLogger.Debug("Executing debug_info")
+		text, err := tools.DebugInfo(s.ctx, client)
+		if err != nil {
+			coreLogger.Error("Failed to get debug info: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get debug info: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	readFileRangeTool := mcp.NewTool("read_file_range",
+		mcp.WithDescription("Read a range of lines of a file with line numbers, such as the lines around a diagnostic or reference. Does not use the language server."),
+		mcp.WithString("filePath",
+			mcp.Required(),
+			mcp.Description("The path to the file"),
+		),
+		mcp.WithNumber("startLine",
+			mcp.Required(),
+			mcp.Description("First line to read (1-indexed, inclusive)"),
+		),
+		mcp.WithNumber("endLine",
+			mcp.Description("Last line to read (1-indexed, inclusive). Defaults to the end of the file."),
+		),
+	)
+
+	s.addTool(readFileRangeTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		filePath, ok := request.Params.Arguments["filePath"].(string)
+		if !ok {
+			return mcp.NewToolResultError("filePath must be a string"), nil
+		}
+
+		// Handle both float64 and int for startLine and endLine due to JSON parsing
+		var startLine, endLine int
+		switch v := request.Params.Arguments["startLine"].(type) {
+		case float64:
+			startLine = int(v)
+		case int:
+			startLine = v
+		default:
+			return mcp.NewToolResultError("startLine must be a number"), nil
+		}
+
+		switch v := request.Params.Arguments["endLine"].(type) {
+		case float64:
+			endLine = int(v)
+		case int:
+			endLine = v
+		}
+
+		coreLogger.Debug("Executing read_file_range for file: %s lines: %d-%d", filePath, startLine, endLine)
+		text, err := tools.ReadFileRange(s.ctx, filePath, startLine, endLine)
+		if err != nil {
+			coreLogger.Error("Failed to read file range: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to read file range: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	indexStatusTool := mcp.NewTool("index_status",
+		mcp.WithDescription("Check whether the language server's symbol index is populated. Use this when symbol lookups return nothing, to tell a symbol that doesn't exist from an index that is still being built."),
+		mcp.WithString("filePath",
+			mcp.Description("A file whose language server to check. Defaults to the primary language server."),
+		),
+		mcp.WithNumber("waitSeconds",
+			mcp.Description("Wait up to this many seconds for the work the server reports in progress, such as indexing, to finish before checking. Defaults to 0."),
+		),
+	)
+
+	s.addTool(indexStatusTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := s.lspClient
+		if filePath, ok := request.Params.Arguments["filePath"].(string); ok && filePath != "" {
+			var err error
+			client, err = s.clientForFile(filePath)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+			}
+		}
+
+		// Handle both float64 and int for waitSeconds due to JSON parsing
+		var waitSeconds float64
+		switch v := request.Params.Arguments["waitSeconds"].(type) {
+		case float64:
+			waitSeconds = v
+		case int:
+			waitSeconds = float64(v)
+		}
+
+		coreLogger.Debug("Executing index_status")
+		if waitSeconds > 0 {
+			client.WaitForProgress(s.ctx, time.Duration(waitSeconds*float64(time.Second)))
+		}
+		text, err := tools.IndexStatus(s.ctx, client)
+		if err != nil {
+			coreLogger.Error("Failed to get index status: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get index status: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	workspaceFilesTool := mcp.NewTool("workspace_files",
+		mcp.WithDescription("List the source files in the workspace that the language server handles, leaving out files ignored by .gitignore. Use this to get a map of the codebase before navigating it."),
+		mcp.WithString("filePath",
+			mcp.Description("A file whose language server to list files for. Defaults to the primary language server."),
+		),
+	)
+
+	s.addTool(workspaceFilesTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client := s.lspClient
+		if filePath, ok := request.Params.Arguments["filePath"].(string); ok && filePath != "" {
+			var err error
+			client, err = s.clientForFile(filePath)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+			}
+		}
+
+		coreLogger.Debug("Executing workspace_files")
+		text, err := tools.ListWorkspaceFiles(s.ctx, client)
+		if err != nil {
+			coreLogger.Error("Failed to list workspace files: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to list workspace files: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	workspaceFoldersTool := mcp.NewTool("workspace_folders",
+		mcp.WithDescription("List the workspace folders the language servers index, and add or remove folders, such as a dependency checked out next to the workspace, so its symbols can be found."),
+		mcp.WithArray("add",
+			mcp.Description("Directories to add as workspace folders"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithArray("remove",
+			mcp.Description("Workspace folders to remove. The workspace itself cannot be removed."),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+	)
+
+	s.addTool(workspaceFoldersTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// Extract arguments
+		folders := make(map[string][]string)
+		for _, name := range []string{"add", "remove"} {
+			arg, ok := request.Params.Arguments[name].([]any)
+			if !ok {
+				continue
+			}
+			for _, folder := range arg {
+				folderStr, ok := folder.(string)
+				if !ok {
+					return mcp.NewToolResultError(name + " must be an array of strings"), nil
+				}
+				folders[name] = append(folders[name], folderStr)
+			}
+		}
+
+		coreLogger.Debug("Executing workspace_folders")
+		text, err := tools.ChangeWorkspaceFolders(s.ctx, s.router, folders["add"], folders["remove"])
+		if err != nil {
+			coreLogger.Error("Failed to change workspace folders: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("failed to change workspace folders: %v", err)), nil
+		}
+		return mcp.NewToolResultText(text), nil
+	})
+
+	// Raw requests can reach any server method, so they must be asked for
+	if rawRequestsEnabled() {
+		rawRequestTool := mcp.NewTool("raw_request",
+			mcp.WithDescription("Send an arbitrary LSP request to the language server and return its raw JSON response. For debugging why another tool returns nothing, or for trying server specific methods such as clangd's textDocument/switchSourceHeader."),
+			mcp.WithString("method",
+				mcp.Required(),
+				mcp.Description("The LSP method, e.g. 'textDocument/hover'"),
+			),
+			mcp.WithString("params",
+				mcp.Description("The request params as a JSON string, e.g. '{\"textDocument\": {\"uri\": \"file:///path/to/file.go\"}}'. A file named by textDocument.uri is opened first. Omit to send no params."),
+			),
+			mcp.WithString("filePath",
+				mcp.Description("A file whose language server to send the request to. Defaults to the primary language server."),
+			),
+		)
+
+		s.addTool(rawRequestTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			// Extract arguments
+			method, ok := request.Params.Arguments["method"].(string)
+			if !ok {
+				return mcp.NewToolResultError("method must be a string"), nil
+			}
+			params, _ := request.Params.Arguments["params"].(string)
+
+			client := s.lspClient
+			if filePath, ok := request.Params.Arguments["filePath"].(string); ok && filePath != "" {
+				var err error
+				client, err = s.clientForFile(filePath)
+				if err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("failed to start language server: %v", err)), nil
+				}
+			}
+
+			coreLogger.Debug("Executing raw_request for method: %s", method)
+			text, err := tools.RawRequest(s.ctx, client, method, params)
+			if err != nil {
+				coreLogger.Error("Failed to send raw request: %v", err)
+				return mcp.NewToolResultError(fmt.Sprintf("failed to send raw request: %v", err)), nil
+			}
+			return mcp.NewToolResultText(text), nil
+		})
+	}
+
 	coreLogger.Info("Successfully registered all MCP tools")
 	return nil
 }